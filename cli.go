@@ -0,0 +1,814 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"fishreports/config"
+	"fishreports/controller"
+	"fishreports/model"
+)
+
+const cliUsage = `Usage: fishreports <command> [flags]
+
+Commands:
+  serve                 run the HTTP server (the default without a command)
+  surveys               list survey rows with the /surveys filters
+  species stats <name>  statistics for a species (common name or code)
+  county stats <name>   statistics for a county
+  lake <dow>            a lake and all of its surveys
+  validate <dir>        check survey files without loading them into a server
+  build-snapshot        parse the data files into a snapshot the server loads quickly
+  diff <from> [to]      compare two survey directories or snapshots (to defaults
+                        to the configured data)
+
+Every command takes -config <file> (default config.json or $FISHREPORTS_CONFIG)
+and -v to show load logs. Run "fishreports <command> -h" for its flags.
+`
+
+// errUsage reports a command line mistake; the usage has already been shown.
+var errUsage = errors.New("usage")
+
+// runCLI runs one command and returns the process exit code: 0 on success,
+// 1 on failure and 2 on a usage error.
+func runCLI(args []string) int {
+	// Without a command the binary is the server, as before the CLI existed.
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		args = append([]string{"serve"}, args...)
+	}
+
+	var err error
+	switch command, rest := args[0], args[1:]; command {
+	case "serve":
+		err = cmdServe(rest)
+	case "surveys":
+		err = cmdSurveys(rest, os.Stdout)
+	case "species":
+		err = cmdSpecies(rest, os.Stdout)
+	case "county":
+		err = cmdCounty(rest, os.Stdout)
+	case "lake":
+		err = cmdLake(rest, os.Stdout)
+	case "validate":
+		err = cmdValidate(rest, os.Stdout)
+	case "build-snapshot":
+		err = cmdBuildSnapshot(rest, os.Stdout)
+	case "diff":
+		err = cmdDiff(rest, os.Stdout)
+	case "help", "-h", "--help":
+		fmt.Print(cliUsage)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "fishreports: unknown command %q\n\n%s", command, cliUsage)
+		return 2
+	}
+
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, errUsage), errors.Is(err, flag.ErrHelp):
+		return 2
+	default:
+		fmt.Fprintf(os.Stderr, "fishreports: %v\n", err)
+		return 1
+	}
+}
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// commandFlags holds the flags every command shares.
+type commandFlags struct {
+	*flag.FlagSet
+	configPath string
+	verbose    bool
+}
+
+func newCommandFlags(name, usage string) *commandFlags {
+	fs := &commandFlags{FlagSet: flag.NewFlagSet(name, flag.ContinueOnError)}
+	fs.StringVar(&fs.configPath, "config", config.Path(), "config file")
+	fs.BoolVar(&fs.verbose, "v", false, "show load logs")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: fishreports %s\n", usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse parses flags anywhere among the arguments and returns the
+// positional ones, which must number exactly want (or any when want < 0).
+func (fs *commandFlags) parse(args []string, want int) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if want >= 0 && len(positional) != want {
+		fs.Usage()
+		return nil, errUsage
+	}
+	if !fs.verbose {
+		log.SetOutput(io.Discard)
+	}
+	return positional, nil
+}
+
+// load reads the config and the data it names.
+func (fs *commandFlags) load() (*dataset, error) {
+	cfg, err := config.Load(fs.configPath)
+	if err != nil {
+		return nil, err
+	}
+	return loadDataset(cfg)
+}
+
+func cmdServe(args []string) error {
+	fs := newCommandFlags("serve", "serve [-config file]")
+	if _, err := fs.parse(args, 0); err != nil {
+		return err
+	}
+	log.SetOutput(os.Stderr) // the server always logs
+	cfg, err := config.Load(fs.configPath)
+	if err != nil {
+		return err
+	}
+	serve(cfg)
+	return nil
+}
+
+// surveyColumns are the /surveys row fields written by the table and CSV
+// formats, in order.
+var surveyColumns = []string{
+	"survey_date", "state", "dow_number", "lake_name", "county_name", "species_name",
+	"total_catch", "min_length", "max_length", "survey_type", "survey_sub_type", "surveyID",
+}
+
+func cmdSurveys(args []string, out io.Writer) error {
+	fs := newCommandFlags("surveys", "surveys [flags]")
+	var species, counties, lakes stringList
+	fs.Var(&species, "species", "species common name, code or ID (repeatable)")
+	fs.Var(&counties, "county", "county name or ID (repeatable)")
+	fs.Var(&lakes, "lake", "lake name (repeatable)")
+	minYear := fs.String("min-year", "", "earliest survey year")
+	maxYear := fs.String("max-year", "", "latest survey year")
+	state := fs.String("state", "", "state code")
+	search := fs.String("search", "", "search lake, county and species names")
+	gameFish := fs.Bool("game-fish", false, "only game fish")
+	invasive := fs.Bool("invasive", false, "only species listed as invasive")
+	sortBy := fs.String("sort", "", "sort field, e.g. survey_date or total_catch")
+	order := fs.String("order", "", "asc or desc")
+	limit := fs.Int("limit", 0, "rows per page; 0 returns every row")
+	page := fs.Int("page", 1, "page number")
+	format := fs.String("format", "table", "table, csv or json")
+	if _, err := fs.parse(args, 0); err != nil {
+		return err
+	}
+	ds, err := fs.load()
+	if err != nil {
+		return err
+	}
+
+	fish := ds.Fish
+	if *state != "" {
+		if _, exists := ds.Fish.FindState(*state); !exists {
+			return fmt.Errorf("unknown state %q", *state)
+		}
+		fish = fish.ForState(*state)
+	}
+	speciesIDs := make([]string, 0, len(species))
+	for _, name := range species {
+		sp, err := findSpecies(ds, name)
+		if err != nil {
+			return err
+		}
+		speciesIDs = append(speciesIDs, sp.ID)
+	}
+	countyIDs := make([]string, 0, len(counties))
+	for _, name := range counties {
+		county, err := findCounty(ds, name, *state)
+		if err != nil {
+			return err
+		}
+		countyIDs = append(countyIDs, county.ID)
+	}
+	pageSize := *limit
+	if pageSize <= 0 {
+		pageSize, *page = int(^uint(0)>>1), 1
+	}
+
+	result, err := fish.FilterAndSortDataContext(context.Background(),
+		speciesIDs, *minYear, *maxYear, countyIDs, lakes,
+		*sortBy, *order, *gameFish, *invasive, *search, pageSize, *page)
+	if err != nil {
+		return err
+	}
+	rows, _ := result["data"].([]map[string]interface{})
+
+	switch *format {
+	case "json":
+		return writeJSON(out, map[string]interface{}{"total": result["total"], "page": *page, "data": rows})
+	case "csv":
+		w := csv.NewWriter(out)
+		w.Write(surveyColumns)
+		for _, row := range rows {
+			w.Write(rowValues(row, surveyColumns))
+		}
+		w.Flush()
+		return w.Error()
+	case "table":
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		columns := surveyColumns[:len(surveyColumns)-2] // IDs and subtypes are too wide for a terminal
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(columns, "\t")))
+		for _, row := range rows {
+			fmt.Fprintln(tw, strings.Join(rowValues(row, columns), "\t"))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%d of %v rows\n", len(rows), result["total"])
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q (want table, csv or json)", *format)
+	}
+}
+
+func rowValues(row map[string]interface{}, columns []string) []string {
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		if value, exists := row[column]; exists && value != nil {
+			values[i] = fmt.Sprint(value)
+		}
+	}
+	return values
+}
+
+func cmdSpecies(args []string, out io.Writer) error {
+	fs := newCommandFlags("species", "species stats <common name or code> [flags]")
+	state := fs.String("state", "", "state code")
+	format := fs.String("format", "table", "table or json")
+	positional, err := fs.parse(args, 2)
+	if err != nil {
+		return err
+	}
+	if positional[0] != "stats" {
+		fs.Usage()
+		return errUsage
+	}
+	ds, err := fs.load()
+	if err != nil {
+		return err
+	}
+	species, err := findSpecies(ds, positional[1])
+	if err != nil {
+		return err
+	}
+	fish := ds.Fish
+	if *state != "" {
+		fish = fish.ForState(*state)
+	}
+	stats, err := fish.GetSpeciesStatsByIDContext(context.Background(), species.ID)
+	if err != nil {
+		return err
+	}
+	if stats == nil {
+		return fmt.Errorf("no survey data for %s", species.CommonName)
+	}
+	if *format == "json" {
+		return writeJSON(out, stats)
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Species\t%s (%s)\n", species.CommonName, species.Code)
+	fmt.Fprintf(tw, "Fish measured\t%v\n", stats["total_fish"])
+	fmt.Fprintf(tw, "Lakes with species\t%v%%\n", stats["percent_lakes"])
+	fmt.Fprintf(tw, "Average length\t%.1f in\n", stats["average_length"])
+	fmt.Fprintf(tw, "Length range\t%v-%v in\n", stats["shortest_length"], stats["biggest_length"])
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "COUNTY\tLAKES WITH SPECIES")
+	countyStats, _ := stats["counties"].([]map[string]interface{})
+	sort.SliceStable(countyStats, func(i, j int) bool {
+		pi, _ := countyStats[i]["percentage"].(int)
+		pj, _ := countyStats[j]["percentage"].(int)
+		return pi > pj
+	})
+	for _, entry := range countyStats {
+		id, _ := entry["id"].(string)
+		if id == "" {
+			continue
+		}
+		name := id
+		if county := ds.Counties.GetCountyByID(id); county != nil {
+			name = county.CountyName
+		}
+		fmt.Fprintf(tw, "%s\t%v%%\n", name, entry["percentage"])
+	}
+	return tw.Flush()
+}
+
+func cmdCounty(args []string, out io.Writer) error {
+	fs := newCommandFlags("county", "county stats <name> [flags]")
+	state := fs.String("state", "", "state code")
+	format := fs.String("format", "table", "table or json")
+	positional, err := fs.parse(args, 2)
+	if err != nil {
+		return err
+	}
+	if positional[0] != "stats" {
+		fs.Usage()
+		return errUsage
+	}
+	ds, err := fs.load()
+	if err != nil {
+		return err
+	}
+	county, err := findCounty(ds, positional[1], *state)
+	if err != nil {
+		return err
+	}
+	stats, err := ds.Counties.GetCountyStatsContext(context.Background(), county)
+	if err != nil {
+		return err
+	}
+	if *format == "json" {
+		return writeJSON(out, stats)
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "County\t%s (%s)\n", county.CountyName, model.CountyState(*county))
+	fmt.Fprintf(tw, "Lakes\t%v\n", stats["number_of_lakes"])
+	fmt.Fprintf(tw, "Surveys\t%v\n", stats["total_surveys"])
+	fmt.Fprintf(tw, "Fish caught\t%v\n", stats["total_fish_caught"])
+	fmt.Fprintf(tw, "Fish per survey\t%.1f\n", stats["average_fish_per_survey"])
+	fmt.Fprintf(tw, "Species\t%v\n", stats["number_of_species"])
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "SPECIES\tSHARE OF CATCH")
+	// The distribution is keyed by species ID.
+	speciesNames := make(map[string]string)
+	for _, species := range ds.Model.Species() {
+		speciesNames[species.ID] = species.CommonName
+	}
+	byID, _ := stats["species_distribution"].(map[string]float64)
+	distribution := make(map[string]float64, len(byID))
+	names := make([]string, 0, len(byID))
+	for id, share := range byID {
+		name := speciesNames[id]
+		if name == "" {
+			name = id
+		}
+		distribution[name] = share
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if distribution[names[i]] != distribution[names[j]] {
+			return distribution[names[i]] > distribution[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	for _, name := range names {
+		fmt.Fprintf(tw, "%s\t%.1f%%\n", name, distribution[name])
+	}
+	return tw.Flush()
+}
+
+func cmdLake(args []string, out io.Writer) error {
+	fs := newCommandFlags("lake", "lake <dow> [flags]")
+	format := fs.String("format", "table", "table or json")
+	positional, err := fs.parse(args, 1)
+	if err != nil {
+		return err
+	}
+	dow, err := strconv.Atoi(positional[0])
+	if err != nil {
+		return fmt.Errorf("invalid DOW number %q", positional[0])
+	}
+	ds, err := fs.load()
+	if err != nil {
+		return err
+	}
+	lake, err := ds.Fish.GetLakeContext(context.Background(), dow)
+	if err != nil {
+		return err
+	}
+	if lake == nil {
+		return fmt.Errorf("no lake with DOW number %d", dow)
+	}
+	sort.Slice(lake.Result.Surveys, func(i, j int) bool {
+		return lake.Result.Surveys[i].SurveyDate > lake.Result.Surveys[j].SurveyDate
+	})
+	if *format == "json" {
+		return writeJSON(out, lake)
+	}
+
+	speciesMap := ds.Model.Species()
+	fmt.Fprintf(out, "%s (DOW %d), %s County, %s: %d surveys\n\n", lake.Result.LakeName, dow,
+		lake.Result.CountyName, model.LakeState(*lake), len(lake.Result.Surveys))
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DATE\tTYPE\tSPECIES\tCATCH\tMEASURED\tLENGTH")
+	for _, survey := range lake.Result.Surveys {
+		for _, summary := range survey.FishCatchSummaries {
+			if summary.Species == nil {
+				continue
+			}
+			code := *summary.Species
+			name := code
+			if species, exists := speciesMap[code]; exists {
+				name = species.CommonName
+			}
+			catch := 0
+			if summary.TotalCatch != nil {
+				catch = *summary.TotalCatch
+			}
+			measured, lengths := 0, ""
+			if lengthData := survey.Lengths[code]; lengthData != nil {
+				for _, count := range lengthData.FishCount {
+					measured += count.Quantity
+				}
+				lengths = fmt.Sprintf("%d-%d", lengthData.MinimumLength, lengthData.MaximumLength)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%s\n", survey.SurveyDate, survey.SurveyType, name, catch, measured, lengths)
+		}
+	}
+	return tw.Flush()
+}
+
+// rejectedDocument is a survey document that failed to parse or validate.
+type rejectedDocument struct {
+	Path      string `json:"path"`
+	DOWNumber int    `json:"dow_number,omitempty"`
+	Error     string `json:"error"`
+}
+
+// validationResult is the outcome of checking a survey directory.
+type validationResult struct {
+	Source     string                                 `json:"source"`
+	Documents  int                                    `json:"documents"`
+	Lakes      int                                    `json:"lakes"`
+	Rejected   []rejectedDocument                     `json:"rejected"`
+	Validation *model.ValidationReport                `json:"validation"`
+	Unknown    []controller.UnknownSpeciesCode        `json:"unknown_species"`
+	Counties   *controller.CountyReconciliationReport `json:"counties"`
+}
+
+func cmdValidate(args []string, out io.Writer) error {
+	fs := newCommandFlags("validate", "validate <dir or file> [flags]")
+	sourceType := fs.String("source", "json_dir", "survey source type: json_dir, json_gzip, zip, ndjson or csv")
+	adapterName := fs.String("adapter", "mn_dnr", "survey adapter for the documents")
+	stateCode := fs.String("state", model.DefaultState, "state the surveys belong to")
+	format := fs.String("format", "table", "table or json")
+	positional, err := fs.parse(args, 1)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(fs.configPath)
+	if err != nil {
+		return err
+	}
+	m := &model.FishSurveyModel{}
+	fish := controller.NewFishSurveyController(m)
+	counties, err := configureCounties(cfg, fish)
+	if err != nil {
+		return err
+	}
+	if err := configureValidation(cfg, fish.Validation); err != nil {
+		return err
+	}
+	if err := controller.LoadSpeciesMap(m, cfg.Data.SpeciesFile); err != nil {
+		return fmt.Errorf("loading species data: %w", err)
+	}
+
+	source, err := controller.NewSurveySource(*sourceType, positional[0])
+	if err != nil {
+		return err
+	}
+	adapter, exists := controller.GetAdapter(*adapterName)
+	if !exists {
+		return fmt.Errorf("unknown survey adapter %q", *adapterName)
+	}
+	state := controller.StateDataset{Code: model.NormalizeState(*stateCode), Adapter: adapter}
+	if configured, exists := fish.FindState(state.Code); exists {
+		state.LakeIDOffset = configured.LakeIDOffset
+	}
+
+	result := validationResult{
+		Source:     source.Name(),
+		Rejected:   []rejectedDocument{},
+		Validation: model.NewValidationReport(),
+	}
+	m.FishDataByCounty = make(map[string][]model.FishData)
+	lakes := make(map[int]bool)
+	err = source.Documents(func(doc controller.SurveyDocument) error {
+		result.Documents++
+		docState := state
+		if doc.Adapter != nil {
+			docState.Adapter = doc.Adapter
+		}
+		parsed, report, err := controller.ParseStateData(doc.Data, m, docState, fish.Validation)
+		result.Validation.Merge(report)
+		if err != nil {
+			rejection := rejectedDocument{Path: doc.Path, Error: err.Error()}
+			if len(parsed) == 1 {
+				rejection.DOWNumber = parsed[0].Result.DOWNumber
+			}
+			result.Rejected = append(result.Rejected, rejection)
+			return nil
+		}
+		for _, lake := range parsed {
+			lakes[lake.Result.DOWNumber] = true
+			m.FishDataByCounty[lake.Result.CountyName] = append(m.FishDataByCounty[lake.Result.CountyName], lake)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	result.Lakes = len(lakes)
+	result.Unknown = controller.UnknownSpeciesCodes(controller.MaterializeAggregates(m, fish.Reconciler))
+	result.Counties = fish.Reconciler.ReconcileCounties(m.FishDataByCounty, counties)
+
+	if *format == "json" {
+		err = writeJSON(out, result)
+	} else {
+		err = writeValidationTable(out, result)
+	}
+	if err != nil {
+		return err
+	}
+	if len(result.Rejected) > 0 || result.Validation.DroppedSurveys > 0 {
+		return fmt.Errorf("%d documents rejected, %d surveys dropped", len(result.Rejected), result.Validation.DroppedSurveys)
+	}
+	return nil
+}
+
+func writeValidationTable(out io.Writer, result validationResult) error {
+	v := result.Validation
+	fmt.Fprintf(out, "%s: %d documents, %d lakes, %d surveys (%d dropped, %d flagged)\n",
+		result.Source, result.Documents, result.Lakes, v.Surveys, v.DroppedSurveys, v.FlaggedSurveys)
+
+	if len(result.Rejected) > 0 {
+		fmt.Fprintf(out, "\nRejected documents:\n")
+		for _, rejected := range result.Rejected {
+			fmt.Fprintf(out, "  %s: %s\n", rejected.Path, rejected.Error)
+		}
+	}
+
+	rules := make([]string, 0, len(v.Rules))
+	for name := range v.Rules {
+		rules = append(rules, name)
+	}
+	sort.Strings(rules)
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	if len(rules) > 0 {
+		fmt.Fprintln(tw, "\nRULE\tSEVERITY\tISSUES\tSURVEYS\tDROPPED\tFLAGGED\tFIXED")
+		for _, name := range rules {
+			rule := v.Rules[name]
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%d\n", name, rule.Severity, rule.Issues, rule.Surveys, rule.Dropped, rule.Flagged, rule.Fixed)
+		}
+	}
+	if len(result.Unknown) > 0 {
+		fmt.Fprintln(tw, "\nUNKNOWN SPECIES\tSURVEYS\tLAKES\tFISH MEASURED")
+		for _, unknown := range result.Unknown {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n", unknown.Code, unknown.Surveys, unknown.Lakes, unknown.FishMeasured)
+		}
+	}
+	if counties := result.Counties; counties != nil && len(counties.Unmatched) > 0 {
+		fmt.Fprintln(tw, "\nUNMATCHED COUNTY\tLAKES\tCLOSEST")
+		for _, unmatched := range counties.Unmatched {
+			closest := ""
+			if unmatched.Suggestion != "" {
+				closest = fmt.Sprintf("%s (%.2f)", unmatched.Suggestion, unmatched.Confidence)
+			}
+			fmt.Fprintf(tw, "%s\t%d\t%s\n", unmatched.Name, unmatched.Lakes, closest)
+		}
+	}
+	return tw.Flush()
+}
+
+func cmdBuildSnapshot(args []string, out io.Writer) error {
+	fs := newCommandFlags("build-snapshot", "build-snapshot [-o file] [flags]")
+	output := fs.String("o", "", "snapshot file (default data.snapshot_file from the config)")
+	if _, err := fs.parse(args, 0); err != nil {
+		return err
+	}
+	cfg, err := config.Load(fs.configPath)
+	if err != nil {
+		return err
+	}
+	path := *output
+	if path == "" {
+		path = cfg.Data.SnapshotFile
+	}
+	if path == "" {
+		return errors.New("no snapshot file: set data.snapshot_file or pass -o")
+	}
+
+	// Fingerprint before loading, so files edited during the build leave the
+	// snapshot stale rather than silently missing the edit.
+	states, err := stateDatasets(cfg)
+	if err != nil {
+		return err
+	}
+	fingerprint, err := snapshotFingerprint(cfg, states)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	cfg.Data.SnapshotFile = "" // always parse the data files
+	ds, err := loadDataset(cfg)
+	if err != nil {
+		return err
+	}
+	snapshot := controller.NewDatasetSnapshot(ds.Model, ds.Counties.GetCounties(), fingerprint)
+	size, err := controller.WriteSnapshot(path, snapshot)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Wrote %s: %d counties, %d species, %d lakes, %d surveys, %.1f MB in %s\n",
+		path, len(snapshot.Counties), len(snapshot.Species), snapshot.Lakes(), snapshot.Surveys(),
+		float64(size)/(1<<20), time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+func cmdDiff(args []string, out io.Writer) error {
+	fs := newCommandFlags("diff", "diff <from> [to] [flags]")
+	format := fs.String("format", "table", "output format: table or json")
+	paths, err := fs.parse(args, -1)
+	if err != nil {
+		return err
+	}
+	if len(paths) < 1 || len(paths) > 2 {
+		fs.Usage()
+		return errUsage
+	}
+	if *format != "table" && *format != "json" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	cfg, err := config.Load(fs.configPath)
+	if err != nil {
+		return err
+	}
+
+	// Without a second path the configured data is the newer side; loading it
+	// also sets up the states and validation the directories are parsed with.
+	var to map[string][]model.FishData
+	var fish *controller.FishSurveyController
+	toLabel := "configured data"
+	if len(paths) == 1 {
+		ds, err := loadDataset(cfg)
+		if err != nil {
+			return err
+		}
+		to, _ = ds.Model.Snapshot()
+		fish = ds.Fish
+	} else {
+		fish = controller.NewFishSurveyController(&model.FishSurveyModel{})
+		if _, err := configureCounties(cfg, fish); err != nil {
+			return err
+		}
+		if err := configureValidation(cfg, fish.Validation); err != nil {
+			return err
+		}
+		toLabel = paths[1]
+		if to, err = loadDiffData(cfg, paths[1], fish); err != nil {
+			return err
+		}
+	}
+	from, err := loadDiffData(cfg, paths[0], fish)
+	if err != nil {
+		return err
+	}
+
+	diff := controller.DiffDatasets(from, to, paths[0], toLabel)
+	if *format == "json" {
+		return writeJSON(out, diff)
+	}
+	return writeDiffTable(out, diff)
+}
+
+// loadDiffData reads the surveys of a survey directory, parsed with the
+// survey controller's states and validation, or a snapshot file.
+func loadDiffData(cfg *config.Config, path string, fish *controller.FishSurveyController) (map[string][]model.FishData, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		snapshot, err := controller.ReadSnapshot(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+		return snapshot.FishDataByCounty, nil
+	}
+	m := &model.FishSurveyModel{}
+	if err := controller.LoadSpeciesMap(m, cfg.Data.SpeciesFile); err != nil {
+		return nil, fmt.Errorf("loading species data: %w", err)
+	}
+	if err := controller.LoadFishData(context.Background(), m, path, fish.States, fish.Validation); err != nil {
+		return nil, fmt.Errorf("loading %s: %w", path, err)
+	}
+	return m.FishDataByCounty, nil
+}
+
+func writeDiffTable(out io.Writer, diff *model.DatasetDiff) error {
+	fmt.Fprintf(out, "%s -> %s: %d added, %d removed, %d modified, %d unchanged\n",
+		diff.From, diff.To, diff.Summary.Added, diff.Summary.Removed, diff.Summary.Modified, diff.Summary.Unchanged)
+
+	sections := []struct {
+		title   string
+		surveys []model.SurveyDiff
+	}{
+		{"Added", diff.Added},
+		{"Removed", diff.Removed},
+		{"Modified", diff.Modified},
+	}
+	for _, section := range sections {
+		if len(section.surveys) == 0 {
+			continue
+		}
+		fmt.Fprintf(out, "\n%s:\n", section.title)
+		for _, survey := range section.surveys {
+			lake := ""
+			if survey.LakeName != "" {
+				lake = fmt.Sprintf(" %s (%s)", survey.LakeName, survey.CountyName)
+			}
+			fmt.Fprintf(out, "  %s %d %s%s\n", survey.SurveyDate, survey.DOWNumber, survey.SurveyType, lake)
+			for _, change := range survey.Changes {
+				fmt.Fprintf(out, "      %s: %s -> %s\n", change.Field, diffValue(change.Old), diffValue(change.New))
+			}
+		}
+	}
+	return nil
+}
+
+// diffValue formats one side of a field change, shortening long text.
+func diffValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "(none)"
+	case string:
+		if runes := []rune(v); len(runes) > 60 {
+			v = string(runes[:57]) + "..."
+		}
+		return strconv.Quote(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// findSpecies looks a species up by common name, code or ID.
+func findSpecies(ds *dataset, name string) (model.Species, error) {
+	for code, species := range ds.Model.Species() {
+		if strings.EqualFold(species.CommonName, name) || strings.EqualFold(code, name) || species.ID == name {
+			return species, nil
+		}
+	}
+	return model.Species{}, fmt.Errorf("unknown species %q", name)
+}
+
+// findCounty looks a county up by ID or by name, matched like survey county
+// names.
+func findCounty(ds *dataset, name, state string) (*model.County, error) {
+	if county := ds.Counties.GetCountyByID(name); county != nil {
+		return county, nil
+	}
+	if state == "" {
+		state = model.DefaultState
+	}
+	match := ds.Fish.Reconciler.MatchInState(model.NormalizeState(state), name)
+	if match.Matched() {
+		if county := ds.Counties.GetCountyByID(match.CountyID); county != nil {
+			return county, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown county %q", name)
+}
+
+func writeJSON(out io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
@@ -0,0 +1,279 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"fishreports/model"
+)
+
+// Config holds the server settings loaded from the JSON config file.
+type Config struct {
+	Port       string           `json:"port"`
+	Data       DataConfig       `json:"data"`
+	Counties   CountiesConfig   `json:"counties"`
+	States     []StateConfig    `json:"states"` // empty serves Data's files as Minnesota DNR data
+	Webhooks   WebhooksConfig   `json:"webhooks"`
+	Validation ValidationConfig `json:"validation"`
+	Rankings   RankingsConfig   `json:"rankings"`
+	Auth       AuthConfig       `json:"auth"`
+	CORS       CORSConfig       `json:"cors"`
+	Security   SecurityConfig   `json:"security"`
+	TLS        TLSConfig        `json:"tls"`
+	Limits     LimitsConfig     `json:"limits"`
+	GRPC       GRPCConfig       `json:"grpc"`
+	GraphQL    GraphQLConfig    `json:"graphql"`
+}
+
+// DataConfig locates the data files and controls reloading.
+type DataConfig struct {
+	CountiesFile          string         `json:"counties_file"`
+	SpeciesFile           string         `json:"species_file"`
+	SurveyDir             string         `json:"survey_dir"`
+	Sources               []SourceConfig `json:"sources"`                 // read after survey_dir's JSON files
+	SurveyKeysFile        string         `json:"survey_keys_file"`        // survey keys of the last load, used to spot new surveys
+	LakeCountiesFile      string         `json:"lake_counties_file"`      // counties of lakes spanning county lines, by DOW number
+	AgeLengthKeysFile     string         `json:"age_length_keys_file"`    // proportions at age by length class, by species code
+	SpeciesStatusFile     string         `json:"species_status_file"`     // species codes by status category, e.g. invasive
+	SnapshotFile          string         `json:"snapshot_file"`           // written by build-snapshot; loaded at startup while it is current
+	ReloadIntervalSeconds int            `json:"reload_interval_seconds"` // 0 disables periodic reloads
+	EventLogSize          int            `json:"event_log_size"`          // survey events kept for Last-Event-ID resume
+}
+
+// CountiesConfig controls how survey county names are matched to counties.
+type CountiesConfig struct {
+	Aliases            map[string]string `json:"aliases"`              // survey spelling -> county name
+	MinFuzzyConfidence float64           `json:"min_fuzzy_confidence"` // 0-1; closer names below it stay unmatched
+}
+
+// StateConfig describes one state's survey dataset. LakeIDOffset is added to
+// the agency's lake IDs so they don't collide with other states'; keep the
+// results below 2^31 for gRPC clients.
+type StateConfig struct {
+	Code         string         `json:"code"`
+	Name         string         `json:"name"`
+	Agency       string         `json:"agency"`
+	CountiesFile string         `json:"counties_file"`
+	SurveyDir    string         `json:"survey_dir"`
+	Adapter      string         `json:"adapter"` // registered survey adapter, e.g. "mn_dnr" or "fishdata"
+	LakeIDOffset int            `json:"lake_id_offset"`
+	Sources      []SourceConfig `json:"sources"` // read after survey_dir's JSON files
+}
+
+// SourceConfig is one input the survey data is read from. Type is a
+// registered source type: "json_dir", "json_gzip", "zip", "ndjson" or "csv".
+// Path is a directory or a single file.
+type SourceConfig struct {
+	Type string `json:"type"`
+	Path string `json:"path"`
+}
+
+// ValidationConfig overrides the severity of survey validation rules by rule
+// name: "drop", "flag", "fix" or "off".
+type ValidationConfig struct {
+	Rules map[string]string `json:"rules"`
+}
+
+// RankingsConfig controls how GET /rankings/lakes scores lakes for a species.
+type RankingsConfig struct {
+	Weights              model.RankingWeights `json:"weights"`
+	RecentSurveys        int                  `json:"recent_surveys"`          // latest surveys of the species scored per lake
+	RecencyHalfLifeYears float64              `json:"recency_half_life_years"` // the recency value halves every this many years
+}
+
+// WebhooksConfig controls outbound webhook delivery.
+type WebhooksConfig struct {
+	Enabled               bool   `json:"enabled"`
+	File                  string `json:"file"` // subscription store
+	MaxAttempts           int    `json:"max_attempts"`
+	InitialBackoffSeconds int    `json:"initial_backoff_seconds"` // doubled after each failed retry
+	MaxBackoffSeconds     int    `json:"max_backoff_seconds"`
+	TimeoutSeconds        int    `json:"timeout_seconds"` // per delivery request
+	Concurrency           int    `json:"concurrency"`     // simultaneous delivery requests
+	DeliveryLogSize       int    `json:"delivery_log_size"`
+}
+
+// AuthConfig controls API key authentication and rate limiting.
+type AuthConfig struct {
+	Enabled              bool           `json:"enabled"`
+	KeysFile             string         `json:"keys_file"` // JSON array of API keys, merged with Keys
+	Keys                 []model.APIKey `json:"keys"`
+	DefaultRatePerMinute int            `json:"default_rate_per_minute"`
+	DefaultBurst         int            `json:"default_burst"`
+}
+
+// CORSConfig controls cross-origin access from browser clients. CORS is
+// disabled when AllowedOrigins is empty; "*" allows any origin.
+type CORSConfig struct {
+	AllowedOrigins   []string `json:"allowed_origins"`
+	AllowedMethods   []string `json:"allowed_methods"`
+	AllowedHeaders   []string `json:"allowed_headers"`
+	ExposedHeaders   []string `json:"exposed_headers"`
+	AllowCredentials bool     `json:"allow_credentials"`
+	MaxAgeSeconds    int      `json:"max_age_seconds"`
+}
+
+// SecurityConfig controls the standard security response headers.
+type SecurityConfig struct {
+	Headers               bool   `json:"headers"`
+	ContentSecurityPolicy string `json:"content_security_policy"`
+	HSTSMaxAgeSeconds     int    `json:"hsts_max_age_seconds"` // sent only over TLS; 0 disables HSTS
+}
+
+// TLSConfig controls native TLS. With Autocert enabled, certificates are
+// obtained from an ACME CA and CertFile/KeyFile are ignored.
+type TLSConfig struct {
+	Enabled               bool           `json:"enabled"`
+	CertFile              string         `json:"cert_file"`
+	KeyFile               string         `json:"key_file"`
+	ReloadIntervalSeconds int            `json:"reload_interval_seconds"` // how often to check the files for changes
+	Autocert              AutocertConfig `json:"autocert"`
+}
+
+// AutocertConfig controls ACME certificate management.
+type AutocertConfig struct {
+	Enabled   bool     `json:"enabled"`
+	Domains   []string `json:"domains"`
+	Email     string   `json:"email"`
+	CacheDir  string   `json:"cache_dir"`
+	HTTPPort  string   `json:"http_port"` // serves ACME HTTP-01 challenges and redirects to HTTPS
+	Directory string   `json:"directory"` // ACME directory URL; empty uses Let's Encrypt
+}
+
+// LimitsConfig bounds how much work a single request may cause.
+type LimitsConfig struct {
+	MaxBodyBytes   int64            `json:"max_body_bytes"`
+	RouteBodyBytes map[string]int64 `json:"route_body_bytes"` // overrides keyed by gin route pattern
+	Timeouts       TimeoutConfig    `json:"timeouts"`
+}
+
+// TimeoutConfig sets per-route request deadlines in seconds. Routes are keyed
+// by their gin pattern (e.g. "/species/id/:species_id"); 0 disables the deadline.
+type TimeoutConfig struct {
+	DefaultSeconds int            `json:"default_seconds"`
+	Routes         map[string]int `json:"routes"`
+}
+
+// GRPCConfig controls the gRPC API served alongside REST.
+type GRPCConfig struct {
+	Enabled bool   `json:"enabled"`
+	Port    string `json:"port"`
+}
+
+// GraphQLConfig controls the /graphql endpoint and its query limits.
+type GraphQLConfig struct {
+	Enabled  bool `json:"enabled"`
+	MaxCost  int  `json:"max_cost"`  // estimated objects a query may resolve
+	MaxDepth int  `json:"max_depth"` // nesting of object fields
+}
+
+// Default returns the configuration used when no config file is present.
+func Default() *Config {
+	return &Config{
+		Port: "8080",
+		Data: DataConfig{
+			CountiesFile:      "data/minnesota_counties.json",
+			SpeciesFile:       "data/fish_species.json",
+			SurveyDir:         "data/surveys",
+			SurveyKeysFile:    "data/survey_keys.json",
+			LakeCountiesFile:  "data/lake_counties.json",
+			AgeLengthKeysFile: "data/age_length_keys.json",
+			SpeciesStatusFile: "data/species_status.json",
+			SnapshotFile:      "data/dataset.snapshot",
+			EventLogSize:      1000,
+		},
+		Counties: CountiesConfig{
+			Aliases:            map[string]string{"St Louis": "Saint Louis"},
+			MinFuzzyConfidence: 0.8,
+		},
+		Webhooks: WebhooksConfig{
+			Enabled:               true,
+			File:                  "data/webhooks.json",
+			MaxAttempts:           6,
+			InitialBackoffSeconds: 5,
+			MaxBackoffSeconds:     600,
+			TimeoutSeconds:        10,
+			Concurrency:           4,
+			DeliveryLogSize:       1000,
+		},
+		Auth: AuthConfig{
+			Enabled:              false,
+			KeysFile:             "data/api_keys.json",
+			DefaultRatePerMinute: 60,
+			DefaultBurst:         20,
+		},
+		CORS: CORSConfig{
+			AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+			AllowedHeaders: []string{"Authorization", "Content-Type", "X-API-Key", "Last-Event-ID"},
+			ExposedHeaders: []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After"},
+			MaxAgeSeconds:  600,
+		},
+		Security: SecurityConfig{
+			Headers:               true,
+			ContentSecurityPolicy: "default-src 'none'; frame-ancestors 'none'",
+			HSTSMaxAgeSeconds:     31536000,
+		},
+		Limits: LimitsConfig{
+			MaxBodyBytes: 1 << 20,
+			RouteBodyBytes: map[string]int64{
+				"/admin/surveys": 32 << 20,
+			},
+			Timeouts: TimeoutConfig{
+				DefaultSeconds: 10,
+				Routes: map[string]int{
+					"/surveys":        20,
+					"/events/surveys": 0,
+					"/admin/surveys":  60,
+					"/admin/reload":   300,
+				},
+			},
+		},
+		Rankings: RankingsConfig{
+			Weights:              model.RankingWeights{Catch: 0.4, Size: 0.3, Recency: 0.15, Trend: 0.15},
+			RecentSurveys:        3,
+			RecencyHalfLifeYears: 5,
+		},
+		GraphQL: GraphQLConfig{
+			Enabled:  true,
+			MaxCost:  20000,
+			MaxDepth: 8,
+		},
+		GRPC: GRPCConfig{
+			Port: "9090",
+		},
+		TLS: TLSConfig{
+			ReloadIntervalSeconds: 60,
+			Autocert: AutocertConfig{
+				CacheDir: "data/autocert",
+				HTTPPort: "80",
+			},
+		},
+	}
+}
+
+// Load reads the config file at path on top of the defaults. A missing file
+// is not an error; the defaults are returned.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	file, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := json.Unmarshal(file, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config JSON: %w", err)
+	}
+	return cfg, nil
+}
+
+// Path returns the config file location, overridable with FISHREPORTS_CONFIG.
+func Path() string {
+	if p := os.Getenv("FISHREPORTS_CONFIG"); p != "" {
+		return p
+	}
+	return "config.json"
+}
@@ -0,0 +1,237 @@
+package controller
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"fishreports/model"
+)
+
+// MaterializeAggregates precomputes the per-species, per-county, per-lake and
+// per-year rollups served by the stats endpoints and stores them on the model,
+// along with the same rollups for each state. It must run after LoadFishData
+// and LoadSpeciesMap so species codes can be resolved to IDs.
+func MaterializeAggregates(m *model.FishSurveyModel, reconciler *CountyReconciler) *model.Aggregates {
+	agg := materializeAggregates(m.FishDataByCounty, m.SpeciesMap, reconciler)
+
+	// Per-state rollups back the state filter.
+	byState := make(map[string]map[string][]model.FishData)
+	for countyName, fishDataList := range m.FishDataByCounty {
+		for _, data := range fishDataList {
+			state := model.LakeState(data)
+			if byState[state] == nil {
+				byState[state] = make(map[string][]model.FishData)
+			}
+			byState[state][countyName] = append(byState[state][countyName], data)
+		}
+	}
+	for state, fishDataByCounty := range byState {
+		agg.ByState[state] = materializeAggregates(fishDataByCounty, m.SpeciesMap, reconciler)
+	}
+
+	m.Aggregates = agg
+	log.Printf("✅ Materialized aggregates: %d species, %d counties, %d lakes, %d years, %d states",
+		len(agg.Species), len(agg.Counties), len(agg.Lakes), len(agg.Years), len(agg.ByState))
+	if len(agg.UnknownSpecies) > 0 {
+		log.Printf("❌ %d species codes in the survey data are missing from the species catalog", len(agg.UnknownSpecies))
+	}
+	return agg
+}
+
+// materializeAggregates builds the rollups for a set of lakes, grouped under
+// the counties the reconciler matches them to.
+func materializeAggregates(fishDataByCounty map[string][]model.FishData, speciesMap map[string]model.Species, reconciler *CountyReconciler) *model.Aggregates {
+	agg := model.NewAggregates()
+
+	for _, fishDataList := range fishDataByCounty {
+		for _, data := range fishDataList {
+			// A lake spanning county lines counts toward each of its counties.
+			lakeCounties := reconciler.countyKeys(data)
+			lakeName := data.Result.LakeName
+
+			agg.AllLakes[strings.ToLower(lakeName)] = true
+			counties := make([]*model.CountyAggregate, 0, len(lakeCounties))
+			for _, normalizedCounty := range lakeCounties {
+				if agg.AllLakesByCounty[normalizedCounty] == nil {
+					agg.AllLakesByCounty[normalizedCounty] = make(map[string]bool)
+				}
+				agg.AllLakesByCounty[normalizedCounty][lakeName] = true
+
+				county := agg.Counties[normalizedCounty]
+				if county == nil {
+					county = &model.CountyAggregate{
+						NormalizedName: normalizedCounty,
+						SpeciesCounts:  make(map[string]int),
+						Lakes:          make(map[string]bool),
+					}
+					agg.Counties[normalizedCounty] = county
+				}
+				county.Lakes[lakeName] = true
+				counties = append(counties, county)
+			}
+
+			lake := agg.Lakes[data.Result.DOWNumber]
+			if lake == nil {
+				lake = &model.LakeAggregate{
+					DOWNumber:     data.Result.DOWNumber,
+					LakeName:      lakeName,
+					CountyName:    data.Result.CountyName,
+					State:         model.LakeState(data),
+					SpeciesCounts: make(map[string]int),
+				}
+				agg.Lakes[data.Result.DOWNumber] = lake
+			}
+
+			for _, survey := range data.Result.Surveys {
+				for _, county := range counties {
+					county.SurveyIDs = append(county.SurveyIDs, survey.SurveyID)
+					county.TotalSurveys++
+				}
+				lake.TotalSurveys++
+
+				year := surveyYear(survey.SurveyDate)
+				var yearAgg *model.YearAggregate
+				if year > 0 {
+					yearAgg = agg.Years[year]
+					if yearAgg == nil {
+						yearAgg = &model.YearAggregate{
+							Year:          year,
+							SpeciesCounts: make(map[string]int),
+							Lakes:         make(map[int]bool),
+						}
+						agg.Years[year] = yearAgg
+					}
+					yearAgg.TotalSurveys++
+					yearAgg.Lakes[data.Result.DOWNumber] = true
+					if lake.FirstYear == 0 || year < lake.FirstYear {
+						lake.FirstYear = year
+					}
+					if year > lake.LastYear {
+						lake.LastYear = year
+					}
+				}
+
+				for _, code := range surveySpeciesCodes(&survey) {
+					if _, known := speciesMap[code]; !known {
+						recordUnknownSpecies(agg, code, data.Result.DOWNumber, survey)
+					}
+				}
+
+				for _, summary := range survey.FishCatchSummaries {
+					if summary.Species == nil || summary.TotalCatch == nil {
+						continue
+					}
+					code := *summary.Species
+					count := *summary.TotalCatch
+
+					// Unknown codes stay out of the species distribution.
+					speciesInfo, known := speciesMap[code]
+					for _, county := range counties {
+						if known {
+							county.SpeciesCounts[speciesInfo.ID] += count
+						} else {
+							county.UnknownCatch += count
+						}
+						county.TotalFishCaught += count
+					}
+					if unknown := agg.UnknownSpecies[code]; !known && unknown != nil {
+						unknown.TotalCatch += count
+					}
+
+					lake.SpeciesCounts[code] += count
+					lake.TotalFishCaught += count
+
+					if yearAgg != nil {
+						yearAgg.SpeciesCounts[code] += count
+						yearAgg.TotalFishCaught += count
+					}
+				}
+
+				for code, lengthData := range survey.Lengths {
+					sa := agg.Species[code]
+					if sa == nil {
+						sa = newSpeciesAggregate(code)
+						agg.Species[code] = sa
+					}
+					accumulateSpecies(sa, lakeCounties, lakeName, lengthData)
+					if unknown := agg.UnknownSpecies[code]; unknown != nil && lengthData != nil {
+						for _, count := range lengthData.FishCount {
+							unknown.FishMeasured += count.Quantity
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return agg
+}
+
+// recordUnknownSpecies counts one survey mentioning a code that isn't in the
+// species catalog.
+func recordUnknownSpecies(agg *model.Aggregates, code string, dowNumber int, survey model.Survey) {
+	unknown := agg.UnknownSpecies[code]
+	if unknown == nil {
+		unknown = &model.UnknownSpeciesAggregate{Code: code, Lakes: make(map[int]bool)}
+		agg.UnknownSpecies[code] = unknown
+	}
+	unknown.Surveys++
+	unknown.Lakes[dowNumber] = true
+	if survey.SurveyDate != "" {
+		if unknown.FirstSeen == "" || survey.SurveyDate < unknown.FirstSeen {
+			unknown.FirstSeen = survey.SurveyDate
+		}
+		if survey.SurveyDate > unknown.LastSeen {
+			unknown.LastSeen = survey.SurveyDate
+		}
+	}
+}
+
+// newSpeciesAggregate returns an empty rollup for the given species code.
+func newSpeciesAggregate(code string) *model.SpeciesAggregate {
+	return &model.SpeciesAggregate{
+		Code:           code,
+		ShortestLength: 1<<31 - 1, // max int value
+		Histogram:      make(map[int]int),
+		Lakes:          make(map[string]bool),
+		LakesByCounty:  make(map[string]map[string]bool),
+	}
+}
+
+// accumulateSpecies folds one survey's length data for a species into its rollup.
+func accumulateSpecies(sa *model.SpeciesAggregate, lakeCounties []string, lakeName string, lengthData *model.LengthData) {
+	sa.SurveyCount++
+	sa.Lakes[strings.ToLower(lakeName)] = true
+	for _, normalizedCounty := range lakeCounties {
+		if sa.LakesByCounty[normalizedCounty] == nil {
+			sa.LakesByCounty[normalizedCounty] = make(map[string]bool)
+		}
+		sa.LakesByCounty[normalizedCounty][lakeName] = true
+	}
+
+	if lengthData == nil {
+		return
+	}
+	for _, count := range lengthData.FishCount {
+		sa.Histogram[count.Length] += count.Quantity
+		sa.TotalLengthSum += count.Length * count.Quantity
+		sa.TotalQuantity += count.Quantity
+
+		if count.Length > sa.BiggestLength {
+			sa.BiggestLength = count.Length
+		}
+		if count.Length < sa.ShortestLength {
+			sa.ShortestLength = count.Length
+		}
+	}
+}
+
+// surveyYear extracts the year from a survey date, returning 0 when it can't be parsed.
+func surveyYear(surveyDate string) int {
+	if len(surveyDate) < 4 {
+		return 0
+	}
+	year, _ := strconv.Atoi(surveyDate[:4])
+	return year
+}
@@ -0,0 +1,303 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"fishreports/model"
+)
+
+// fixtureCounties are the counties of the test survey data.
+func fixtureCounties() []model.County {
+	counties := []model.County{
+		{CountyName: "Aitkin", State: "MN", FIPSCode: "27001"},
+		{CountyName: "Cass", State: "MN", FIPSCode: "27021"},
+		{CountyName: "Crow Wing", State: "MN", FIPSCode: "27035"},
+	}
+	for i := range counties {
+		counties[i].ID = StableCountyID(counties[i])
+	}
+	return counties
+}
+
+func fixtureSpecies() map[string]model.Species {
+	species := map[string]model.Species{
+		"WAE": {Code: "WAE", CommonName: "walleye", GameFish: true},
+		"NOP": {Code: "NOP", CommonName: "northern pike", GameFish: true},
+		"YEP": {Code: "YEP", CommonName: "yellow perch"},
+		"BLG": {Code: "BLG", CommonName: "bluegill", GameFish: true},
+	}
+	for code, s := range species {
+		s.ID = StableSpeciesID(code)
+		species[code] = s
+	}
+	return species
+}
+
+func fixtureLake(dow int, county, lakeName string, surveys ...model.Survey) model.FishData {
+	var data model.FishData
+	data.Result.DOWNumber = dow
+	data.Result.CountyName = county
+	data.Result.LakeName = lakeName
+	data.Result.Surveys = surveys
+	return data
+}
+
+func fixtureSurvey(id, date string, catches map[string]int, lengths map[string][]model.FishCount) model.Survey {
+	survey := model.Survey{SurveyID: id, SurveyDate: date, SurveyType: "Standard Survey", Lengths: make(map[string]*model.LengthData)}
+	codes := make([]string, 0, len(catches))
+	for code := range catches {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		code, catch := code, catches[code]
+		survey.FishCatchSummaries = append(survey.FishCatchSummaries, model.FishCatchSummary{Species: &code, TotalCatch: &catch})
+	}
+	for code, counts := range lengths {
+		if counts == nil {
+			survey.Lengths[code] = nil
+			continue
+		}
+		survey.Lengths[code] = &model.LengthData{FishCount: counts}
+	}
+	return survey
+}
+
+// newFixtureReconciler matches the fixture counties, with Long Lake spanning
+// into Crow Wing.
+func newFixtureReconciler() *CountyReconciler {
+	reconciler := NewCountyReconciler(DefaultCountyAliases(), 0.8)
+	reconciler.SetCounties(fixtureCounties())
+	reconciler.SetLakeCounties(map[int][]string{11000200: {"Crow Wing"}})
+	return reconciler
+}
+
+// newFixtureModel returns a small survey dataset: a lake in Aitkin, a lake
+// spanning Cass and Crow Wing, a lake in an unknown county, a species code
+// missing from the catalog and a survey with nil length data.
+func newFixtureModel(t *testing.T) *model.FishSurveyModel {
+	t.Helper()
+
+	big := fixtureLake(1000100, "Aitkin", "Big Lake",
+		fixtureSurvey("s1", "2015-06-10",
+			map[string]int{"WAE": 12, "NOP": 4, "YEP": 30},
+			map[string][]model.FishCount{
+				"WAE": {{Length: 14, Quantity: 3}, {Length: 18, Quantity: 2}, {Length: 22, Quantity: 1}},
+				"NOP": {{Length: 24, Quantity: 2}, {Length: 30, Quantity: 1}},
+				"YEP": {{Length: 6, Quantity: 10}, {Length: 8, Quantity: 5}},
+			}),
+		fixtureSurvey("s2", "2021-07-02",
+			map[string]int{"WAE": 8, "XYZ": 3},
+			map[string][]model.FishCount{
+				"WAE": {{Length: 16, Quantity: 4}, {Length: 25, Quantity: 1}},
+				"XYZ": {{Length: 5, Quantity: 3}},
+			}),
+	)
+	long := fixtureLake(11000200, "Cass", "Long Lake",
+		fixtureSurvey("s3", "2019-08-15",
+			map[string]int{"WAE": 5, "BLG": 40},
+			map[string][]model.FishCount{
+				"WAE": {{Length: 20, Quantity: 2}},
+				"BLG": {{Length: 7, Quantity: 20}},
+				"NOP": nil,
+			}),
+	)
+	mystery := fixtureLake(99000100, "Nowhere", "Mystery Lake",
+		fixtureSurvey("s4", "2020-05-20",
+			map[string]int{"YEP": 9},
+			map[string][]model.FishCount{"YEP": {{Length: 7, Quantity: 9}}}),
+	)
+	return &model.FishSurveyModel{
+		FishDataByCounty: map[string][]model.FishData{
+			"Aitkin":  {big},
+			"Cass":    {long},
+			"Nowhere": {mystery},
+		},
+		SpeciesMap: fixtureSpecies(),
+	}
+}
+
+func TestMaterializedSpeciesStatsMatchComputed(t *testing.T) {
+	m := newFixtureModel(t)
+	reconciler := newFixtureReconciler()
+	agg := MaterializeAggregates(m, reconciler)
+	opts := DefaultHistogramOptions()
+
+	codes := []string{"XYZ", "ZZZ"}
+	for code := range m.SpeciesMap {
+		codes = append(codes, code)
+	}
+	for _, code := range codes {
+		computed, err := computeSpeciesStats(context.Background(), m.FishDataByCounty, code, code, opts, reconciler)
+		if err != nil {
+			t.Fatalf("%s: computeSpeciesStats: %v", code, err)
+		}
+		sa := agg.Species[code]
+		if sa == nil {
+			sa = newSpeciesAggregate(code)
+		}
+		stored := buildSpeciesStats(code, sa, agg.AllLakes, agg.AllLakesByCounty, opts, reconciler)
+		if !reflect.DeepEqual(stored, computed) {
+			t.Errorf("%s: stored stats\n%v\ndiffer from computed\n%v", code, stored, computed)
+		}
+	}
+}
+
+func TestMaterializedSpeciesStatsGolden(t *testing.T) {
+	m := newFixtureModel(t)
+	reconciler := newFixtureReconciler()
+	agg := MaterializeAggregates(m, reconciler)
+
+	tests := []struct {
+		code          string
+		totalFish     int
+		biggest       int
+		shortest      int
+		averageLength float64
+		percentLakes  int
+	}{
+		{"WAE", 13, 25, 14, float64(3*14+2*18+22+4*16+25+2*20) / 13, 67},
+		{"NOP", 3, 30, 24, 26, 67}, // Long Lake's nil length data still counts the lake
+		{"BLG", 20, 7, 7, 7, 33},
+		{"YEP", 24, 8, 6, float64(10*6+5*8+9*7) / 24, 67},
+		{"XYZ", 3, 5, 5, 5, 33},
+	}
+	for _, tt := range tests {
+		stats := buildSpeciesStats(tt.code, agg.Species[tt.code], agg.AllLakes, agg.AllLakesByCounty, DefaultHistogramOptions(), reconciler)
+		if stats["total_fish"] != tt.totalFish || stats["biggest_length"] != tt.biggest || stats["shortest_length"] != tt.shortest {
+			t.Errorf("%s: total %v, biggest %v, shortest %v; want %d, %d, %d", tt.code,
+				stats["total_fish"], stats["biggest_length"], stats["shortest_length"], tt.totalFish, tt.biggest, tt.shortest)
+		}
+		if stats["average_length"] != tt.averageLength || stats["percent_lakes"] != tt.percentLakes {
+			t.Errorf("%s: average %v, percent %v; want %v, %d", tt.code,
+				stats["average_length"], stats["percent_lakes"], tt.averageLength, tt.percentLakes)
+		}
+	}
+
+	if got := agg.Species["NOP"].SurveyCount; got != 2 {
+		t.Errorf("NOP survey count = %d, want 2", got)
+	}
+	unknown := agg.UnknownSpecies["XYZ"]
+	if unknown == nil || unknown.Surveys != 1 || unknown.FishMeasured != 3 || unknown.TotalCatch != 3 {
+		t.Errorf("unknown XYZ = %+v, want 1 survey, 3 measured, 3 caught", unknown)
+	}
+	if len(agg.Lakes) != 3 || agg.Lakes[1000100].FirstYear != 2015 || agg.Lakes[1000100].LastYear != 2021 {
+		t.Errorf("lake rollups = %+v", agg.Lakes)
+	}
+	if year := agg.Years[2015]; year == nil || year.TotalFishCaught != 46 || year.TotalSurveys != 1 {
+		t.Errorf("2015 rollup = %+v, want 46 fish in 1 survey", year)
+	}
+}
+
+func TestMaterializedCountyStatsMatchComputed(t *testing.T) {
+	m := newFixtureModel(t)
+	reconciler := newFixtureReconciler()
+	counties := EnhanceCountiesWithLakes(m, fixtureCounties(), reconciler)
+
+	computed := NewCountyController(counties, m, reconciler)
+	materialized := &model.FishSurveyModel{FishDataByCounty: m.FishDataByCounty, SpeciesMap: m.SpeciesMap}
+	MaterializeAggregates(materialized, reconciler)
+	stored := NewCountyController(counties, materialized, reconciler)
+
+	for i := range counties {
+		county := &counties[i]
+		want, err := computed.GetCountyStatsContext(context.Background(), county)
+		if err != nil {
+			t.Fatalf("%s: %v", county.CountyName, err)
+		}
+		got, err := stored.GetCountyStatsContext(context.Background(), county)
+		if err != nil {
+			t.Fatalf("%s: %v", county.CountyName, err)
+		}
+		sort.Strings(want["survey_ids"].([]string))
+		sort.Strings(got["survey_ids"].([]string))
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: stored stats\n%v\ndiffer from computed\n%v", county.CountyName, got, want)
+		}
+	}
+}
+
+func TestCountyStatsGolden(t *testing.T) {
+	m := newFixtureModel(t)
+	reconciler := newFixtureReconciler()
+	MaterializeAggregates(m, reconciler)
+	counties := EnhanceCountiesWithLakes(m, fixtureCounties(), reconciler)
+	cc := NewCountyController(counties, m, reconciler)
+	species := fixtureSpecies()
+
+	tests := []struct {
+		county   string
+		lakes    int
+		surveys  int
+		caught   int
+		unknown  int
+		walleyes int
+	}{
+		{"Aitkin", 1, 2, 57, 3, 20},
+		{"Cass", 1, 1, 45, 0, 5},
+		{"Crow Wing", 1, 1, 45, 0, 5}, // Long Lake spans into Crow Wing
+	}
+	for _, tt := range tests {
+		var county *model.County
+		for i := range counties {
+			if counties[i].CountyName == tt.county {
+				county = &counties[i]
+			}
+		}
+		stats := cc.GetCountyStats(county)
+		if stats["number_of_lakes"] != tt.lakes || stats["total_surveys"] != tt.surveys {
+			t.Errorf("%s: %v lakes, %v surveys; want %d, %d", tt.county, stats["number_of_lakes"], stats["total_surveys"], tt.lakes, tt.surveys)
+		}
+		if stats["total_fish_caught"] != tt.caught || stats["unknown_species_fish_caught"] != tt.unknown {
+			t.Errorf("%s: %v caught, %v unknown; want %d, %d", tt.county, stats["total_fish_caught"], stats["unknown_species_fish_caught"], tt.caught, tt.unknown)
+		}
+		distribution := stats["species_distribution"].(map[string]float64)
+		want := float64(tt.walleyes) / float64(tt.caught) * 100
+		if got := distribution[species["WAE"].ID]; got < want-0.01 || got > want+0.01 {
+			t.Errorf("%s: walleye share %v, want %.2f", tt.county, got, want)
+		}
+	}
+}
+
+func TestCountyStatsDoNotShareTheRollup(t *testing.T) {
+	m := newFixtureModel(t)
+	reconciler := newFixtureReconciler()
+	agg := MaterializeAggregates(m, reconciler)
+	counties := EnhanceCountiesWithLakes(m, fixtureCounties(), reconciler)
+	cc := NewCountyController(counties, m, reconciler)
+
+	stats := cc.GetCountyStats(&counties[0])
+	surveyIDs := stats["survey_ids"].([]string)
+	if len(surveyIDs) == 0 {
+		t.Fatal("no survey IDs for Aitkin")
+	}
+	surveyIDs[0] = "changed"
+	_ = append(surveyIDs[:1], "appended")
+
+	rollup := agg.Counties[countyAggregateKey(counties[0])]
+	for _, id := range rollup.SurveyIDs {
+		if id == "changed" || id == "appended" {
+			t.Fatalf("rollup survey IDs changed through the response: %v", rollup.SurveyIDs)
+		}
+	}
+	if again := cc.GetCountyStats(&counties[0]); again["survey_ids"].([]string)[0] == "changed" {
+		t.Error("a later response saw the change")
+	}
+}
+
+func TestStateAggregates(t *testing.T) {
+	agg := MaterializeAggregates(newFixtureModel(t), newFixtureReconciler())
+	state := agg.ByState[model.DefaultState]
+	if len(agg.ByState) != 1 || state == nil || state == agg || len(state.ByState) != 0 {
+		t.Fatalf("ByState = %v, want one Minnesota rollup of its own", agg.ByState)
+	}
+	// With every lake in one state its rollups are the statewide ones.
+	statewide := *agg
+	statewide.ByState = state.ByState
+	if !reflect.DeepEqual(&statewide, state) {
+		t.Error("the Minnesota rollups differ from the statewide ones")
+	}
+}
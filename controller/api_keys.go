@@ -0,0 +1,182 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"fishreports/model"
+)
+
+// LoadAPIKeys reads API keys from a JSON file. A missing file yields no keys.
+func LoadAPIKeys(filePath string) ([]model.APIKey, error) {
+	var keys []model.APIKey
+	file, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API key file: %w", err)
+	}
+	if err := json.Unmarshal(file, &keys); err != nil {
+		return nil, fmt.Errorf("failed to parse API key JSON: %w", err)
+	}
+	return keys, nil
+}
+
+// RateLimitStatus is the outcome of a rate limit check for one request.
+type RateLimitStatus struct {
+	Allowed    bool
+	Limit      int           // requests per window
+	Remaining  int           // whole tokens left after this request
+	Reset      time.Duration // time until the bucket is full again
+	RetryAfter time.Duration // time until the next token, zero when allowed
+	Window     time.Duration
+}
+
+// tokenBucket is a classic token bucket refilled continuously.
+type tokenBucket struct {
+	capacity   float64
+	tokens     float64
+	refillRate float64 // tokens per second
+	last       time.Time
+}
+
+// take refills the bucket up to now and consumes one token if available.
+func (b *tokenBucket) take(now time.Time) bool {
+	elapsed := now.Sub(b.last).Seconds()
+	b.tokens = math.Min(b.capacity, b.tokens+elapsed*b.refillRate)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// APIKeyController authenticates API keys and applies per-key rate limits.
+type APIKeyController struct {
+	keys                 map[string]model.APIKey
+	buckets              map[string]*tokenBucket
+	defaultRatePerMinute int
+	defaultBurst         int
+	mu                   sync.Mutex
+}
+
+// NewAPIKeyController creates a controller for the given keys. Disabled keys
+// are dropped.
+func NewAPIKeyController(keys []model.APIKey, defaultRatePerMinute, defaultBurst int) *APIKeyController {
+	kc := &APIKeyController{
+		keys:                 make(map[string]model.APIKey),
+		buckets:              make(map[string]*tokenBucket),
+		defaultRatePerMinute: defaultRatePerMinute,
+		defaultBurst:         defaultBurst,
+	}
+	for _, key := range keys {
+		if key.Key == "" || key.Disabled {
+			continue
+		}
+		kc.keys[key.Key] = key
+	}
+	log.Printf("✅ Loaded %d API keys", len(kc.keys))
+	return kc
+}
+
+// KeyCount returns the number of enabled keys.
+func (kc *APIKeyController) KeyCount() int {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	return len(kc.keys)
+}
+
+// Authenticate returns the API key matching the presented secret.
+func (kc *APIKeyController) Authenticate(secret string) (*model.APIKey, bool) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	key, ok := kc.keys[secret]
+	if !ok {
+		return nil, false
+	}
+	return &key, true
+}
+
+// Allow consumes one request from the key's token bucket.
+func (kc *APIKeyController) Allow(key *model.APIKey) RateLimitStatus {
+	ratePerMinute := key.RatePerMinute
+	if ratePerMinute <= 0 {
+		ratePerMinute = kc.defaultRatePerMinute
+	}
+	burst := key.Burst
+	if burst <= 0 {
+		burst = kc.defaultBurst
+	}
+	if burst <= 0 {
+		burst = ratePerMinute
+	}
+
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	now := time.Now()
+	bucket, exists := kc.buckets[key.Key]
+	if !exists {
+		bucket = &tokenBucket{
+			capacity:   float64(burst),
+			tokens:     float64(burst),
+			refillRate: float64(ratePerMinute) / 60.0,
+			last:       now,
+		}
+		kc.buckets[key.Key] = bucket
+	}
+
+	allowed := bucket.take(now)
+	var reset, retryAfter time.Duration
+	if bucket.refillRate > 0 {
+		reset = time.Duration((bucket.capacity - bucket.tokens) / bucket.refillRate * float64(time.Second))
+		if !allowed {
+			retryAfter = time.Duration((1 - bucket.tokens) / bucket.refillRate * float64(time.Second))
+		}
+	}
+	return RateLimitStatus{
+		Allowed:    allowed,
+		Limit:      ratePerMinute,
+		Remaining:  int(bucket.tokens),
+		Reset:      reset,
+		RetryAfter: retryAfter,
+		Window:     time.Minute,
+	}
+}
+
+// ListKeys returns the configured keys with their secrets masked.
+func (kc *APIKeyController) ListKeys() []map[string]interface{} {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	var list []map[string]interface{}
+	for secret, key := range kc.keys {
+		list = append(list, map[string]interface{}{
+			"name":            key.Name,
+			"key":             maskSecret(secret),
+			"scopes":          key.Scopes,
+			"rate_per_minute": key.RatePerMinute,
+			"burst":           key.Burst,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i]["name"].(string) < list[j]["name"].(string)
+	})
+	return list
+}
+
+// maskSecret hides all but the last four characters of a key.
+func maskSecret(secret string) string {
+	if len(secret) <= 4 {
+		return "****"
+	}
+	return "****" + secret[len(secret)-4:]
+}
@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fishreports/model"
+)
+
+func TestTokenBucketTake(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	b := &tokenBucket{capacity: 2, tokens: 2, refillRate: 1, last: start}
+
+	for i, tt := range []struct {
+		after time.Duration
+		want  bool
+	}{
+		{0, true},
+		{0, true},
+		{0, false},                      // empty
+		{500 * time.Millisecond, false}, // half a token
+		{time.Second, true},             // the token is whole
+		{10 * time.Second, true},        // refilled, but only up to capacity
+		{10 * time.Second, true},
+		{10 * time.Second, false},
+	} {
+		if got := b.take(start.Add(tt.after)); got != tt.want {
+			t.Errorf("take %d at +%v = %v, want %v (tokens %.2f)", i, tt.after, got, tt.want, b.tokens)
+		}
+	}
+}
+
+func TestAllowLimitsEachKey(t *testing.T) {
+	slow := model.APIKey{Key: "slow-secret", Name: "slow", RatePerMinute: 1, Burst: 2}
+	kc := NewAPIKeyController([]model.APIKey{slow, {Key: "fast-secret", Name: "fast"}}, 600, 0)
+
+	for i := 0; i < 2; i++ {
+		status := kc.Allow(&slow)
+		if !status.Allowed || status.Remaining != 1-i || status.RetryAfter != 0 {
+			t.Errorf("request %d = %+v, want allowed with %d left", i+1, status, 1-i)
+		}
+	}
+	status := kc.Allow(&slow)
+	if status.Allowed || status.Limit != 1 || status.Window != time.Minute || status.Remaining != 0 {
+		t.Errorf("third request = %+v, want denied", status)
+	}
+	// One token a minute: the next comes in just under a minute and the
+	// bucket is full again in just under two.
+	if status.RetryAfter <= 59*time.Second || status.RetryAfter > time.Minute {
+		t.Errorf("retry after %v, want about a minute", status.RetryAfter)
+	}
+	if status.Reset <= 119*time.Second || status.Reset > 2*time.Minute {
+		t.Errorf("reset %v, want about two minutes", status.Reset)
+	}
+
+	// Other keys have their own bucket, sized by the defaults: with no
+	// default burst the burst is a minute's worth of requests.
+	fast, _ := kc.Authenticate("fast-secret")
+	status = kc.Allow(fast)
+	if !status.Allowed || status.Limit != 600 || status.Remaining != 599 {
+		t.Errorf("fast key = %+v, want allowed with 599 left of 600", status)
+	}
+}
+
+func TestAllowWithDefaultBurst(t *testing.T) {
+	key := model.APIKey{Key: "secret"}
+	kc := NewAPIKeyController([]model.APIKey{key}, 60, 3)
+	for i := 0; i < 3; i++ {
+		if status := kc.Allow(&key); !status.Allowed {
+			t.Fatalf("request %d denied: %+v", i+1, status)
+		}
+	}
+	if status := kc.Allow(&key); status.Allowed || status.Limit != 60 {
+		t.Errorf("fourth request = %+v, want denied after the burst of 3", status)
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	kc := NewAPIKeyController([]model.APIKey{
+		{Key: "admin-secret", Name: "admin", Scopes: []string{model.ScopeAdmin}},
+		{Key: "old-secret", Name: "old", Disabled: true},
+		{Name: "no secret"},
+	}, 60, 0)
+	if kc.KeyCount() != 1 {
+		t.Errorf("%d keys, want only the enabled key with a secret", kc.KeyCount())
+	}
+	if key, ok := kc.Authenticate("admin-secret"); !ok || key.Name != "admin" {
+		t.Errorf("Authenticate = %+v, %v", key, ok)
+	}
+	for _, secret := range []string{"old-secret", "", "ADMIN-SECRET"} {
+		if _, ok := kc.Authenticate(secret); ok {
+			t.Errorf("%q authenticated", secret)
+		}
+	}
+	if list := kc.ListKeys(); len(list) != 1 || list[0]["key"] != "****cret" {
+		t.Errorf("ListKeys = %v, want the masked admin key", list)
+	}
+}
+
+func TestMaskSecret(t *testing.T) {
+	for secret, want := range map[string]string{"": "****", "abcd": "****", "abcdef": "****cdef"} {
+		if got := maskSecret(secret); got != want {
+			t.Errorf("maskSecret(%q) = %q, want %q", secret, got, want)
+		}
+	}
+}
+
+func TestLoadAPIKeys(t *testing.T) {
+	dir := t.TempDir()
+	if keys, err := LoadAPIKeys(filepath.Join(dir, "missing.json")); keys != nil || err != nil {
+		t.Errorf("missing file = %v, %v; want no keys", keys, err)
+	}
+	path := filepath.Join(dir, "keys.json")
+	if err := os.WriteFile(path, []byte(`[{"key": "k1", "name": "one", "rate_per_minute": 30}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := LoadAPIKeys(path)
+	if err != nil || len(keys) != 1 || keys[0].RatePerMinute != 30 {
+		t.Errorf("LoadAPIKeys = %+v, %v", keys, err)
+	}
+	if err := os.WriteFile(path, []byte(`{"key": "k1"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadAPIKeys(path); err == nil {
+		t.Error("a key object instead of a list was accepted")
+	}
+}
@@ -0,0 +1,447 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+
+	"fishreports/model"
+)
+
+// Limits of the cohort decomposition.
+const (
+	defaultMaxCohorts = 4
+	maxCohorts        = 6
+	cohortIterations  = 500
+	cohortTolerance   = 1e-7
+	// minCohortSD keeps a cohort from collapsing onto one 1-inch length class.
+	minCohortSD = 0.5
+)
+
+// ErrInvalidCohorts is returned for unsupported cohort options.
+var ErrInvalidCohorts = errors.New("invalid cohort options")
+
+// AgeLengthKey gives, for each length class in inches, the proportion of fish
+// at each age. Lengths beyond the key use its nearest length class.
+type AgeLengthKey struct {
+	Source  string
+	Lengths map[int]map[int]float64 // length -> age -> proportion, each row summing to 1
+}
+
+// ageLengthKeyFile is the JSON form of an AgeLengthKey, with lengths and ages
+// as object keys.
+type ageLengthKeyFile struct {
+	Source  string                        `json:"source"`
+	Lengths map[string]map[string]float64 `json:"lengths"`
+}
+
+// LoadAgeLengthKeys reads the age-length keys by species code. A missing file
+// returns no keys; each length's proportions are normalized to sum to 1.
+func LoadAgeLengthKeys(path string) (map[string]AgeLengthKey, error) {
+	if path == "" {
+		return nil, nil
+	}
+	file, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read age-length keys: %w", err)
+	}
+	var raw map[string]ageLengthKeyFile
+	if err := json.Unmarshal(file, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse age-length keys: %w", err)
+	}
+
+	keys := make(map[string]AgeLengthKey, len(raw))
+	for code, entry := range raw {
+		key := AgeLengthKey{Source: entry.Source, Lengths: make(map[int]map[int]float64, len(entry.Lengths))}
+		for lengthStr, ages := range entry.Lengths {
+			length, err := strconv.Atoi(lengthStr)
+			if err != nil {
+				return nil, fmt.Errorf("age-length key %s: length %q is not a whole number of inches", code, lengthStr)
+			}
+			total := 0.0
+			row := make(map[int]float64, len(ages))
+			for ageStr, proportion := range ages {
+				age, err := strconv.Atoi(ageStr)
+				if err != nil || proportion < 0 {
+					return nil, fmt.Errorf("age-length key %s: invalid age %q at %d inches", code, ageStr, length)
+				}
+				row[age] = proportion
+				total += proportion
+			}
+			if total <= 0 {
+				continue
+			}
+			for age := range row {
+				row[age] /= total
+			}
+			key.Lengths[length] = row
+		}
+		if len(key.Lengths) > 0 {
+			keys[code] = key
+		}
+	}
+	log.Printf("✅ Loaded age-length keys for %d species from %s", len(keys), path)
+	return keys, nil
+}
+
+// ageProportions returns the key's row for a length, using the nearest
+// length class in the key.
+func (k AgeLengthKey) ageProportions(length int) map[int]float64 {
+	if row, exists := k.Lengths[length]; exists {
+		return row
+	}
+	nearest, distance := 0, math.MaxInt
+	for keyLength := range k.Lengths {
+		d := keyLength - length
+		if d < 0 {
+			d = -d
+		}
+		if d < distance || (d == distance && keyLength < nearest) {
+			nearest, distance = keyLength, d
+		}
+	}
+	return k.Lengths[nearest]
+}
+
+// AgeProbability is the estimated share of fish at an age.
+type AgeProbability struct {
+	Age         int     `json:"age"`
+	Probability float64 `json:"probability"`
+}
+
+// Cohort is one component of a length-frequency mixture: a group of fish,
+// usually one year class, whose lengths are normally distributed.
+type Cohort struct {
+	Cohort        int              `json:"cohort"`         // 1 is the shortest
+	MeanLength    float64          `json:"mean_length"`    // the cohort's mode, in inches
+	SD            float64          `json:"sd"`             // in inches
+	Proportion    float64          `json:"proportion"`     // share of the fish measured
+	EstimatedFish float64          `json:"estimated_fish"` // proportion times the fish measured
+	LikelyAge     *int             `json:"likely_age"`     // most probable age from the age-length key
+	Ages          []AgeProbability `json:"ages,omitempty"` // ages with at least 5% probability
+}
+
+// mixtureFit is a fitted Gaussian mixture.
+type mixtureFit struct {
+	weights, means, sds []float64
+	logLikelihood       float64
+	bic                 float64
+	iterations          int
+}
+
+// fitLengthMixture fits a k-component Gaussian mixture to fish counts keyed
+// by length class with EM. Each fish is placed at its class midpoint.
+// Components start at the weighted quantiles of the lengths, so fits are
+// deterministic.
+func fitLengthMixture(counts map[int]int, k int) mixtureFit {
+	lengths := make([]int, 0, len(counts))
+	total := 0
+	for length, quantity := range counts {
+		if quantity > 0 {
+			lengths = append(lengths, length)
+			total += quantity
+		}
+	}
+	sort.Ints(lengths)
+	x := make([]float64, len(lengths))
+	n := make([]float64, len(lengths))
+	mean := 0.0
+	for i, length := range lengths {
+		x[i] = float64(length) + 0.5
+		n[i] = float64(counts[length])
+		mean += x[i] * n[i]
+	}
+	mean /= float64(total)
+	variance := 0.0
+	for i := range x {
+		variance += n[i] * (x[i] - mean) * (x[i] - mean)
+	}
+	overallSD := math.Max(math.Sqrt(variance/float64(total)), minCohortSD)
+
+	fit := mixtureFit{
+		weights: make([]float64, k),
+		means:   make([]float64, k),
+		sds:     make([]float64, k),
+	}
+	for j := 0; j < k; j++ {
+		fit.weights[j] = 1 / float64(k)
+		fit.means[j] = weightedQuantile(x, n, (float64(j)+0.5)/float64(k))
+		fit.sds[j] = math.Max(overallSD/float64(k), minCohortSD)
+	}
+
+	resp := make([][]float64, len(x))
+	for i := range resp {
+		resp[i] = make([]float64, k)
+	}
+	previous := math.Inf(-1)
+	for fit.iterations = 1; fit.iterations <= cohortIterations; fit.iterations++ {
+		// E step: each length class's responsibilities.
+		logLikelihood := 0.0
+		for i := range x {
+			sum := 0.0
+			for j := 0; j < k; j++ {
+				resp[i][j] = fit.weights[j] * normalPDF(x[i], fit.means[j], fit.sds[j])
+				sum += resp[i][j]
+			}
+			if sum <= 0 {
+				// Far from every component: give it to the nearest.
+				nearest := 0
+				for j := 1; j < k; j++ {
+					if math.Abs(x[i]-fit.means[j]) < math.Abs(x[i]-fit.means[nearest]) {
+						nearest = j
+					}
+				}
+				resp[i][nearest], sum = 1, 1
+			} else {
+				for j := 0; j < k; j++ {
+					resp[i][j] /= sum
+				}
+			}
+			logLikelihood += n[i] * math.Log(math.Max(sum, math.SmallestNonzeroFloat64))
+		}
+		fit.logLikelihood = logLikelihood
+
+		// M step.
+		for j := 0; j < k; j++ {
+			weight, sumX := 0.0, 0.0
+			for i := range x {
+				weight += n[i] * resp[i][j]
+				sumX += n[i] * resp[i][j] * x[i]
+			}
+			if weight <= 0 {
+				fit.weights[j] = 0
+				continue
+			}
+			fit.means[j] = sumX / weight
+			sumSq := 0.0
+			for i := range x {
+				sumSq += n[i] * resp[i][j] * (x[i] - fit.means[j]) * (x[i] - fit.means[j])
+			}
+			fit.sds[j] = math.Max(math.Sqrt(sumSq/weight), minCohortSD)
+			fit.weights[j] = weight / float64(total)
+		}
+
+		if math.Abs(logLikelihood-previous) < cohortTolerance*math.Abs(logLikelihood) {
+			break
+		}
+		previous = logLikelihood
+	}
+	if fit.iterations > cohortIterations {
+		fit.iterations = cohortIterations
+	}
+	parameters := float64(3*k - 1)
+	fit.bic = -2*fit.logLikelihood + parameters*math.Log(float64(total))
+	return fit
+}
+
+// findSurvey returns the survey with an ID and its lake, or a nil survey
+// when no lake has it.
+func (c *FishSurveyController) findSurvey(ctx context.Context, surveyID string) (model.FishData, *model.Survey, error) {
+	fishDataByCounty, _ := c.Model.Snapshot()
+	for _, fishDataList := range fishDataByCounty {
+		for _, data := range fishDataList {
+			if err := ctx.Err(); err != nil {
+				return model.FishData{}, nil, err
+			}
+			for i := range data.Result.Surveys {
+				if data.Result.Surveys[i].SurveyID == surveyID {
+					return data, &data.Result.Surveys[i], nil
+				}
+			}
+		}
+	}
+	return model.FishData{}, nil, nil
+}
+
+// normalPDF is the normal density at x.
+func normalPDF(x, mean, sd float64) float64 {
+	z := (x - mean) / sd
+	return math.Exp(-0.5*z*z) / (sd * math.Sqrt(2*math.Pi))
+}
+
+// weightedQuantile returns the value below which the given fraction of the
+// weight falls; x must be sorted.
+func weightedQuantile(x, weights []float64, fraction float64) float64 {
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+	seen := 0.0
+	for i, w := range weights {
+		seen += w
+		if seen >= fraction*total {
+			return x[i]
+		}
+	}
+	return x[len(x)-1]
+}
+
+// GetSurveyCohortsContext decomposes the length frequencies of one species
+// (common name, code or ID) in one survey into cohorts. With cohorts > 0
+// exactly that many are fitted; otherwise fits of 1 to maxCohorts components
+// are compared and the one with the lowest BIC is kept. When an age-length
+// key exists for the species, each cohort's likely ages and the age
+// composition of the whole sample are estimated from it. It returns nil when
+// the survey doesn't exist or measured no fish of the species.
+func (c *FishSurveyController) GetSurveyCohortsContext(ctx context.Context, surveyID, speciesName string, cohorts, maxCohortCount int) (map[string]interface{}, error) {
+	if cohorts < 0 || cohorts > maxCohorts {
+		return nil, fmt.Errorf("%w: cohorts must be between 1 and %d", ErrInvalidCohorts, maxCohorts)
+	}
+	if maxCohortCount == 0 {
+		maxCohortCount = defaultMaxCohorts
+	}
+	if maxCohortCount < 1 || maxCohortCount > maxCohorts {
+		return nil, fmt.Errorf("%w: max_cohorts must be between 1 and %d", ErrInvalidCohorts, maxCohorts)
+	}
+	code := c.ResolveSpecies(speciesName)
+	if code == "" {
+		return nil, nil
+	}
+
+	lake, survey, err := c.findSurvey(ctx, surveyID)
+	if err != nil || survey == nil {
+		return nil, err
+	}
+	counts, _ := lengthCountsOf(*survey, code)
+	measured := sumCounts(counts)
+	if measured == 0 {
+		return nil, nil
+	}
+
+	// Fit, or pick the component count by BIC. More components than length
+	// classes can't be told apart.
+	classes := len(counts)
+	selection := "fixed"
+	var fit mixtureFit
+	if cohorts > 0 {
+		fit = fitLengthMixture(counts, int(math.Min(float64(cohorts), float64(classes))))
+	} else {
+		selection = "bic"
+		for k := 1; k <= maxCohortCount && k <= classes; k++ {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			candidate := fitLengthMixture(counts, k)
+			if k == 1 || candidate.bic < fit.bic {
+				fit = candidate
+			}
+		}
+	}
+
+	// Drop components EM emptied and order the rest by length.
+	order := make([]int, 0, len(fit.means))
+	for j := range fit.means {
+		if fit.weights[j] > 1e-9 {
+			order = append(order, j)
+		}
+	}
+	sort.Slice(order, func(a, b int) bool {
+		return fit.means[order[a]] < fit.means[order[b]]
+	})
+
+	key, hasKey := c.AgeLengthKeys[code]
+	results := make([]Cohort, 0, len(order))
+	for rank, j := range order {
+		cohort := Cohort{
+			Cohort:        rank + 1,
+			MeanLength:    roundTo(fit.means[j], 2),
+			SD:            roundTo(fit.sds[j], 2),
+			Proportion:    roundTo(fit.weights[j], 3),
+			EstimatedFish: roundTo(fit.weights[j]*float64(measured), 1),
+		}
+		if hasKey {
+			// Weigh each length class's ages by the fish the cohort holds there.
+			ages := make(map[int]float64)
+			for length, quantity := range counts {
+				x := float64(length) + 0.5
+				share := 0.0
+				sum := 0.0
+				for m := range fit.means {
+					density := fit.weights[m] * normalPDF(x, fit.means[m], fit.sds[m])
+					sum += density
+					if m == j {
+						share = density
+					}
+				}
+				if sum <= 0 {
+					continue
+				}
+				for age, proportion := range key.ageProportions(length) {
+					ages[age] += float64(quantity) * share / sum * proportion
+				}
+			}
+			cohort.Ages = ageProbabilities(ages, 0.05)
+			if len(cohort.Ages) > 0 {
+				likely := cohort.Ages[0].Age
+				cohort.LikelyAge = &likely
+			}
+		}
+		results = append(results, cohort)
+	}
+
+	species := c.Model.Species()[code]
+	response := map[string]interface{}{
+		"survey_id":      survey.SurveyID,
+		"survey_date":    survey.SurveyDate,
+		"survey_type":    survey.SurveyType,
+		"dow_number":     lake.Result.DOWNumber,
+		"lake_name":      lake.Result.LakeName,
+		"species":        species.CommonName,
+		"species_id":     species.ID,
+		"fish_measured":  measured,
+		"length_classes": classes,
+		"unit":           "in",
+		"selection":      selection,
+		"components":     len(results),
+		"log_likelihood": roundTo(fit.logLikelihood, 3),
+		"bic":            roundTo(fit.bic, 3),
+		"iterations":     fit.iterations,
+		"cohorts":        results,
+		"age_length_key": nil,
+	}
+	if hasKey {
+		composition := make(map[int]float64)
+		for length, quantity := range counts {
+			for age, proportion := range key.ageProportions(length) {
+				composition[age] += float64(quantity) * proportion
+			}
+		}
+		response["age_length_key"] = key.Source
+		response["age_composition"] = ageProbabilities(composition, 0)
+	}
+	return response, nil
+}
+
+// ageProbabilities normalizes fish counts by age into probabilities, keeping
+// ages at or above minProbability, most likely first.
+func ageProbabilities(ages map[int]float64, minProbability float64) []AgeProbability {
+	total := 0.0
+	for _, fish := range ages {
+		total += fish
+	}
+	if total <= 0 {
+		return nil
+	}
+	var probabilities []AgeProbability
+	for age, fish := range ages {
+		if p := fish / total; p >= minProbability && p > 0 {
+			probabilities = append(probabilities, AgeProbability{Age: age, Probability: roundTo(p, 3)})
+		}
+	}
+	sort.Slice(probabilities, func(i, j int) bool {
+		if probabilities[i].Probability != probabilities[j].Probability {
+			return probabilities[i].Probability > probabilities[j].Probability
+		}
+		return probabilities[i].Age < probabilities[j].Age
+	})
+	return probabilities
+}
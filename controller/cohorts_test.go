@@ -0,0 +1,181 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"fishreports/model"
+)
+
+// twoCohorts are the walleye lengths of a survey with a young-of-year class
+// around 8 inches and an older class around 18.
+var twoCohorts = map[int]int{
+	6: 5, 7: 20, 8: 40, 9: 20, 10: 5,
+	16: 3, 17: 12, 18: 25, 19: 12, 20: 3,
+}
+
+func TestFitLengthMixtureSeparatesCohorts(t *testing.T) {
+	fit := fitLengthMixture(twoCohorts, 2)
+	shorter, longer := 0, 1
+	if fit.means[0] > fit.means[1] {
+		shorter, longer = 1, 0
+	}
+	if math.Abs(fit.means[shorter]-8.5) > 0.05 || math.Abs(fit.means[longer]-18.5) > 0.05 {
+		t.Errorf("means = %v, want about 8.5 and 18.5", fit.means)
+	}
+	if math.Abs(fit.weights[shorter]-90.0/145) > 0.005 || math.Abs(fit.weights[longer]-55.0/145) > 0.005 {
+		t.Errorf("weights = %v, want about %.3f and %.3f", fit.weights, 90.0/145, 55.0/145)
+	}
+	if fit.iterations < 1 || fit.iterations > cohortIterations {
+		t.Errorf("iterations = %d", fit.iterations)
+	}
+	if again := fitLengthMixture(twoCohorts, 2); !reflect.DeepEqual(again, fit) {
+		t.Errorf("refit = %+v, want the same fit %+v", again, fit)
+	}
+}
+
+func TestFitLengthMixtureBICPrefersTheTrueCohorts(t *testing.T) {
+	one := fitLengthMixture(twoCohorts, 1)
+	two := fitLengthMixture(twoCohorts, 2)
+	if two.bic >= one.bic {
+		t.Errorf("BIC with 2 components %.2f, want below %.2f with 1", two.bic, one.bic)
+	}
+	// One component on one cohort: the fit is the sample itself.
+	single := fitLengthMixture(map[int]int{8: 10, 9: 10}, 1)
+	if single.means[0] != 9 || single.sds[0] != 0.5 || single.weights[0] != 1 {
+		t.Errorf("single fit = %+v, want mean 9, sd 0.5, weight 1", single)
+	}
+}
+
+func TestFitLengthMixtureKeepsTheMinimumSD(t *testing.T) {
+	fit := fitLengthMixture(map[int]int{12: 30}, 1)
+	if fit.means[0] != 12.5 || fit.sds[0] != minCohortSD {
+		t.Errorf("fit = %+v, want mean 12.5 and sd %v", fit, minCohortSD)
+	}
+	if math.IsNaN(fit.bic) || math.IsInf(fit.bic, 0) {
+		t.Errorf("bic = %v", fit.bic)
+	}
+}
+
+func TestLoadAgeLengthKeys(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "keys.json")
+	if err := os.WriteFile(path, []byte(`{
+		"WAE": {"source": "test key", "lengths": {"8": {"0": 3, "1": 1}, "18": {"3": 1}, "30": {}}},
+		"NOP": {"lengths": {"20": {"2": 0}}}
+	}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := LoadAgeLengthKeys(path)
+	if err != nil {
+		t.Fatalf("LoadAgeLengthKeys: %v", err)
+	}
+	want := map[string]AgeLengthKey{
+		"WAE": {Source: "test key", Lengths: map[int]map[int]float64{8: {0: 0.75, 1: 0.25}, 18: {3: 1}}},
+	}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys = %+v, want %+v", keys, want)
+	}
+
+	if keys, err := LoadAgeLengthKeys(filepath.Join(dir, "missing.json")); err != nil || keys != nil {
+		t.Errorf("missing file = %v, %v; want no keys", keys, err)
+	}
+	for _, bad := range []string{
+		`{"WAE": {"lengths": {"8.5": {"1": 1}}}}`,
+		`{"WAE": {"lengths": {"8": {"one": 1}}}}`,
+		`{"WAE": {"lengths": {"8": {"1": -1}}}}`,
+	} {
+		if err := os.WriteFile(path, []byte(bad), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadAgeLengthKeys(path); err == nil {
+			t.Errorf("%s: want an error", bad)
+		}
+	}
+}
+
+func TestAgeProportionsUsesTheNearestLengthClass(t *testing.T) {
+	key := AgeLengthKey{Lengths: map[int]map[int]float64{8: {1: 1}, 12: {2: 1}, 18: {3: 1}}}
+	tests := []struct {
+		length int
+		age    int
+	}{
+		{8, 1}, {4, 1}, {10, 1}, {11, 2}, {15, 2}, {16, 3}, {40, 3},
+	}
+	for _, tt := range tests {
+		if row := key.ageProportions(tt.length); row[tt.age] != 1 {
+			t.Errorf("ageProportions(%d) = %v, want age %d", tt.length, row, tt.age)
+		}
+	}
+}
+
+func newCohortController() *FishSurveyController {
+	var counts []model.FishCount
+	for length, quantity := range twoCohorts {
+		counts = append(counts, model.FishCount{Length: length, Quantity: quantity})
+	}
+	lake := fixtureLake(1000100, "Aitkin", "Big Lake",
+		fixtureSurvey("s1", "2020-06-01", map[string]int{"WAE": 145}, map[string][]model.FishCount{"WAE": counts}))
+	c := NewFishSurveyController(&model.FishSurveyModel{
+		FishDataByCounty: map[string][]model.FishData{"Aitkin": {lake}},
+		SpeciesMap:       fixtureSpecies(),
+	})
+	c.AgeLengthKeys = map[string]AgeLengthKey{
+		"WAE": {Source: "test key", Lengths: map[int]map[int]float64{8: {0: 0.9, 1: 0.1}, 18: {3: 0.8, 4: 0.2}}},
+	}
+	return c
+}
+
+func TestGetSurveyCohorts(t *testing.T) {
+	c := newCohortController()
+	result, err := c.GetSurveyCohortsContext(context.Background(), "s1", "walleye", 0, 0)
+	if err != nil {
+		t.Fatalf("GetSurveyCohortsContext: %v", err)
+	}
+	if result["selection"] != "bic" || result["components"] != 2 || result["fish_measured"] != 145 {
+		t.Errorf("selection %v, components %v, measured %v; want bic, 2, 145",
+			result["selection"], result["components"], result["fish_measured"])
+	}
+	cohorts := result["cohorts"].([]Cohort)
+	if len(cohorts) != 2 || cohorts[0].MeanLength > cohorts[1].MeanLength {
+		t.Fatalf("cohorts = %+v, want 2 ordered by length", cohorts)
+	}
+	for i, age := range []int{0, 3} {
+		if cohorts[i].LikelyAge == nil || *cohorts[i].LikelyAge != age {
+			t.Errorf("cohort %d likely age = %v, want %d", i+1, cohorts[i].LikelyAge, age)
+		}
+	}
+	if result["age_length_key"] != "test key" {
+		t.Errorf("age_length_key = %v", result["age_length_key"])
+	}
+
+	fixed, err := c.GetSurveyCohortsContext(context.Background(), "s1", "WAE", 3, 0)
+	if err != nil || fixed["selection"] != "fixed" {
+		t.Errorf("fixed fit = %v, %v; want selection fixed", fixed, err)
+	}
+
+	c.AgeLengthKeys = nil
+	unaged, _ := c.GetSurveyCohortsContext(context.Background(), "s1", "WAE", 0, 0)
+	if unaged["age_length_key"] != nil || unaged["cohorts"].([]Cohort)[0].LikelyAge != nil {
+		t.Errorf("without a key: %v", unaged)
+	}
+}
+
+func TestGetSurveyCohortsErrors(t *testing.T) {
+	c := newCohortController()
+	for _, counts := range [][2]int{{-1, 0}, {maxCohorts + 1, 0}, {0, maxCohorts + 1}, {0, -1}} {
+		if _, err := c.GetSurveyCohortsContext(context.Background(), "s1", "WAE", counts[0], counts[1]); !errors.Is(err, ErrInvalidCohorts) {
+			t.Errorf("cohorts %d, max %d: err = %v, want ErrInvalidCohorts", counts[0], counts[1], err)
+		}
+	}
+	for _, tt := range []struct{ survey, species string }{{"missing", "WAE"}, {"s1", "NOP"}, {"s1", "unknown"}} {
+		if result, err := c.GetSurveyCohortsContext(context.Background(), tt.survey, tt.species, 0, 0); result != nil || err != nil {
+			t.Errorf("%s %s = %v, %v; want nil", tt.survey, tt.species, result, err)
+		}
+	}
+}
@@ -0,0 +1,396 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"fishreports/model"
+)
+
+// Limits of the community analytics.
+const (
+	defaultCooccurrenceSpecies = 20
+	maxCooccurrenceSpecies     = 50
+	defaultDiversityLimit      = 100
+	maxDiversityLimit          = 1000
+)
+
+// Co-occurrence sampling units.
+const (
+	CooccurrenceBySurvey = "survey"
+	CooccurrenceByLake   = "lake"
+)
+
+// ErrInvalidCommunity is returned for unsupported community analytics options.
+var ErrInvalidCommunity = errors.New("invalid community options")
+
+// CommunityFilters selects the surveys the community analytics use.
+type CommunityFilters struct {
+	Counties []string // county IDs
+	MinYear  int
+	MaxYear  int
+	DOW      int
+}
+
+// communitySurvey is a survey that passed the filters.
+type communitySurvey struct {
+	lake   *model.FishData
+	survey model.Survey
+}
+
+// filteredSurveys returns the surveys matching the filters.
+func (c *FishSurveyController) filteredSurveys(ctx context.Context, filters CommunityFilters) ([]communitySurvey, error) {
+	countySet := make(map[string]bool)
+	for _, id := range filters.Counties {
+		countySet[strings.ToLower(id)] = true
+	}
+	var surveys []communitySurvey
+	fishDataByCounty, _ := c.Model.Snapshot()
+	for _, fishDataList := range fishDataByCounty {
+		for i := range fishDataList {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			data := &fishDataList[i]
+			if filters.DOW != 0 && data.Result.DOWNumber != filters.DOW {
+				continue
+			}
+			if len(countySet) > 0 && !anyInSet(c.Reconciler.lakeCountyIDs(*data), countySet) {
+				continue
+			}
+			for _, survey := range data.Result.Surveys {
+				year := surveyYear(survey.SurveyDate)
+				if filters.MinYear > 0 && year < filters.MinYear {
+					continue
+				}
+				if filters.MaxYear > 0 && year > filters.MaxYear {
+					continue
+				}
+				surveys = append(surveys, communitySurvey{lake: data, survey: survey})
+			}
+		}
+	}
+	return surveys, nil
+}
+
+// surveyAbundance returns the species a survey found, with each one's
+// abundance: its total catch when the catch summary has one, otherwise the
+// fish measured. Species with no fish caught or measured, such as those with
+// null or empty length data, are left out.
+func surveyAbundance(survey model.Survey) map[string]int {
+	abundance := totalCatches(survey)
+	for code, catch := range abundance {
+		if catch <= 0 {
+			delete(abundance, code)
+		}
+	}
+	for code, lengthData := range survey.Lengths {
+		if _, caught := abundance[code]; caught || lengthData == nil {
+			continue
+		}
+		measured := make(map[int]int)
+		histogramCounts(measured, lengthData)
+		if fish := sumCounts(measured); fish > 0 {
+			abundance[code] = fish
+		}
+	}
+	return abundance
+}
+
+// CooccurrenceContext counts how often species are found together, by survey
+// (default) or by lake, in the surveys matching the filters. The species are
+// the given ones (ID, code or common name), or else the top most often found.
+// For each pair it reports the samples holding both and their Jaccard
+// similarity: the samples with both over the samples with either.
+func (c *FishSurveyController) CooccurrenceContext(ctx context.Context, filters CommunityFilters, by string, speciesNames []string, top int) (map[string]interface{}, error) {
+	if by == "" {
+		by = CooccurrenceBySurvey
+	}
+	if by != CooccurrenceBySurvey && by != CooccurrenceByLake {
+		return nil, fmt.Errorf("%w: by must be survey or lake", ErrInvalidCommunity)
+	}
+	if top == 0 {
+		top = defaultCooccurrenceSpecies
+	}
+	if top < 2 || top > maxCooccurrenceSpecies {
+		return nil, fmt.Errorf("%w: top must be between 2 and %d", ErrInvalidCommunity, maxCooccurrenceSpecies)
+	}
+	if len(speciesNames) > maxCooccurrenceSpecies {
+		return nil, fmt.Errorf("%w: at most %d species can be compared", ErrInvalidCommunity, maxCooccurrenceSpecies)
+	}
+	var chosen []string
+	for _, name := range speciesNames {
+		code := c.ResolveSpecies(name)
+		if code == "" {
+			return nil, fmt.Errorf("%w: unknown species %q", ErrInvalidCommunity, name)
+		}
+		if !containsString(chosen, code) {
+			chosen = append(chosen, code)
+		}
+	}
+
+	surveys, err := c.filteredSurveys(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each sample is the set of species one survey, or one lake, found.
+	var samples []map[string]bool
+	lakeSamples := make(map[int]map[string]bool)
+	for _, s := range surveys {
+		found := make(map[string]bool)
+		for code := range surveyAbundance(s.survey) {
+			found[code] = true
+		}
+		if by == CooccurrenceByLake {
+			dow := s.lake.Result.DOWNumber
+			if lakeSamples[dow] == nil {
+				lakeSamples[dow] = make(map[string]bool)
+			}
+			for code := range found {
+				lakeSamples[dow][code] = true
+			}
+			continue
+		}
+		samples = append(samples, found)
+	}
+	for _, found := range lakeSamples {
+		samples = append(samples, found)
+	}
+
+	occurrences := make(map[string]int)
+	for _, found := range samples {
+		for code := range found {
+			occurrences[code]++
+		}
+	}
+	speciesMap := c.Model.Species()
+	if chosen == nil {
+		// The most often found species in the catalog.
+		for code := range occurrences {
+			if _, known := speciesMap[code]; known {
+				chosen = append(chosen, code)
+			}
+		}
+		sort.Slice(chosen, func(i, j int) bool {
+			if occurrences[chosen[i]] != occurrences[chosen[j]] {
+				return occurrences[chosen[i]] > occurrences[chosen[j]]
+			}
+			return chosen[i] < chosen[j]
+		})
+		if len(chosen) > top {
+			chosen = chosen[:top]
+		}
+	}
+
+	together := make([][]int, len(chosen))
+	for i := range together {
+		together[i] = make([]int, len(chosen))
+	}
+	for _, found := range samples {
+		for i, a := range chosen {
+			if !found[a] {
+				continue
+			}
+			for j, b := range chosen {
+				if found[b] {
+					together[i][j]++
+				}
+			}
+		}
+	}
+
+	species := make([]map[string]interface{}, 0, len(chosen))
+	for _, code := range chosen {
+		species = append(species, map[string]interface{}{
+			"code":        code,
+			"species":     speciesMap[code].CommonName,
+			"species_id":  speciesMap[code].ID,
+			"occurrences": occurrences[code],
+			"frequency":   roundTo(percentOf(occurrences[code], len(samples)), 1),
+		})
+	}
+	jaccard := make([][]float64, len(chosen))
+	pairs := []map[string]interface{}{}
+	for i, a := range chosen {
+		jaccard[i] = make([]float64, len(chosen))
+		for j, b := range chosen {
+			either := occurrences[a] + occurrences[b] - together[i][j]
+			if either > 0 {
+				jaccard[i][j] = roundTo(float64(together[i][j])/float64(either), 3)
+			}
+			if j > i {
+				pairs = append(pairs, map[string]interface{}{
+					"species":  []string{a, b},
+					"together": together[i][j],
+					"jaccard":  jaccard[i][j],
+				})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i]["jaccard"].(float64) > pairs[j]["jaccard"].(float64)
+	})
+
+	return map[string]interface{}{
+		"by":       by,
+		"samples":  len(samples),
+		"species":  species,
+		"together": together,
+		"jaccard":  jaccard,
+		"pairs":    pairs,
+	}, nil
+}
+
+// SurveyDiversity holds the community diversity indices of one survey.
+type SurveyDiversity struct {
+	DOWNumber      int     `json:"dow_number"`
+	LakeName       string  `json:"lake_name"`
+	CountyName     string  `json:"county_name"`
+	State          string  `json:"state"`
+	SurveyID       string  `json:"survey_id"`
+	SurveyDate     string  `json:"survey_date"`
+	SurveyType     string  `json:"survey_type"`
+	Richness       int     `json:"richness"`        // species found
+	TotalFish      int     `json:"total_fish"`      // abundance summed over the species
+	Shannon        float64 `json:"shannon"`         // H' = -sum(p ln p)
+	Simpson        float64 `json:"simpson"`         // 1 - sum(p^2), the chance two fish differ in species
+	InverseSimpson float64 `json:"inverse_simpson"` // 1 / sum(p^2), the effective number of species
+	Evenness       float64 `json:"evenness"`        // Pielou's J = H' / ln(richness)
+}
+
+// diversityOf computes the diversity indices of species abundances. Species
+// without fish don't count toward richness.
+func diversityOf(abundance map[string]int) SurveyDiversity {
+	var d SurveyDiversity
+	for _, fish := range abundance {
+		if fish > 0 {
+			d.Richness++
+			d.TotalFish += fish
+		}
+	}
+	if d.TotalFish == 0 {
+		return d
+	}
+	shannon, dominance := 0.0, 0.0
+	for _, fish := range abundance {
+		if fish <= 0 {
+			continue
+		}
+		p := float64(fish) / float64(d.TotalFish)
+		shannon -= p * math.Log(p)
+		dominance += p * p
+	}
+	d.Shannon = roundTo(shannon, 4)
+	d.Simpson = roundTo(1-dominance, 4)
+	d.InverseSimpson = roundTo(1/dominance, 4)
+	if d.Richness > 1 {
+		d.Evenness = roundTo(shannon/math.Log(float64(d.Richness)), 4)
+	}
+	return d
+}
+
+// DiversityContext computes the richness, Shannon, Simpson and evenness
+// indices of every survey matching the filters from the species it found and
+// their abundance, sorted by sortBy (survey_date, richness, shannon or
+// simpson) and paginated. The summary averages every matching survey.
+func (c *FishSurveyController) DiversityContext(ctx context.Context, filters CommunityFilters, sortBy, order string, limit, page int) (map[string]interface{}, error) {
+	if sortBy == "" {
+		sortBy = "survey_date"
+	}
+	if sortBy != "survey_date" && sortBy != "richness" && sortBy != "shannon" && sortBy != "simpson" {
+		return nil, fmt.Errorf("%w: sort_by must be survey_date, richness, shannon or simpson", ErrInvalidCommunity)
+	}
+	if order != "" && order != "asc" && order != "desc" {
+		return nil, fmt.Errorf("%w: order must be asc or desc", ErrInvalidCommunity)
+	}
+	if limit <= 0 {
+		limit = defaultDiversityLimit
+	}
+	if limit > maxDiversityLimit {
+		limit = maxDiversityLimit
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	surveys, err := c.filteredSurveys(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]SurveyDiversity, 0, len(surveys))
+	var sumRichness, sumShannon, sumSimpson float64
+	for _, s := range surveys {
+		d := diversityOf(surveyAbundance(s.survey))
+		d.DOWNumber = s.lake.Result.DOWNumber
+		d.LakeName = s.lake.Result.LakeName
+		d.CountyName = s.lake.Result.CountyName
+		d.State = model.LakeState(*s.lake)
+		d.SurveyID = s.survey.SurveyID
+		d.SurveyDate = s.survey.SurveyDate
+		d.SurveyType = s.survey.SurveyType
+		rows = append(rows, d)
+		sumRichness += float64(d.Richness)
+		sumShannon += d.Shannon
+		sumSimpson += d.Simpson
+	}
+
+	key := func(d SurveyDiversity) float64 {
+		switch sortBy {
+		case "richness":
+			return float64(d.Richness)
+		case "shannon":
+			return d.Shannon
+		default:
+			return d.Simpson
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if order == "asc" {
+			a, b = b, a
+		}
+		if sortBy == "survey_date" {
+			if a.SurveyDate != b.SurveyDate {
+				return a.SurveyDate > b.SurveyDate
+			}
+		} else if key(a) != key(b) {
+			return key(a) > key(b)
+		}
+		return rows[i].DOWNumber < rows[j].DOWNumber
+	})
+
+	summary := map[string]interface{}{
+		"surveys":       len(rows),
+		"mean_richness": 0.0,
+		"mean_shannon":  0.0,
+		"mean_simpson":  0.0,
+	}
+	if len(rows) > 0 {
+		n := float64(len(rows))
+		summary["mean_richness"] = roundTo(sumRichness/n, 2)
+		summary["mean_shannon"] = roundTo(sumShannon/n, 4)
+		summary["mean_simpson"] = roundTo(sumSimpson/n, 4)
+	}
+
+	// Checked before multiplying so huge pages can't overflow.
+	start := len(rows)
+	if page-1 <= len(rows)/limit {
+		start = min((page-1)*limit, len(rows))
+	}
+	end := start + limit
+	if end > len(rows) {
+		end = len(rows)
+	}
+	return map[string]interface{}{
+		"data":    rows[start:end],
+		"summary": summary,
+		"limit":   limit,
+		"page":    page,
+		"total":   len(rows),
+	}, nil
+}
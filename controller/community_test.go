@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"fishreports/model"
+)
+
+func TestSurveyAbundanceDropsSpeciesWithoutFish(t *testing.T) {
+	survey := fixtureSurvey("s1", "2020-06-01",
+		map[string]int{"WAE": 10, "NOP": 0, "YEP": 0},
+		map[string][]model.FishCount{
+			"NOP": {{Length: 20, Quantity: 2}},
+			"YEP": {},
+			"BLG": nil,
+			"PMK": {{Length: 6, Quantity: 0}},
+			"LMB": {{Length: 12, Quantity: 3}},
+		})
+	abundance := surveyAbundance(survey)
+	want := map[string]int{"WAE": 10, "NOP": 2, "LMB": 3}
+	if len(abundance) != len(want) {
+		t.Fatalf("abundance = %v, want %v", abundance, want)
+	}
+	for code, fish := range want {
+		if abundance[code] != fish {
+			t.Errorf("%s abundance = %d, want %d", code, abundance[code], fish)
+		}
+	}
+}
+
+func TestDiversityOf(t *testing.T) {
+	tests := []struct {
+		name      string
+		abundance map[string]int
+		want      SurveyDiversity
+	}{
+		{"empty", map[string]int{}, SurveyDiversity{}},
+		{"one species", map[string]int{"WAE": 5}, SurveyDiversity{Richness: 1, TotalFish: 5, InverseSimpson: 1}},
+		{"even pair", map[string]int{"WAE": 5, "NOP": 5}, SurveyDiversity{
+			Richness: 2, TotalFish: 10, Shannon: roundTo(math.Ln2, 4), Simpson: 0.5, InverseSimpson: 2, Evenness: 1,
+		}},
+		{"zero abundance ignored", map[string]int{"WAE": 5, "NOP": 5, "YEP": 0}, SurveyDiversity{
+			Richness: 2, TotalFish: 10, Shannon: roundTo(math.Ln2, 4), Simpson: 0.5, InverseSimpson: 2, Evenness: 1,
+		}},
+		{"uneven", map[string]int{"WAE": 3, "NOP": 1}, SurveyDiversity{
+			Richness: 2, TotalFish: 4,
+			Shannon:        roundTo(-(0.75*math.Log(0.75) + 0.25*math.Log(0.25)), 4),
+			Simpson:        0.375,
+			InverseSimpson: roundTo(1/0.625, 4),
+			Evenness:       roundTo(-(0.75*math.Log(0.75)+0.25*math.Log(0.25))/math.Ln2, 4),
+		}},
+	}
+	for _, tt := range tests {
+		got := diversityOf(tt.abundance)
+		if got.Richness != tt.want.Richness || got.TotalFish != tt.want.TotalFish ||
+			got.Shannon != tt.want.Shannon || got.Simpson != tt.want.Simpson ||
+			got.InverseSimpson != tt.want.InverseSimpson || got.Evenness != tt.want.Evenness {
+			t.Errorf("%s: diversityOf = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDiversityPages(t *testing.T) {
+	c := NewFishSurveyController(newFixtureModel(t))
+	c.Reconciler = newFixtureReconciler()
+	for _, tt := range []struct {
+		page, rows int
+	}{
+		{1, 3},
+		{2, 1},
+		{3, 0},
+		{math.MaxInt64/100 + 2, 0}, // (page-1)*limit would overflow
+	} {
+		result, err := c.DiversityContext(context.Background(), CommunityFilters{}, "", "", 3, tt.page)
+		if err != nil {
+			t.Fatalf("page %d: %v", tt.page, err)
+		}
+		if rows := result["data"].([]SurveyDiversity); len(rows) != tt.rows || result["total"] != 4 {
+			t.Errorf("page %d = %d of %v rows, want %d of 4", tt.page, len(rows), result["total"], tt.rows)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"fishreports/model"
+)
+
+// Limits of one comparison.
+const (
+	maxCompareSurveys = 10
+	maxCompareSpecies = 10
+)
+
+var (
+	// ErrInvalidCompare is returned for comparisons that can't be set up.
+	ErrInvalidCompare = errors.New("invalid comparison")
+	// ErrCompareNotFound is returned when a compared lake or survey doesn't exist.
+	ErrCompareNotFound = errors.New("compared lake or survey not found")
+)
+
+// compareColumn is one lake survey being compared.
+type compareColumn struct {
+	lake   *model.FishData
+	survey model.Survey
+}
+
+// CompareContext compares the given species across lake surveys. Without
+// dates every lake's latest survey of any of the species is used; with one
+// lake and several dates that lake's surveys on those dates are compared; and
+// with as many dates as lakes each lake is paired with its date. Species are
+// given by common name, code or ID. Histograms are binned with opts and
+// aligned on a shared axis per species.
+func (c *FishSurveyController) CompareContext(ctx context.Context, dows []int, dates []string, speciesNames []string, opts HistogramOptions) (map[string]interface{}, error) {
+	if len(dows) == 0 || len(speciesNames) == 0 {
+		return nil, fmt.Errorf("%w: at least one dow and one species are required", ErrInvalidCompare)
+	}
+	if len(speciesNames) > maxCompareSpecies {
+		return nil, fmt.Errorf("%w: at most %d species can be compared", ErrInvalidCompare, maxCompareSpecies)
+	}
+	codes := make([]string, 0, len(speciesNames))
+	for _, name := range speciesNames {
+		code := c.ResolveSpecies(name)
+		if code == "" {
+			return nil, fmt.Errorf("%w: unknown species %q", ErrInvalidCompare, name)
+		}
+		codes = append(codes, code)
+	}
+
+	// Pair every compared survey with its lake and date ("" for the latest).
+	type selection struct {
+		dow  int
+		date string
+	}
+	var selections []selection
+	switch {
+	case len(dates) == 0:
+		for _, dow := range dows {
+			selections = append(selections, selection{dow: dow})
+		}
+	case len(dows) == 1:
+		for _, date := range dates {
+			selections = append(selections, selection{dow: dows[0], date: date})
+		}
+	case len(dates) == len(dows):
+		for i, dow := range dows {
+			selections = append(selections, selection{dow: dow, date: dates[i]})
+		}
+	default:
+		return nil, fmt.Errorf("%w: give no date, one date per dow, or several dates for a single dow", ErrInvalidCompare)
+	}
+	if len(selections) < 2 {
+		return nil, fmt.Errorf("%w: at least two lakes or survey dates are required", ErrInvalidCompare)
+	}
+	if len(selections) > maxCompareSurveys {
+		return nil, fmt.Errorf("%w: at most %d surveys can be compared", ErrInvalidCompare, maxCompareSurveys)
+	}
+
+	index, err := c.BuildLakeIndexContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]compareColumn, 0, len(selections))
+	for _, sel := range selections {
+		lake := index.ByDOW[sel.dow]
+		if lake == nil {
+			return nil, fmt.Errorf("%w: lake %d", ErrCompareNotFound, sel.dow)
+		}
+		survey, found := compareSurvey(lake, sel.date, codes)
+		if !found {
+			if sel.date == "" {
+				return nil, fmt.Errorf("%w: lake %d has no surveys", ErrCompareNotFound, sel.dow)
+			}
+			return nil, fmt.Errorf("%w: lake %d has no survey on %s", ErrCompareNotFound, sel.dow, sel.date)
+		}
+		columns = append(columns, compareColumn{lake: lake, survey: survey})
+	}
+
+	surveys := make([]map[string]interface{}, 0, len(columns))
+	for _, column := range columns {
+		surveys = append(surveys, map[string]interface{}{
+			"dow_number":  column.lake.Result.DOWNumber,
+			"lake_name":   column.lake.Result.LakeName,
+			"county_name": column.lake.Result.CountyName,
+			"state":       model.LakeState(*column.lake),
+			"survey_id":   column.survey.SurveyID,
+			"survey_date": column.survey.SurveyDate,
+			"survey_type": column.survey.SurveyType,
+		})
+	}
+
+	speciesMap := c.Model.Species()
+	speciesResults := make([]map[string]interface{}, 0, len(codes))
+	for _, code := range codes {
+		histograms := make([][]HistogramBin, len(columns))
+		metrics := make([]map[string]interface{}, len(columns))
+		for i, column := range columns {
+			counts, totalCatch := lengthCountsOf(column.survey, code)
+			histograms[i] = BuildHistogram(counts, opts)
+			metrics[i] = map[string]interface{}{
+				"total_catch":    totalCatch,
+				"fish_measured":  sumCounts(counts),
+				"length":         lengthStats(counts, opts.Unit),
+				"size_structure": sizeStructure(code, counts),
+			}
+		}
+		for i, bins := range alignHistograms(histograms, opts) {
+			metrics[i]["histogram"] = bins
+		}
+		species := speciesMap[code]
+		speciesResults = append(speciesResults, map[string]interface{}{
+			"species":    species.CommonName,
+			"species_id": species.ID,
+			"code":       code,
+			"surveys":    metrics,
+		})
+	}
+
+	return map[string]interface{}{
+		"surveys":   surveys,
+		"species":   speciesResults,
+		"histogram": opts,
+	}, nil
+}
+
+// compareSurvey picks a lake's survey on date, or without a date its latest
+// survey that measured or caught any of the species, falling back to its
+// latest survey.
+func compareSurvey(lake *model.FishData, date string, codes []string) (model.Survey, bool) {
+	var latest, latestWithSpecies *model.Survey
+	for i := range lake.Result.Surveys {
+		survey := &lake.Result.Surveys[i]
+		if date != "" {
+			if survey.SurveyDate == date {
+				return *survey, true
+			}
+			continue
+		}
+		if latest == nil || survey.SurveyDate > latest.SurveyDate {
+			latest = survey
+		}
+		if surveyHasSpecies(*survey, codes) && (latestWithSpecies == nil || survey.SurveyDate > latestWithSpecies.SurveyDate) {
+			latestWithSpecies = survey
+		}
+	}
+	switch {
+	case latestWithSpecies != nil:
+		return *latestWithSpecies, true
+	case latest != nil:
+		return *latest, true
+	default:
+		return model.Survey{}, false
+	}
+}
+
+// surveyHasSpecies reports whether a survey measured or caught any of the species.
+func surveyHasSpecies(survey model.Survey, codes []string) bool {
+	catches := totalCatches(survey)
+	for _, code := range codes {
+		if _, measured := survey.Lengths[code]; measured {
+			return true
+		}
+		if catches[code] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// alignHistograms puts histograms on one axis running from the shortest to
+// the longest bin of any of them, adding empty bins so every histogram has a
+// bin at every edge. Empty bins carry the running total in cumulative mode.
+func alignHistograms(histograms [][]HistogramBin, opts HistogramOptions) [][]HistogramBin {
+	edges := make(map[int]bool)
+	for _, bins := range histograms {
+		for _, bin := range bins {
+			edges[bin.Length] = true
+		}
+	}
+	aligned := make([][]HistogramBin, len(histograms))
+	if len(edges) == 0 {
+		for i := range aligned {
+			aligned[i] = []HistogramBin{}
+		}
+		return aligned
+	}
+	axis := make([]int, 0, len(edges))
+	for edge := range edges {
+		axis = append(axis, edge)
+	}
+	sort.Ints(axis)
+	width := opts.Bin
+	if width <= 0 {
+		width = 1
+	}
+
+	for i, bins := range histograms {
+		byEdge := make(map[int]HistogramBin, len(bins))
+		for _, bin := range bins {
+			byEdge[bin.Length] = bin
+		}
+		running := 0.0
+		row := make([]HistogramBin, 0, (axis[len(axis)-1]-axis[0])/width+1)
+		for edge := axis[0]; edge <= axis[len(axis)-1]; edge += width {
+			bin, exists := byEdge[edge]
+			if !exists {
+				bin = HistogramBin{Length: edge}
+				if opts.Mode == HistogramCumulative {
+					bin.Value = running
+				}
+				if opts.Density {
+					zero := 0.0
+					bin.Density = &zero
+				}
+			}
+			running = bin.Value
+			row = append(row, bin)
+		}
+		aligned[i] = row
+	}
+	return aligned
+}
@@ -1,11 +1,12 @@
 package controller
 
 import (
+	"context"
 	"fishreports/model"
-	"log"
 	"sort"
 	"strings"
 	"math"
+	"sync"
 
 )
 
@@ -15,22 +16,36 @@ import (
 type CountyController struct {
 	Counties []model.County
 	FishSurveyModel  *model.FishSurveyModel 
+	Reconciler       *CountyReconciler // groups lakes under their counties
+	mu               sync.RWMutex // guards Counties across data reloads
 }
 
 
 // In controller/counties.go
-func NewCountyController(counties []model.County, fishModel *model.FishSurveyModel) *CountyController {
+func NewCountyController(counties []model.County, fishModel *model.FishSurveyModel, reconciler *CountyReconciler) *CountyController {
     return &CountyController{
         Counties:         counties,
         FishSurveyModel:  fishModel,
+        Reconciler:       reconciler,
     }
 }
 
 // GetCounties returns the stored counties.
 func (cc *CountyController) GetCounties() []model.County {
+	cc.mu.RLock()
+	defer cc.mu.RUnlock()
 	return cc.Counties
 }
 
+// SetCounties replaces the stored counties, e.g. after a reload re-enhances
+// them with lake names. The previous slice is left untouched for readers
+// still holding it.
+func (cc *CountyController) SetCounties(counties []model.County) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.Counties = counties
+}
+
 func NormalizeCountyName(name string) string {
     name = strings.ToLower(strings.TrimSpace(name))
     // Remove common punctuation.
@@ -44,25 +59,26 @@ func NormalizeCountyName(name string) string {
     return name
 }
 
-// EnhanceCountiesWithLakes enriches the given counties slice with lake names from the fish survey data.
-// It returns the updated slice.
-func EnhanceCountiesWithLakes(m *model.FishSurveyModel, counties []model.County) []model.County {
+// EnhanceCountiesWithLakes enriches the given counties slice with lake names from the fish survey data,
+// grouping lakes under the counties the reconciler matches them to. It returns the updated slice.
+func EnhanceCountiesWithLakes(m *model.FishSurveyModel, counties []model.County, reconciler *CountyReconciler) []model.County {
 	// Create a mapping from normalized county name to a set of lake names.
 	lakesByCounty := make(map[string]map[string]bool)
 	for _, fishDataList := range m.FishDataByCounty {
 		for _, data := range fishDataList {
-			countyKey := NormalizeCountyName(data.Result.CountyName)
-			lakeName := data.Result.LakeName
-			if lakesByCounty[countyKey] == nil {
-				lakesByCounty[countyKey] = make(map[string]bool)
+			// A lake spanning county lines is listed in each of its counties.
+			for _, countyKey := range reconciler.countyKeys(data) {
+				if lakesByCounty[countyKey] == nil {
+					lakesByCounty[countyKey] = make(map[string]bool)
+				}
+				lakesByCounty[countyKey][data.Result.LakeName] = true
 			}
-			lakesByCounty[countyKey][lakeName] = true
 		}
 	}
 
 	// Enrich each county in the slice.
 	for i, county := range counties {
-		normalizedCounty := NormalizeCountyName(county.CountyName)
+		normalizedCounty := countyAggregateKey(county)
 		if lakeSet, exists := lakesByCounty[normalizedCounty]; exists {
 			var lakes []string
 			for lake := range lakeSet {
@@ -70,33 +86,18 @@ func EnhanceCountiesWithLakes(m *model.FishSurveyModel, counties []model.County)
 			}
 			sort.Strings(lakes)
 			counties[i].Lakes = lakes
-		} else {
-			log.Printf("County '%s' (normalized: '%s') has no associated lakes in fish data", county.CountyName, normalizedCounty)
 		}
 	}
 	return counties
 }
 
-func getCountyID(countyName string) string {
-    normalizedInput := NormalizeCountyName(countyName)
-    // Handle known discrepancies:
-    if normalizedInput == "st louis" {
-        normalizedInput = "saint louis"
-    }
-    for _, county := range Counties {
-        if NormalizeCountyName(county.CountyName) == normalizedInput {
-            return county.ID
-        }
-    }
-    return ""
-}
-
 // GetCountyByID searches for a county with the matching ID.
 // Returns a pointer to the county if found, or nil otherwise.
 func (cc *CountyController) GetCountyByID(id string) *model.County {
-	for i := range cc.Counties {
-		if cc.Counties[i].ID == id {
-			return &cc.Counties[i]
+	counties := cc.GetCounties()
+	for i := range counties {
+		if counties[i].ID == id {
+			return &counties[i]
 		}
 	}
 	return nil
@@ -105,57 +106,96 @@ func (cc *CountyController) GetCountyByID(id string) *model.County {
 // GetCountyStats computes statistics for the given county.
 // It aggregates data from the fish survey model using a normalized county name match.
 func (cc *CountyController) GetCountyStats(county *model.County) map[string]interface{} {
+	stats, _ := cc.GetCountyStatsContext(context.Background(), county)
+	return stats
+}
+
+// GetCountyStatsContext is GetCountyStats with a context. The on-the-fly scan
+// stops and returns the context's error once it is cancelled or its deadline
+// passes.
+func (cc *CountyController) GetCountyStatsContext(ctx context.Context, county *model.County) (map[string]interface{}, error) {
 	stats := make(map[string]interface{})
 
 	// Base county info and lakes count.
 	stats["county"] = county
 	stats["number_of_lakes"] = len(county.Lakes)
 
-	normalizedCounty := NormalizeCountyName(county.CountyName)
+	normalizedCounty := countyAggregateKey(*county)
 	var surveys []model.FishData
 
 	// Check if FishSurveyModel or its FishDataByCounty is nil.
-	if cc.FishSurveyModel == nil || cc.FishSurveyModel.FishDataByCounty == nil {
+	var fishDataByCounty map[string][]model.FishData
+	var agg *model.Aggregates
+	var speciesMap map[string]model.Species
+	if cc.FishSurveyModel != nil {
+		fishDataByCounty, agg = cc.FishSurveyModel.Snapshot()
+		speciesMap = cc.FishSurveyModel.Species()
+	}
+	if fishDataByCounty == nil {
 		// No fish data available; return base stats.
 		stats["survey_ids"] = []string{}
 		stats["total_surveys"] = 0
 		stats["total_fish_caught"] = 0
+		stats["unknown_species_fish_caught"] = 0
 		stats["number_of_species"] = 0
 		stats["species_distribution"] = map[string]float64{}
 		stats["average_fish_per_survey"] = 0.0
-		return stats
-	}
-
-	// Aggregate fish survey data that match the normalized county name.
-	for key, fishDataList := range cc.FishSurveyModel.FishDataByCounty {
-		if NormalizeCountyName(key) == normalizedCounty {
-			surveys = append(surveys, fishDataList...)
-		}
+		return stats, nil
 	}
 
 	var surveyIDs []string
 	speciesCounts := make(map[string]int)
 	totalFishCaught := 0
+	unknownCatch := 0
 	totalSurveys := 0
 
-	// Process each survey.
-	for _, fishData := range surveys {
-		for _, survey := range fishData.Result.Surveys {
-			surveyIDs = append(surveyIDs, survey.SurveyID)
-			totalSurveys++
-			// Process each fish catch summary.
-			for _, summary := range survey.FishCatchSummaries {
-				if summary.Species != nil && summary.TotalCatch != nil {
-					// Use species abbreviation from the summary.
-					speciesAbbrev := *summary.Species
-					speciesID := speciesAbbrev
-					// Attempt to look up the species ID from the SpeciesMap.
-					if speciesInfo, exists := cc.FishSurveyModel.SpeciesMap[speciesAbbrev]; exists {
-						speciesID = speciesInfo.ID
+	if agg != nil {
+		// Serve from the materialized county rollup. The response gets its
+		// own copies so callers can't change the shared rollup.
+		if countyAgg := agg.Counties[normalizedCounty]; countyAgg != nil {
+			surveyIDs = append([]string(nil), countyAgg.SurveyIDs...)
+			for speciesID, count := range countyAgg.SpeciesCounts {
+				speciesCounts[speciesID] = count
+			}
+			totalFishCaught = countyAgg.TotalFishCaught
+			unknownCatch = countyAgg.UnknownCatch
+			totalSurveys = countyAgg.TotalSurveys
+		}
+	} else {
+		// Aggregate fish survey data that match the normalized county name.
+		for _, fishDataList := range fishDataByCounty {
+			for _, data := range fishDataList {
+				for _, countyKey := range cc.Reconciler.countyKeys(data) {
+					if countyKey == normalizedCounty {
+						surveys = append(surveys, data)
+						break
+					}
+				}
+			}
+		}
+
+		// Process each survey.
+		for _, fishData := range surveys {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			for _, survey := range fishData.Result.Surveys {
+				surveyIDs = append(surveyIDs, survey.SurveyID)
+				totalSurveys++
+				// Process each fish catch summary.
+				for _, summary := range survey.FishCatchSummaries {
+					if summary.Species != nil && summary.TotalCatch != nil {
+						// Use species abbreviation from the summary.
+						speciesAbbrev := *summary.Species
+						count := *summary.TotalCatch
+						// Look up the species ID; unknown codes stay out of the distribution.
+						if speciesInfo, exists := speciesMap[speciesAbbrev]; exists {
+							speciesCounts[speciesInfo.ID] += count
+						} else {
+							unknownCatch += count
+						}
+						totalFishCaught += count
 					}
-					count := *summary.TotalCatch
-					speciesCounts[speciesID] += count
-					totalFishCaught += count
 				}
 			}
 		}
@@ -164,6 +204,7 @@ func (cc *CountyController) GetCountyStats(county *model.County) map[string]inte
 	stats["survey_ids"] = surveyIDs
 	stats["total_surveys"] = totalSurveys
 	stats["total_fish_caught"] = totalFishCaught
+	stats["unknown_species_fish_caught"] = unknownCatch
 	stats["number_of_species"] = len(speciesCounts)
 
 	// Build pie chart data: percentage distribution per species (using species IDs).
@@ -188,5 +229,5 @@ func (cc *CountyController) GetCountyStats(county *model.County) map[string]inte
 		stats["average_fish_per_survey"] = 0.0
 	}
 
-	return stats
+	return stats, nil
 }
\ No newline at end of file
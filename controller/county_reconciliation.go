@@ -0,0 +1,477 @@
+package controller
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"fishreports/model"
+)
+
+// County match methods, strongest first.
+const (
+	CountyMatchFIPS  = "fips"
+	CountyMatchExact = "exact"
+	CountyMatchAlias = "alias"
+	CountyMatchFuzzy = "fuzzy"
+	CountyMatchNone  = "none"
+)
+
+// CountyMatch is the result of matching a survey's county to the county list.
+type CountyMatch struct {
+	Name       string  `json:"name"` // county name as given by the survey data
+	State      string  `json:"state"`
+	CountyID   string  `json:"county_id,omitempty"`
+	CountyName string  `json:"county_name,omitempty"`
+	Method     string  `json:"method"`
+	Confidence float64 `json:"confidence"` // 1 for FIPS, exact and alias matches
+}
+
+// Matched reports whether the survey county was matched to a county.
+func (cm CountyMatch) Matched() bool {
+	return cm.CountyID != ""
+}
+
+// CountyReconciler matches survey county names to the county list using, in
+// order, the FIPS code when the scraper provides one, the normalized name, the
+// alias table, and fuzzy name similarity. Fuzzy matches below MinConfidence
+// are left unmatched.
+type CountyReconciler struct {
+	counties      []model.County
+	aliases       map[string]string // normalized survey name -> normalized county name
+	minConfidence float64
+	lakeCounties  map[int][]string // DOW number -> extra county names of lakes spanning county lines
+	cache         map[string]CountyMatch
+	mu            sync.RWMutex
+}
+
+// DefaultCountyAliases returns the built-in spellings of county names used by
+// the survey data, keyed by survey spelling.
+func DefaultCountyAliases() map[string]string {
+	return map[string]string{
+		"St Louis": "Saint Louis",
+	}
+}
+
+// NewCountyReconciler creates a reconciler with the given aliases (survey
+// spelling -> county name) and fuzzy match threshold between 0 and 1.
+func NewCountyReconciler(aliases map[string]string, minConfidence float64) *CountyReconciler {
+	r := &CountyReconciler{minConfidence: minConfidence}
+	r.SetAliases(aliases)
+	return r
+}
+
+// SetCounties replaces the county list matched against.
+func (r *CountyReconciler) SetCounties(counties []model.County) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counties = counties
+	r.cache = make(map[string]CountyMatch)
+}
+
+// SetAliases replaces the alias table.
+func (r *CountyReconciler) SetAliases(aliases map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.aliases = make(map[string]string, len(aliases))
+	for alias, countyName := range aliases {
+		r.aliases[NormalizeCountyName(alias)] = NormalizeCountyName(countyName)
+	}
+	r.cache = make(map[string]CountyMatch)
+}
+
+// SetMinConfidence sets the fuzzy match threshold.
+func (r *CountyReconciler) SetMinConfidence(minConfidence float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.minConfidence = minConfidence
+	r.cache = make(map[string]CountyMatch)
+}
+
+// SetLakeCounties replaces the table of counties for lakes spanning county
+// lines, keyed by DOW number.
+func (r *CountyReconciler) SetLakeCounties(lakeCounties map[int][]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lakeCounties = lakeCounties
+}
+
+// MatchLakeCounties matches every county a lake lies in: the county of its
+// survey data plus those listed for its DOW number in the lake counties
+// table. Each county appears once; unmatched names are included so they can
+// be reported.
+func (r *CountyReconciler) MatchLakeCounties(data model.FishData) []CountyMatch {
+	r.mu.RLock()
+	extra := r.lakeCounties[data.Result.DOWNumber]
+	r.mu.RUnlock()
+
+	state := model.LakeState(data)
+	matches := []CountyMatch{r.MatchLake(data)}
+	seen := map[string]bool{lakeMatchKey(matches[0]): true}
+	for _, name := range extra {
+		match := r.MatchInState(state, name)
+		if key := lakeMatchKey(match); !seen[key] {
+			seen[key] = true
+			matches = append(matches, match)
+		}
+	}
+	return matches
+}
+
+// lakeMatchKey identifies a match's county, or its name when unmatched.
+func lakeMatchKey(match CountyMatch) string {
+	if match.Matched() {
+		return match.CountyID
+	}
+	return "name:" + stateCountyKey(match.State, match.Name)
+}
+
+// MatchLake matches a lake's county within its state, preferring its FIPS
+// code.
+func (r *CountyReconciler) MatchLake(data model.FishData) CountyMatch {
+	state := model.LakeState(data)
+	if fips := normalizeFIPS(data.Result.CountyFIPS); fips != "" {
+		r.mu.RLock()
+		counties := r.counties
+		r.mu.RUnlock()
+		for _, county := range counties {
+			if model.CountyState(county) == state && normalizeFIPS(county.FIPSCode) == fips {
+				return CountyMatch{
+					Name:       data.Result.CountyName,
+					State:      state,
+					CountyID:   county.ID,
+					CountyName: county.CountyName,
+					Method:     CountyMatchFIPS,
+					Confidence: 1,
+				}
+			}
+		}
+	}
+	return r.MatchInState(state, data.Result.CountyName)
+}
+
+// Match matches a county name in the default state.
+func (r *CountyReconciler) Match(name string) CountyMatch {
+	return r.MatchInState(model.DefaultState, name)
+}
+
+// MatchInState matches a county name among one state's counties. Results are
+// cached per state and name.
+func (r *CountyReconciler) MatchInState(state, name string) CountyMatch {
+	state = model.NormalizeState(state)
+	cacheKey := state + "|" + name
+	r.mu.RLock()
+	cached, exists := r.cache[cacheKey]
+	r.mu.RUnlock()
+	if exists {
+		return cached
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	match := r.match(state, name)
+	if r.cache == nil {
+		r.cache = make(map[string]CountyMatch)
+	}
+	r.cache[cacheKey] = match
+	return match
+}
+
+// CountyIDByKey returns the ID of the county with the given state-qualified
+// key, as used by the aggregates, or "".
+func (r *CountyReconciler) CountyIDByKey(key string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, county := range r.counties {
+		if countyAggregateKey(county) == key {
+			return county.ID
+		}
+	}
+	return ""
+}
+
+// CountyByID returns the county with the given ID, compared
+// case-insensitively.
+func (r *CountyReconciler) CountyByID(id string) (model.County, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, county := range r.counties {
+		if strings.EqualFold(county.ID, id) {
+			return county, true
+		}
+	}
+	return model.County{}, false
+}
+
+// match does the uncached name match. Callers hold r.mu.
+func (r *CountyReconciler) match(state, name string) CountyMatch {
+	normalized := NormalizeCountyName(name)
+	result := CountyMatch{Name: name, State: state, Method: CountyMatchNone}
+	if normalized == "" {
+		return result
+	}
+
+	byName := make(map[string]model.County, len(r.counties))
+	for _, county := range r.counties {
+		if model.CountyState(county) == state {
+			byName[NormalizeCountyName(county.CountyName)] = county
+		}
+	}
+	found := func(county model.County, method string, confidence float64) CountyMatch {
+		result.CountyID = county.ID
+		result.CountyName = county.CountyName
+		result.Method = method
+		result.Confidence = confidence
+		return result
+	}
+
+	if county, exists := byName[normalized]; exists {
+		return found(county, CountyMatchExact, 1)
+	}
+	if target, exists := r.aliases[normalized]; exists {
+		if county, exists := byName[target]; exists {
+			return found(county, CountyMatchAlias, 1)
+		}
+	}
+
+	// Fuzzy match; spacing differences ("lac qui parle" vs "lacqui parle") don't count.
+	var best model.County
+	bestScore := 0.0
+	compact := strings.ReplaceAll(normalized, " ", "")
+	for countyName, county := range byName {
+		score := nameSimilarity(compact, strings.ReplaceAll(countyName, " ", ""))
+		if score > bestScore || (score == bestScore && county.CountyName < best.CountyName) {
+			best, bestScore = county, score
+		}
+	}
+	bestScore = math.Round(bestScore*100) / 100
+	if bestScore >= r.minConfidence && bestScore > 0 {
+		return found(best, CountyMatchFuzzy, bestScore)
+	}
+	// Keep the closest county as a suggestion for the report.
+	if bestScore > 0 {
+		result.CountyName = best.CountyName
+		result.Confidence = bestScore
+	}
+	return result
+}
+
+// nameSimilarity scores two strings from 0 to 1 by edit distance.
+func nameSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 0
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein returns the edit distance between two rune slices.
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// normalizeFIPS reduces a FIPS code to its three-digit county part, so "001",
+// "1" and the full "27001" compare equal.
+func normalizeFIPS(fips string) string {
+	fips = strings.TrimSpace(fips)
+	if fips == "" {
+		return ""
+	}
+	if len(fips) > 3 {
+		fips = fips[len(fips)-3:]
+	}
+	for len(fips) < 3 {
+		fips = "0" + fips
+	}
+	return fips
+}
+
+// stateCountyKey qualifies a normalized county name with its state so
+// same-named counties in different states stay apart. Keys in the default
+// state are the bare normalized names.
+func stateCountyKey(state, countyName string) string {
+	if state = model.NormalizeState(state); state != model.DefaultState {
+		return strings.ToLower(state) + ":" + NormalizeCountyName(countyName)
+	}
+	return NormalizeCountyName(countyName)
+}
+
+// countyAggregateKey returns the key a county's rollup is stored under.
+func countyAggregateKey(county model.County) string {
+	return stateCountyKey(county.State, county.CountyName)
+}
+
+// countyKeys returns the state-qualified normalized county names a lake is
+// grouped under: each matched county's name, or the given name when it is
+// unmatched.
+func (r *CountyReconciler) countyKeys(data model.FishData) []string {
+	matches := r.MatchLakeCounties(data)
+	keys := make([]string, 0, len(matches))
+	for _, match := range matches {
+		if match.Matched() {
+			keys = append(keys, stateCountyKey(match.State, match.CountyName))
+		} else {
+			keys = append(keys, stateCountyKey(match.State, match.Name))
+		}
+	}
+	return keys
+}
+
+// LakeCountyID returns the ID of the county a lake's survey data names,
+// matched within the lake's state, or "".
+func (r *CountyReconciler) LakeCountyID(data model.FishData) string {
+	return r.MatchLake(data).CountyID
+}
+
+// lakeCountyIDs returns the IDs of every matched county a lake lies in.
+func (r *CountyReconciler) lakeCountyIDs(data model.FishData) []string {
+	var ids []string
+	for _, match := range r.MatchLakeCounties(data) {
+		if match.Matched() {
+			ids = append(ids, match.CountyID)
+		}
+	}
+	return ids
+}
+
+// UnmatchedCounty is a survey county name that matched no county.
+type UnmatchedCounty struct {
+	Name       string  `json:"name"`
+	State      string  `json:"state"`
+	Lakes      int     `json:"lakes"`
+	Surveys    int     `json:"surveys"`
+	DOWNumbers []int   `json:"dow_numbers"`
+	Suggestion string  `json:"suggestion,omitempty"` // closest county below the fuzzy threshold
+	Confidence float64 `json:"confidence,omitempty"`
+}
+
+// ReconciledCounty is a survey county name matched other than exactly.
+type ReconciledCounty struct {
+	CountyMatch
+	Lakes int `json:"lakes"`
+}
+
+// CountyReconciliationReport lists how survey county names matched the
+// county list.
+type CountyReconciliationReport struct {
+	SurveyCountyNames    int                `json:"survey_county_names"`
+	Matched              int                `json:"matched"`
+	Reconciled           []ReconciledCounty `json:"reconciled"` // FIPS, alias and fuzzy matches
+	Unmatched            []UnmatchedCounty  `json:"unmatched"`
+	CountiesWithoutLakes []model.County     `json:"counties_without_lakes"`
+}
+
+// ReconcileCounties matches every lake's county and reports unmatched survey
+// county names, non-exact matches and counties no lake is matched to.
+func (r *CountyReconciler) ReconcileCounties(fishDataByCounty map[string][]model.FishData, counties []model.County) *CountyReconciliationReport {
+	report := &CountyReconciliationReport{
+		Reconciled:           []ReconciledCounty{},
+		Unmatched:            []UnmatchedCounty{},
+		CountiesWithoutLakes: []model.County{},
+	}
+
+	names := make(map[string]bool)
+	reconciled := make(map[string]*ReconciledCounty)
+	unmatched := make(map[string]*UnmatchedCounty)
+	countiesWithLakes := make(map[string]bool)
+	for _, fishDataList := range fishDataByCounty {
+		for _, data := range fishDataList {
+			for _, match := range r.MatchLakeCounties(data) {
+				name := match.State + "|" + match.Name
+				names[name] = true
+				if !match.Matched() {
+					entry := unmatched[name]
+					if entry == nil {
+						entry = &UnmatchedCounty{Name: match.Name, State: match.State, Suggestion: match.CountyName, Confidence: match.Confidence}
+						unmatched[name] = entry
+					}
+					entry.Lakes++
+					entry.Surveys += len(data.Result.Surveys)
+					entry.DOWNumbers = append(entry.DOWNumbers, data.Result.DOWNumber)
+					continue
+				}
+				countiesWithLakes[match.CountyID] = true
+				if match.Method == CountyMatchExact {
+					continue
+				}
+				key := match.Method + "|" + name
+				entry := reconciled[key]
+				if entry == nil {
+					entry = &ReconciledCounty{CountyMatch: match}
+					reconciled[key] = entry
+				}
+				entry.Lakes++
+			}
+		}
+	}
+
+	report.SurveyCountyNames = len(names)
+	report.Matched = len(names) - len(unmatched)
+	for _, entry := range reconciled {
+		report.Reconciled = append(report.Reconciled, *entry)
+	}
+	sort.Slice(report.Reconciled, func(i, j int) bool {
+		if report.Reconciled[i].Name != report.Reconciled[j].Name {
+			return report.Reconciled[i].Name < report.Reconciled[j].Name
+		}
+		return report.Reconciled[i].Method < report.Reconciled[j].Method
+	})
+	for _, entry := range unmatched {
+		sort.Ints(entry.DOWNumbers)
+		report.Unmatched = append(report.Unmatched, *entry)
+	}
+	sort.Slice(report.Unmatched, func(i, j int) bool {
+		if report.Unmatched[i].State != report.Unmatched[j].State {
+			return report.Unmatched[i].State < report.Unmatched[j].State
+		}
+		return report.Unmatched[i].Name < report.Unmatched[j].Name
+	})
+	for _, county := range counties {
+		if !countiesWithLakes[county.ID] {
+			county.Lakes = nil
+			report.CountiesWithoutLakes = append(report.CountiesWithoutLakes, county)
+		}
+	}
+	return report
+}
+
+// Reconciliation reports how the current survey data's county names match
+// the county list.
+func (cc *CountyController) Reconciliation() *CountyReconciliationReport {
+	var fishDataByCounty map[string][]model.FishData
+	if cc.FishSurveyModel != nil {
+		fishDataByCounty, _ = cc.FishSurveyModel.Snapshot()
+	}
+	return cc.Reconciler.ReconcileCounties(fishDataByCounty, cc.GetCounties())
+}
+
+// anyInSet reports whether any of the IDs, lowercased, is in the set.
+func anyInSet(ids []string, set map[string]bool) bool {
+	for _, id := range ids {
+		if set[strings.ToLower(id)] {
+			return true
+		}
+	}
+	return false
+}
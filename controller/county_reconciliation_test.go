@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	"fishreports/model"
+)
+
+// reconcilerCounties adds Saint Louis, Lac qui Parle and a Wisconsin county
+// to the fixture counties.
+func reconcilerCounties() []model.County {
+	counties := append(fixtureCounties(),
+		model.County{CountyName: "Saint Louis", State: "MN", FIPSCode: "27137"},
+		model.County{CountyName: "Lac qui Parle", State: "MN", FIPSCode: "27073"},
+		model.County{CountyName: "Ashland", State: "WI", FIPSCode: "55003"},
+	)
+	for i := range counties {
+		counties[i].ID = StableCountyID(counties[i])
+	}
+	return counties
+}
+
+// countyIDs maps the reconciler counties' names to their IDs.
+func countyIDs(counties []model.County) map[string]string {
+	ids := make(map[string]string, len(counties))
+	for _, county := range counties {
+		ids[county.CountyName] = county.ID
+	}
+	return ids
+}
+
+func TestNormalizeFIPS(t *testing.T) {
+	for fips, want := range map[string]string{
+		"1":       "001",
+		"21":      "021",
+		"001":     "001",
+		"27001":   "001",
+		" 27001 ": "001",
+		"":        "",
+		"  ":      "",
+	} {
+		if got := normalizeFIPS(fips); got != want {
+			t.Errorf("normalizeFIPS(%q) = %q, want %q", fips, got, want)
+		}
+	}
+}
+
+func TestMatchInState(t *testing.T) {
+	counties := reconcilerCounties()
+	ids := countyIDs(counties)
+	reconciler := NewCountyReconciler(DefaultCountyAliases(), 0.8)
+	reconciler.SetCounties(counties)
+
+	for _, tt := range []struct {
+		state, name string
+		want        CountyMatch
+	}{
+		{"MN", "Crow Wing", CountyMatch{CountyID: ids["Crow Wing"], CountyName: "Crow Wing", Method: CountyMatchExact, Confidence: 1}},
+		{"mn", "crow wing county", CountyMatch{CountyID: ids["Crow Wing"], CountyName: "Crow Wing", Method: CountyMatchExact, Confidence: 1}},
+		{"", "Cass", CountyMatch{CountyID: ids["Cass"], CountyName: "Cass", Method: CountyMatchExact, Confidence: 1}},
+		{"MN", "St Louis", CountyMatch{CountyID: ids["Saint Louis"], CountyName: "Saint Louis", Method: CountyMatchAlias, Confidence: 1}},
+		{"MN", "St. Louis County", CountyMatch{CountyID: ids["Saint Louis"], CountyName: "Saint Louis", Method: CountyMatchAlias, Confidence: 1}},
+		// Fuzzy matches at or above the threshold; spacing doesn't count.
+		{"MN", "Aitken", CountyMatch{CountyID: ids["Aitkin"], CountyName: "Aitkin", Method: CountyMatchFuzzy, Confidence: 0.83}},
+		{"MN", "Casss", CountyMatch{CountyID: ids["Cass"], CountyName: "Cass", Method: CountyMatchFuzzy, Confidence: 0.8}},
+		{"MN", "Lacqui Parle", CountyMatch{CountyID: ids["Lac qui Parle"], CountyName: "Lac qui Parle", Method: CountyMatchFuzzy, Confidence: 1}},
+		// Below the threshold the closest county is only a suggestion.
+		{"MN", "Cas", CountyMatch{CountyName: "Cass", Method: CountyMatchNone, Confidence: 0.75}},
+		{"MN", "", CountyMatch{Method: CountyMatchNone}},
+		// Counties are matched within the state.
+		{"WI", "Ashland", CountyMatch{CountyID: ids["Ashland"], CountyName: "Ashland", Method: CountyMatchExact, Confidence: 1}},
+	} {
+		got := reconciler.MatchInState(tt.state, tt.name)
+		tt.want.Name, tt.want.State = tt.name, model.NormalizeState(tt.state)
+		if got != tt.want {
+			t.Errorf("MatchInState(%q, %q) = %+v, want %+v", tt.state, tt.name, got, tt.want)
+		}
+	}
+	if match := reconciler.MatchInState("MN", "Ashland"); match.Matched() {
+		t.Errorf("Ashland matched %+v in Minnesota", match)
+	}
+	if match := reconciler.MatchInState("WI", "Aitkin"); match.Matched() {
+		t.Errorf("Aitkin matched %+v in Wisconsin", match)
+	}
+
+	// Raising the threshold drops the cached fuzzy match.
+	reconciler.SetMinConfidence(0.9)
+	want := CountyMatch{Name: "Aitken", State: "MN", CountyName: "Aitkin", Method: CountyMatchNone, Confidence: 0.83}
+	if got := reconciler.MatchInState("MN", "Aitken"); got != want {
+		t.Errorf("Aitken above 0.9 = %+v, want %+v", got, want)
+	}
+}
+
+func TestMatchLakeByFIPS(t *testing.T) {
+	counties := reconcilerCounties()
+	ids := countyIDs(counties)
+	reconciler := NewCountyReconciler(nil, 0.8)
+	reconciler.SetCounties(counties)
+
+	for _, fips := range []string{"1", "001", "27001"} {
+		lake := fixtureLake(1000100, "Not A County", "Big Lake")
+		lake.Result.CountyFIPS = fips
+		want := CountyMatch{Name: "Not A County", State: "MN", CountyID: ids["Aitkin"], CountyName: "Aitkin", Method: CountyMatchFIPS, Confidence: 1}
+		if got := reconciler.MatchLake(lake); got != want {
+			t.Errorf("FIPS %q = %+v, want %+v", fips, got, want)
+		}
+	}
+
+	// A FIPS code of no county in the lake's state falls back to the name.
+	lake := fixtureLake(1000100, "Cass", "Big Lake")
+	lake.Result.CountyFIPS = "55003"
+	if got := reconciler.MatchLake(lake); got.Method != CountyMatchExact || got.CountyID != ids["Cass"] {
+		t.Errorf("Minnesota lake with a Wisconsin FIPS = %+v, want Cass by name", got)
+	}
+	lake.Result.State = "WI"
+	if got := reconciler.MatchLake(lake); got.Method != CountyMatchFIPS || got.CountyID != ids["Ashland"] {
+		t.Errorf("Wisconsin lake = %+v, want Ashland by FIPS", got)
+	}
+}
+
+func TestReconcileCounties(t *testing.T) {
+	counties := reconcilerCounties()
+	ids := countyIDs(counties)
+	reconciler := NewCountyReconciler(DefaultCountyAliases(), 0.8)
+	reconciler.SetCounties(counties)
+	reconciler.SetLakeCounties(map[int][]string{11000200: {"Crow Wing"}})
+
+	byFIPS := fixtureLake(1000200, "Aitkin Co", "Round Lake")
+	byFIPS.Result.CountyFIPS = "27001"
+	data := map[string][]model.FishData{
+		"Aitkin":   {fixtureLake(1000100, "Aitkin", "Big Lake"), byFIPS},
+		"Aitken":   {fixtureLake(1000300, "Aitken", "Long Lake")},
+		"Cass":     {fixtureLake(11000200, "Cass", "Long Lake")},
+		"St Louis": {fixtureLake(69000100, "St Louis", "Pelican Lake", model.Survey{}, model.Survey{})},
+		"Cas":      {fixtureLake(11000900, "Cas", "Leech Lake", model.Survey{}, model.Survey{}), fixtureLake(11000300, "Cas", "Cass Lake", model.Survey{})},
+		"Ashland":  {fixtureLake(99000100, "Ashland", "Mystery Lake")}, // a Minnesota lake
+	}
+	report := reconciler.ReconcileCounties(data, counties)
+
+	// Aitkin, Aitkin Co, Aitken, Cass, Crow Wing, St Louis, Cas and Ashland.
+	if report.SurveyCountyNames != 8 || report.Matched != 6 {
+		t.Errorf("matched %d of %d names, want 6 of 8", report.Matched, report.SurveyCountyNames)
+	}
+	wantReconciled := []ReconciledCounty{
+		{CountyMatch{Name: "Aitken", State: "MN", CountyID: ids["Aitkin"], CountyName: "Aitkin", Method: CountyMatchFuzzy, Confidence: 0.83}, 1},
+		{CountyMatch{Name: "Aitkin Co", State: "MN", CountyID: ids["Aitkin"], CountyName: "Aitkin", Method: CountyMatchFIPS, Confidence: 1}, 1},
+		{CountyMatch{Name: "St Louis", State: "MN", CountyID: ids["Saint Louis"], CountyName: "Saint Louis", Method: CountyMatchAlias, Confidence: 1}, 1},
+	}
+	if !reflect.DeepEqual(report.Reconciled, wantReconciled) {
+		t.Errorf("reconciled = %+v, want %+v", report.Reconciled, wantReconciled)
+	}
+
+	if len(report.Unmatched) != 2 {
+		t.Fatalf("unmatched = %+v, want Ashland and Cas", report.Unmatched)
+	}
+	if got := report.Unmatched[0]; got.Name != "Ashland" || got.Lakes != 1 || !reflect.DeepEqual(got.DOWNumbers, []int{99000100}) {
+		t.Errorf("first unmatched = %+v, want Ashland's lake", got)
+	}
+	wantCas := UnmatchedCounty{Name: "Cas", State: "MN", Lakes: 2, Surveys: 3, DOWNumbers: []int{11000300, 11000900}, Suggestion: "Cass", Confidence: 0.75}
+	if got := report.Unmatched[1]; !reflect.DeepEqual(got, wantCas) {
+		t.Errorf("second unmatched = %+v, want %+v", got, wantCas)
+	}
+
+	var without []string
+	for _, county := range report.CountiesWithoutLakes {
+		without = append(without, county.CountyName)
+	}
+	if want := []string{"Lac qui Parle", "Ashland"}; !reflect.DeepEqual(without, want) {
+		t.Errorf("counties without lakes = %v, want %v", without, want)
+	}
+}
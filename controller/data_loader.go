@@ -1,11 +1,13 @@
 package controller
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
-	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -14,10 +16,33 @@ import (
 	"fishreports/model"
 )
 
+// idNamespace derives the IDs of counties and species that have none in the
+// data files, so the same entry gets the same ID on every load and saved
+// filters such as webhook subscriptions keep matching across restarts.
+var idNamespace = uuid.MustParse("99b07061-cfb8-4919-95ea-89a73a50bd38")
+
+// StableCountyID returns the ID of a county without one in its data file,
+// derived from its state and FIPS code, or its name when it has no FIPS code.
+func StableCountyID(county model.County) string {
+	key := county.FIPSCode
+	if key == "" {
+		key = NormalizeCountyName(county.CountyName)
+	}
+	return uuid.NewSHA1(idNamespace, []byte("county:"+county.State+":"+key)).String()
+}
+
+// StableSpeciesID returns the ID of a species without one in the species
+// file, derived from its code.
+func StableSpeciesID(code string) string {
+	return uuid.NewSHA1(idNamespace, []byte("species:"+strings.ToUpper(code))).String()
+}
+
 // ✅ Load Counties from JSON File
 var Counties []model.County
 
-func LoadCounties(filePath string) ([]model.County, error) {
+// LoadCounties reads a state's counties, setting the state of counties
+// without one and deriving the IDs of counties without one.
+func LoadCounties(filePath, state string) ([]model.County, error) {
     var counties []model.County
     file, err := os.ReadFile(filePath)
     if err != nil {
@@ -27,25 +52,74 @@ func LoadCounties(filePath string) ([]model.County, error) {
     if err != nil {
         return nil, fmt.Errorf("failed to parse county JSON: %w", err)
     }
-    // Assign a new ID to each county if missing.
-    for i, county := range counties {
-        if county.ID == "" {
-            counties[i].ID = uuid.New().String()
+    // Assign a stable ID to each county if missing.
+    for i := range counties {
+        if counties[i].State == "" {
+            counties[i].State = state
+        }
+        if counties[i].ID == "" {
+            counties[i].ID = StableCountyID(counties[i])
         }
     }
     return counties, nil
 }
 
+// LoadLakeCounties reads the counties of lakes spanning county lines: a JSON
+// object mapping DOW numbers to county names. A missing file is an empty table.
+func LoadLakeCounties(filePath string) (map[int][]string, error) {
+	file, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[int][]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lake counties file: %w", err)
+	}
+	var raw map[string][]string
+	if err := json.Unmarshal(file, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse lake counties JSON: %w", err)
+	}
+	lakeCounties := make(map[int][]string, len(raw))
+	for key, counties := range raw {
+		dow, err := strconv.Atoi(strings.TrimSpace(key))
+		if err != nil {
+			return nil, fmt.Errorf("invalid DOW number %q in lake counties file", key)
+		}
+		lakeCounties[dow] = counties
+	}
+	log.Printf("✅ Loaded counties for %d multi-county lakes from %s", len(lakeCounties), filePath)
+	return lakeCounties, nil
+}
+
 // ✅ Load Fish Survey Data
-func LoadFishData(m *model.FishSurveyModel, syncDir string) error {
+func LoadFishData(ctx context.Context, m *model.FishSurveyModel, syncDir string, states []StateDataset, validator *Validator) error {
+	state, exists := findState(states, model.DefaultState)
+	if !exists {
+		state = DefaultStateDataset("", syncDir)
+	}
+	state.SurveyDir = syncDir
+	return LoadStateData(ctx, m, []StateDataset{state}, validator)
+}
+
+// LoadStateData loads every state's survey sources with its adapter and runs
+// the surveys through validator. Reading stops and returns the context's
+// error once it is cancelled; documents already queued are still parsed into m.
+func LoadStateData(ctx context.Context, m *model.FishSurveyModel, states []StateDataset, validator *Validator) error {
 	m.FishDataByCounty = make(map[string][]model.FishData)
-	fileChan := make(chan string, 100)
+	m.LakeFiles = make(map[int][]string)
+	m.ReadOnlyLakes = make(map[int]string)
+	m.Validation = model.NewValidationReport()
+
+	type job struct {
+		doc   SurveyDocument
+		state StateDataset
+	}
+	docChan := make(chan job, 100)
 	var wg sync.WaitGroup
 
 	worker := func() {
-		for path := range fileChan {
-			// Process the file without logging
-			_, _ = processFile(m, path)
+		for j := range docChan {
+			// Process the document without logging
+			_, _ = processDocument(m, j.doc, j.state, validator)
 			wg.Done()
 		}
 	}
@@ -55,62 +129,90 @@ func LoadFishData(m *model.FishSurveyModel, syncDir string) error {
 		go worker()
 	}
 
-	err := filepath.WalkDir(syncDir, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil
-		}
-		if d.IsDir() || !strings.HasSuffix(path, ".json") {
+	var err error
+	for _, state := range states {
+		err = state.Source().Documents(func(doc SurveyDocument) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			wg.Add(1)
+			docChan <- job{doc: doc, state: state}
 			return nil
+		})
+		if err != nil {
+			break
 		}
-		wg.Add(1)
-		fileChan <- path
-		return nil
-	})
+	}
 
-	close(fileChan)
+	close(docChan)
 	wg.Wait()
 	return err
 }
 
-func processFile(m *model.FishSurveyModel, path string) (int, error) {
+func processDocument(m *model.FishSurveyModel, doc SurveyDocument, state StateDataset, validator *Validator) (int, error) {
     m.Mutex.Lock()  // ✅ Lock before modifying shared data
     defer m.Mutex.Unlock()  // ✅ Unlock after modification
 
-    fileData, err := os.ReadFile(path)
+    if doc.Adapter != nil {
+        state.Adapter = doc.Adapter
+    }
+    lakes, report, err := ParseStateData(doc.Data, m, state, validator)
+    if m.Validation != nil {
+        m.Validation.Merge(report)
+    }
     if err != nil {
-        return 0, err
+        return 0, fmt.Errorf("%s: %w", doc.Path, err)
     }
 
-    // Step 1: Read raw JSON into map.
-    var rawData map[string]interface{}
-    if err := json.Unmarshal(fileData, &rawData); err != nil {
-        return 0, err
+    // Step 4: Safely store data in the map.
+    surveys := 0
+    for _, fishData := range lakes {
+        key := fishDataKey(fishData)
+        m.FishDataByCounty[key] = append(m.FishDataByCounty[key], fishData)
+        dow := fishData.Result.DOWNumber
+        if doc.Writable && m.LakeFiles != nil {
+            m.LakeFiles[dow] = append(m.LakeFiles[dow], doc.Path)
+        } else if !doc.Writable && m.ReadOnlyLakes != nil {
+            m.ReadOnlyLakes[dow] = doc.Path
+        }
+        surveys += len(fishData.Result.Surveys)
     }
 
-    // Step 2: Transform fishCount safely.
-    TransformFishCount(rawData, m)
+    // ✅ Return number of surveys processed.
+    return surveys, nil
+}
 
-    // Step 3: Convert transformed JSON back into FishData struct.
-    transformedJSON, _ := json.Marshal(rawData)
-    var fishData model.FishData
-    if err := json.Unmarshal(transformedJSON, &fishData); err != nil {
-        return 0, err
-    }
 
-    // Assign IDs to surveys if missing.
-    for i, survey := range fishData.Result.Surveys {
-        if survey.SurveyID == "" {
-            fishData.Result.Surveys[i].SurveyID = uuid.New().String()
+// ParseFishData turns one raw scraper FishData document of the default
+// state into a FishData: fishCount pairs are transformed, missing survey IDs
+// are assigned and the surveys are run through the validator's rules. Files
+// and the ingest API share this path through ParseStateData. The default
+// state's settings are taken from states when it is configured there.
+func ParseFishData(raw []byte, m *model.FishSurveyModel, states []StateDataset, validator *Validator) (model.FishData, *model.ValidationReport, error) {
+    state, exists := findState(states, model.DefaultState)
+    if !exists {
+        state = DefaultStateDataset("", "")
+    }
+    lakes, report, err := ParseStateData(raw, m, state, validator)
+    if len(lakes) != 1 {
+        if err == nil {
+            err = fmt.Errorf("expected one lake, found %d", len(lakes))
         }
+        return model.FishData{}, report, err
     }
-
-    // Step 4: Safely store data in the map.
-    m.FishDataByCounty[fishData.Result.CountyName] = append(m.FishDataByCounty[fishData.Result.CountyName], fishData)
-
-    // ✅ Return number of surveys processed.
-    return len(fishData.Result.Surveys), nil
+    return lakes[0], report, err
 }
 
+// validateFishData rejects documents that can't be placed on a lake.
+func validateFishData(fishData model.FishData) error {
+    if fishData.Result.DOWNumber <= 0 {
+        return fmt.Errorf("missing or invalid DOWNumber")
+    }
+    if strings.TrimSpace(fishData.Result.CountyName) == "" {
+        return fmt.Errorf("lake %d has no countyName", fishData.Result.DOWNumber)
+    }
+    return nil
+}
 
 func TransformFishCount(data map[string]interface{}, m *model.FishSurveyModel) {
 
@@ -120,10 +222,14 @@ func TransformFishCount(data map[string]interface{}, m *model.FishSurveyModel) {
 				transformed := []map[string]int{}
 				for _, pair := range list {
 					if pairList, ok := pair.([]interface{}); ok && len(pairList) == 2 {
-						transformed = append(transformed, map[string]int{
-							"length":   int(pairList[0].(float64)), // Convert float64 to int
-							"quantity": int(pairList[1].(float64)),
-						})
+						length, lengthOK := pairList[0].(float64)
+						quantity, quantityOK := pairList[1].(float64)
+						if lengthOK && quantityOK {
+							transformed = append(transformed, map[string]int{
+								"length":   int(length), // Convert float64 to int
+								"quantity": int(quantity),
+							})
+						}
 					}
 				}
 				data[key] = transformed
@@ -157,7 +263,7 @@ func LoadSpeciesMap(m *model.FishSurveyModel, speciesFile string) error {
     for code, species := range m.SpeciesMap {
         species.CommonName = capitalizeFirst(species.CommonName)
         if species.ID == "" {
-            species.ID = uuid.New().String()
+            species.ID = StableSpeciesID(code)
         }
         m.SpeciesMap[code] = species
     }
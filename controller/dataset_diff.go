@@ -0,0 +1,295 @@
+package controller
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"fishreports/model"
+)
+
+// diffSurvey is one survey of a dataset with the lake it belongs to.
+type diffSurvey struct {
+	lake   model.FishData
+	survey model.Survey
+}
+
+// indexSurveys maps every survey in the data by survey key. When a key occurs
+// more than once the last one wins, as with the reload fingerprints.
+func indexSurveys(fishDataByCounty map[string][]model.FishData) map[string]diffSurvey {
+	surveys := make(map[string]diffSurvey)
+	for _, fishDataList := range fishDataByCounty {
+		for _, data := range fishDataList {
+			for _, survey := range data.Result.Surveys {
+				surveys[model.SurveyKey(data.Result.DOWNumber, survey)] = diffSurvey{lake: data, survey: survey}
+			}
+		}
+	}
+	return surveys
+}
+
+// DiffDatasets compares two datasets survey by survey. Surveys are matched by
+// survey key (DOW number, date and type); matched surveys whose contents
+// differ are reported as modified with their field-level changes. Survey IDs
+// and quality flags are ignored since both are assigned at load time.
+func DiffDatasets(from, to map[string][]model.FishData, fromLabel, toLabel string) *model.DatasetDiff {
+	previous := indexSurveys(from)
+	current := indexSurveys(to)
+	diff := &model.DatasetDiff{ComparedAt: time.Now().UTC(), From: fromLabel, To: toLabel}
+
+	for key, after := range current {
+		before, seen := previous[key]
+		if !seen {
+			diff.Added = append(diff.Added, newSurveyDiff(key, after, nil))
+			continue
+		}
+		if changes := surveyFieldChanges(before, after); len(changes) > 0 {
+			diff.Modified = append(diff.Modified, newSurveyDiff(key, after, changes))
+		} else {
+			diff.Summary.Unchanged++
+		}
+	}
+	for key, before := range previous {
+		if _, kept := current[key]; !kept {
+			diff.Removed = append(diff.Removed, newSurveyDiff(key, before, nil))
+		}
+	}
+	finishDiff(diff)
+	return diff
+}
+
+// fingerprintDiff compares the persisted fingerprints of an earlier load with
+// the loaded data. Only the keys of the earlier surveys are known, so removed
+// surveys carry no lake details and modified ones no field changes. An empty
+// previous fingerprint means unknown and never counts as a change.
+func fingerprintDiff(previous, current map[string]string, fishDataByCounty map[string][]model.FishData, fromLabel, toLabel string) *model.DatasetDiff {
+	surveys := indexSurveys(fishDataByCounty)
+	diff := &model.DatasetDiff{ComparedAt: time.Now().UTC(), From: fromLabel, To: toLabel}
+	for key, after := range surveys {
+		fingerprint, seen := previous[key]
+		switch {
+		case !seen:
+			diff.Added = append(diff.Added, newSurveyDiff(key, after, nil))
+		case fingerprint != "" && fingerprint != current[key]:
+			diff.Modified = append(diff.Modified, newSurveyDiff(key, after, nil))
+		default:
+			diff.Summary.Unchanged++
+		}
+	}
+	for key := range previous {
+		if _, kept := surveys[key]; kept {
+			continue
+		}
+		removed := model.SurveyDiff{SurveyKey: key}
+		removed.DOWNumber, removed.SurveyDate, removed.SurveyType, _ = model.ParseSurveyKey(key)
+		diff.Removed = append(diff.Removed, removed)
+	}
+	finishDiff(diff)
+	return diff
+}
+
+// newSurveyDiff describes one survey of a diff.
+func newSurveyDiff(key string, s diffSurvey, changes []model.FieldChange) model.SurveyDiff {
+	return model.SurveyDiff{
+		SurveyKey:  key,
+		SurveyID:   s.survey.SurveyID,
+		SurveyDate: s.survey.SurveyDate,
+		SurveyType: s.survey.SurveyType,
+		DOWNumber:  s.lake.Result.DOWNumber,
+		State:      model.LakeState(s.lake),
+		LakeName:   s.lake.Result.LakeName,
+		CountyName: s.lake.Result.CountyName,
+		Changes:    changes,
+	}
+}
+
+// finishDiff sorts the surveys of a diff, oldest first, and counts them.
+// Empty lists are left non-nil so they encode as [] rather than null.
+func finishDiff(diff *model.DatasetDiff) {
+	for _, surveys := range []*[]model.SurveyDiff{&diff.Added, &diff.Removed, &diff.Modified} {
+		if *surveys == nil {
+			*surveys = []model.SurveyDiff{}
+		}
+	}
+	for _, surveys := range [][]model.SurveyDiff{diff.Added, diff.Removed, diff.Modified} {
+		sort.Slice(surveys, func(i, j int) bool {
+			if surveys[i].SurveyDate != surveys[j].SurveyDate {
+				return surveys[i].SurveyDate < surveys[j].SurveyDate
+			}
+			return surveys[i].SurveyKey < surveys[j].SurveyKey
+		})
+	}
+	diff.Summary.Added = len(diff.Added)
+	diff.Summary.Removed = len(diff.Removed)
+	diff.Summary.Modified = len(diff.Modified)
+}
+
+// surveyFieldChanges lists the differences between two versions of a survey:
+// the lake's name and county, the survey sub-type and narrative, the total
+// catch per species and the fish counted per species and length. A species
+// whose lengths appear on one side only is reported as a single change of its
+// total fish count.
+func surveyFieldChanges(before, after diffSurvey) []model.FieldChange {
+	var changes []model.FieldChange
+	addString := func(field, old, new string) {
+		if old != new {
+			changes = append(changes, model.FieldChange{Field: field, Old: old, New: new})
+		}
+	}
+	addString("lake_name", before.lake.Result.LakeName, after.lake.Result.LakeName)
+	addString("county_name", before.lake.Result.CountyName, after.lake.Result.CountyName)
+	addString("survey_sub_type", before.survey.SurveySubType, after.survey.SurveySubType)
+	addString("narrative", before.survey.Narrative, after.survey.Narrative)
+
+	oldCatch, newCatch := totalCatches(before.survey), totalCatches(after.survey)
+	for _, code := range unionKeys(oldCatch, newCatch) {
+		old, hadOld := oldCatch[code]
+		new, hasNew := newCatch[code]
+		if hadOld == hasNew && old == new {
+			continue
+		}
+		changes = append(changes, model.FieldChange{
+			Field: "total_catch." + code,
+			Old:   optionalInt(old, hadOld),
+			New:   optionalInt(new, hasNew),
+		})
+	}
+
+	oldLengths, newLengths := lengthCounts(before.survey), lengthCounts(after.survey)
+	for _, code := range unionKeys(oldLengths, newLengths) {
+		old, hadOld := oldLengths[code]
+		new, hasNew := newLengths[code]
+		if !hadOld || !hasNew {
+			changes = append(changes, model.FieldChange{
+				Field: "lengths." + code,
+				Old:   optionalInt(sumCounts(old), hadOld),
+				New:   optionalInt(sumCounts(new), hasNew),
+			})
+			continue
+		}
+		lengths := make([]int, 0, len(old)+len(new))
+		for length := range old {
+			lengths = append(lengths, length)
+		}
+		for length := range new {
+			if _, exists := old[length]; !exists {
+				lengths = append(lengths, length)
+			}
+		}
+		sort.Ints(lengths)
+		for _, length := range lengths {
+			oldCount, hadCount := old[length]
+			newCount, hasCount := new[length]
+			if oldCount == newCount {
+				continue
+			}
+			changes = append(changes, model.FieldChange{
+				Field: "lengths." + code + "." + strconv.Itoa(length),
+				Old:   optionalInt(oldCount, hadCount),
+				New:   optionalInt(newCount, hasCount),
+			})
+		}
+	}
+	return changes
+}
+
+// totalCatches maps species codes to the survey's total catch. Summaries
+// without a species or total are skipped.
+func totalCatches(survey model.Survey) map[string]int {
+	catches := make(map[string]int)
+	for _, summary := range survey.FishCatchSummaries {
+		if summary.Species == nil || summary.TotalCatch == nil {
+			continue
+		}
+		catches[*summary.Species] += *summary.TotalCatch
+	}
+	return catches
+}
+
+// lengthCounts maps species codes to the fish counted at each length.
+func lengthCounts(survey model.Survey) map[string]map[int]int {
+	counts := make(map[string]map[int]int, len(survey.Lengths))
+	for code, lengthData := range survey.Lengths {
+		byLength := make(map[int]int)
+		if lengthData != nil {
+			for _, fishCount := range lengthData.FishCount {
+				byLength[fishCount.Length] += fishCount.Quantity
+			}
+		}
+		counts[code] = byLength
+	}
+	return counts
+}
+
+// sumCounts totals the fish counted at every length.
+func sumCounts(byLength map[int]int) int {
+	total := 0
+	for _, quantity := range byLength {
+		total += quantity
+	}
+	return total
+}
+
+// optionalInt returns value, or nil when it is missing.
+func optionalInt(value int, present bool) interface{} {
+	if !present {
+		return nil
+	}
+	return value
+}
+
+// unionKeys returns the keys of both maps, sorted.
+func unionKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, exists := a[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// diffEvents turns a diff into survey events: added and updated events for
+// the surveys in to, with the field changes on updated ones, and removed
+// events for the surveys only in from. from may be nil when only the keys of
+// the earlier data are known.
+func diffEvents(diff *model.DatasetDiff, from, to map[string][]model.FishData, speciesMap map[string]model.Species, reconciler *CountyReconciler) []model.SurveyEvent {
+	current := indexSurveys(to)
+	var events []model.SurveyEvent
+	for _, added := range diff.Added {
+		s := current[added.SurveyKey]
+		events = append(events, newSurveyEvent(model.EventSurveyAdded, added.SurveyKey, s.lake, s.survey, speciesMap, reconciler))
+	}
+	for _, modified := range diff.Modified {
+		s := current[modified.SurveyKey]
+		event := newSurveyEvent(model.EventSurveyUpdated, modified.SurveyKey, s.lake, s.survey, speciesMap, reconciler)
+		event.Changes = modified.Changes
+		events = append(events, event)
+	}
+	previous := indexSurveys(from)
+	for _, removed := range diff.Removed {
+		if s, exists := previous[removed.SurveyKey]; exists {
+			events = append(events, newSurveyEvent(model.EventSurveyRemoved, removed.SurveyKey, s.lake, s.survey, speciesMap, reconciler))
+			continue
+		}
+		events = append(events, model.SurveyEvent{
+			Type:       model.EventSurveyRemoved,
+			SurveyKey:  removed.SurveyKey,
+			SurveyDate: removed.SurveyDate,
+			SurveyType: removed.SurveyType,
+			DOWNumber:  removed.DOWNumber,
+		})
+	}
+	// Publish in a stable order: oldest survey first.
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].SurveyDate != events[j].SurveyDate {
+			return events[i].SurveyDate < events[j].SurveyDate
+		}
+		return events[i].SurveyKey < events[j].SurveyKey
+	})
+	return events
+}
@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	"fishreports/model"
+)
+
+// changedFixtureData returns the fixture data after an update: Big Lake's
+// 2015 survey was corrected, Mystery Lake's survey withdrawn, a 2022 survey
+// of Long Lake added and the IDs and flags of the other surveys reassigned.
+func changedFixtureData(t *testing.T) map[string][]model.FishData {
+	t.Helper()
+	data := newFixtureModel(t).FishDataByCounty
+
+	big := &data["Aitkin"][0].Result
+	s1 := &big.Surveys[0]
+	s1.Narrative = "Corrected walleye counts."
+	*s1.FishCatchSummaries[1].TotalCatch = 13 // WAE, after NOP
+	s1.Lengths["WAE"].FishCount = []model.FishCount{{Length: 14, Quantity: 4}, {Length: 16, Quantity: 1}, {Length: 18, Quantity: 2}, {Length: 22, Quantity: 1}}
+	delete(s1.Lengths, "NOP")
+	big.Surveys[1].SurveyID = "s2-reloaded"
+	big.Surveys[1].QualityFlags = []model.QualityFlag{{Rule: "unknown_species", Severity: model.SeverityFlag}}
+
+	long := &data["Cass"][0].Result
+	long.Surveys = append(long.Surveys, fixtureSurvey("s5", "2022-06-01",
+		map[string]int{"WAE": 7}, map[string][]model.FishCount{"WAE": {{Length: 17, Quantity: 7}}}))
+	delete(data, "Nowhere")
+	return data
+}
+
+func TestDiffDatasets(t *testing.T) {
+	from := newFixtureModel(t).FishDataByCounty
+	diff := DiffDatasets(from, changedFixtureData(t), "before", "after")
+
+	if diff.From != "before" || diff.To != "after" || diff.ComparedAt.IsZero() {
+		t.Errorf("diff labels %q, %q at %v", diff.From, diff.To, diff.ComparedAt)
+	}
+	if want := (model.DiffSummary{Added: 1, Removed: 1, Modified: 1, Unchanged: 2}); diff.Summary != want {
+		t.Errorf("summary = %+v, want %+v", diff.Summary, want)
+	}
+	if len(diff.Added) != 1 || diff.Added[0].SurveyID != "s5" || diff.Added[0].LakeName != "Long Lake" || diff.Added[0].State != model.DefaultState {
+		t.Errorf("added = %+v, want Long Lake's s5", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].SurveyKey != "99000100|2020-05-20|Standard Survey" || diff.Removed[0].CountyName != "Nowhere" {
+		t.Errorf("removed = %+v, want Mystery Lake's survey", diff.Removed)
+	}
+
+	if len(diff.Modified) != 1 || diff.Modified[0].SurveyID != "s1" {
+		t.Fatalf("modified = %+v, want s1", diff.Modified)
+	}
+	want := []model.FieldChange{
+		{Field: "narrative", Old: "", New: "Corrected walleye counts."},
+		{Field: "total_catch.WAE", Old: 12, New: 13},
+		{Field: "lengths.NOP", Old: 3, New: nil},
+		{Field: "lengths.WAE.14", Old: 3, New: 4},
+		{Field: "lengths.WAE.16", Old: nil, New: 1},
+	}
+	if !reflect.DeepEqual(diff.Modified[0].Changes, want) {
+		t.Errorf("changes = %+v, want %+v", diff.Modified[0].Changes, want)
+	}
+}
+
+func TestDiffDatasetsWithoutChanges(t *testing.T) {
+	diff := DiffDatasets(newFixtureModel(t).FishDataByCounty, newFixtureModel(t).FishDataByCounty, "a", "b")
+	if !diff.Empty() || diff.Summary.Unchanged != 4 {
+		t.Errorf("diff = %+v, want 4 unchanged surveys", diff)
+	}
+	// Empty lists encode as [] rather than null.
+	if diff.Added == nil || diff.Removed == nil || diff.Modified == nil {
+		t.Errorf("added %v, removed %v, modified %v; want empty lists", diff.Added, diff.Removed, diff.Modified)
+	}
+}
+
+func TestFingerprintDiff(t *testing.T) {
+	data := newFixtureModel(t).FishDataByCounty
+	previous := map[string]string{
+		"1000100|2015-06-10|Standard Survey":  "a",
+		"1000100|2021-07-02|Standard Survey":  "", // unknown fingerprint
+		"11000200|2019-08-15|Standard Survey": "c",
+		"5000100|2010-01-01|Standard Survey":  "x",
+	}
+	current := map[string]string{
+		"1000100|2015-06-10|Standard Survey":  "a2",
+		"1000100|2021-07-02|Standard Survey":  "b",
+		"11000200|2019-08-15|Standard Survey": "c",
+	}
+	diff := fingerprintDiff(previous, current, data, "keys", "live")
+
+	if want := (model.DiffSummary{Added: 1, Removed: 1, Modified: 1, Unchanged: 2}); diff.Summary != want {
+		t.Errorf("summary = %+v, want %+v", diff.Summary, want)
+	}
+	if diff.Added[0].SurveyID != "s4" || diff.Modified[0].SurveyID != "s1" || diff.Modified[0].Changes != nil {
+		t.Errorf("added %+v, modified %+v", diff.Added, diff.Modified)
+	}
+	removed := model.SurveyDiff{SurveyKey: "5000100|2010-01-01|Standard Survey", DOWNumber: 5000100, SurveyDate: "2010-01-01", SurveyType: "Standard Survey"}
+	if !reflect.DeepEqual(diff.Removed, []model.SurveyDiff{removed}) {
+		t.Errorf("removed = %+v, want only the key's details", diff.Removed)
+	}
+}
+
+func TestDiffEvents(t *testing.T) {
+	from, to := newFixtureModel(t).FishDataByCounty, changedFixtureData(t)
+	reconciler := newFixtureReconciler()
+	events := diffEvents(DiffDatasets(from, to, "before", "after"), from, to, fixtureSpecies(), reconciler)
+
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3: %+v", len(events), events)
+	}
+	updated, removed, added := events[0], events[1], events[2]
+	if updated.Type != model.EventSurveyUpdated || updated.SurveyID != "s1" || len(updated.Changes) != 5 {
+		t.Errorf("first event = %+v, want s1 updated with its changes", updated)
+	}
+	if removed.Type != model.EventSurveyRemoved || removed.LakeName != "Mystery Lake" || len(removed.CountyIDs) != 0 || removed.CountyIDs == nil {
+		t.Errorf("second event = %+v, want Mystery Lake removed in no known county", removed)
+	}
+	if added.Type != model.EventSurveyAdded || added.SurveyID != "s5" || !added.GameFish {
+		t.Errorf("third event = %+v, want s5 added with game fish", added)
+	}
+	counties := fixtureCounties()
+	if want := []string{counties[1].ID, counties[2].ID}; !reflect.DeepEqual(added.CountyIDs, want) {
+		t.Errorf("added county IDs = %v, want Cass and Crow Wing %v", added.CountyIDs, want)
+	}
+	if !reflect.DeepEqual(added.SpeciesCodes, []string{"WAE"}) || !reflect.DeepEqual(added.SpeciesIDs, []string{StableSpeciesID("WAE")}) {
+		t.Errorf("added species = %v, %v", added.SpeciesCodes, added.SpeciesIDs)
+	}
+
+	// Without the earlier data removed events only carry the survey key.
+	diff := fingerprintDiff(map[string]string{"5000100|2010-01-01|Standard Survey": "x"}, nil, nil, "keys", "live")
+	events = diffEvents(diff, nil, nil, fixtureSpecies(), reconciler)
+	want := model.SurveyEvent{Type: model.EventSurveyRemoved, SurveyKey: "5000100|2010-01-01|Standard Survey",
+		SurveyDate: "2010-01-01", SurveyType: "Standard Survey", DOWNumber: 5000100}
+	if !reflect.DeepEqual(events, []model.SurveyEvent{want}) {
+		t.Errorf("events = %+v, want %+v", events, want)
+	}
+}
@@ -0,0 +1,258 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"fishreports/model"
+)
+
+// Distribution time buckets.
+const (
+	DistributionByYear   = "year"
+	DistributionByDecade = "decade"
+)
+
+// ErrInvalidDistribution is returned for unsupported distribution options.
+var ErrInvalidDistribution = errors.New("invalid distribution options")
+
+// DistributionCounty is a county's lake prevalence of a species in one period.
+type DistributionCounty struct {
+	ID            string  `json:"id"`
+	CountyName    string  `json:"county_name"`
+	LakesSurveyed int     `json:"lakes_surveyed"`
+	LakesDetected int     `json:"lakes_detected"`
+	Percentage    float64 `json:"percentage"` // of the lakes surveyed in the period
+}
+
+// DistributionPeriod is the lake prevalence of a species in one year or
+// decade, statewide and per county. Only lakes surveyed in the period count.
+type DistributionPeriod struct {
+	Period        int                  `json:"period"` // the year, or the decade's first year
+	LakesSurveyed int                  `json:"lakes_surveyed"`
+	LakesDetected int                  `json:"lakes_detected"`
+	Percentage    float64              `json:"percentage"`
+	NewLakes      int                  `json:"new_lakes"` // lakes where the species was first detected in the period
+	Counties      []DistributionCounty `json:"counties"`
+}
+
+// LakeDetection records when a species was detected in a lake.
+type LakeDetection struct {
+	DOWNumber         int      `json:"dow_number"`
+	LakeName          string   `json:"lake_name"`
+	CountyIDs         []string `json:"county_ids"`
+	FirstDetected     int      `json:"first_detected"`
+	LastDetected      int      `json:"last_detected"`
+	SurveysDetected   int      `json:"surveys_detected"`
+	SurveysSinceFirst int      `json:"surveys_since_first"` // surveys of the lake from the first detection on
+}
+
+// CountyDetection records when a species was first detected in a county.
+type CountyDetection struct {
+	ID            string `json:"id"`
+	CountyName    string `json:"county_name"`
+	FirstDetected int    `json:"first_detected"`
+	LakesDetected int    `json:"lakes_detected"`
+}
+
+// distributionLake is one lake's surveyed and detected years.
+type distributionLake struct {
+	data     model.FishData
+	counties []CountyMatch
+	surveyed map[int]int // year -> surveys
+	detected map[int]int // year -> surveys that caught or measured the species
+}
+
+// GetSpeciesDistributionContext returns how a species (by ID, code or common
+// name) spread across counties over time: for each year or decade with
+// surveys, the share of the lakes surveyed whose surveys caught or measured
+// the species, statewide and per county, along with the year the species
+// was first detected in each lake and county. It returns nil for an unknown
+// species.
+func (c *FishSurveyController) GetSpeciesDistributionContext(ctx context.Context, speciesID, by string) (map[string]interface{}, error) {
+	if by == "" {
+		by = DistributionByYear
+	}
+	if by != DistributionByYear && by != DistributionByDecade {
+		return nil, fmt.Errorf("%w: by must be year or decade", ErrInvalidDistribution)
+	}
+	code := c.ResolveSpecies(speciesID)
+	if code == "" {
+		return nil, nil
+	}
+
+	lakes := make(map[int]*distributionLake)
+	fishDataByCounty, _ := c.Model.Snapshot()
+	for _, fishDataList := range fishDataByCounty {
+		for _, data := range fishDataList {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			dow := data.Result.DOWNumber
+			lake := lakes[dow]
+			if lake == nil {
+				lake = &distributionLake{
+					data:     data,
+					counties: c.Reconciler.MatchLakeCounties(data),
+					surveyed: make(map[int]int),
+					detected: make(map[int]int),
+				}
+				lakes[dow] = lake
+			}
+			for _, survey := range data.Result.Surveys {
+				year := surveyYear(survey.SurveyDate)
+				if year == 0 {
+					continue
+				}
+				lake.surveyed[year]++
+				// A species listed without fish wasn't found.
+				if surveyAbundance(survey)[code] > 0 {
+					lake.detected[year]++
+				}
+			}
+		}
+	}
+
+	bucket := func(year int) int {
+		if by == DistributionByDecade {
+			return year / 10 * 10
+		}
+		return year
+	}
+
+	periods := make(map[int]*DistributionPeriod)
+	periodCounties := make(map[int]map[string]*DistributionCounty)
+	countyFirst := make(map[string]*CountyDetection)
+	var detections []LakeDetection
+
+	for _, lake := range lakes {
+		periodSurveyed := make(map[int]bool)
+		periodDetected := make(map[int]bool)
+		for year := range lake.surveyed {
+			periodSurveyed[bucket(year)] = true
+		}
+		detection := LakeDetection{
+			DOWNumber: lake.data.Result.DOWNumber,
+			LakeName:  lake.data.Result.LakeName,
+			CountyIDs: []string{},
+		}
+		for year, surveys := range lake.detected {
+			if surveys == 0 {
+				continue
+			}
+			periodDetected[bucket(year)] = true
+			if detection.FirstDetected == 0 || year < detection.FirstDetected {
+				detection.FirstDetected = year
+			}
+			if year > detection.LastDetected {
+				detection.LastDetected = year
+			}
+			detection.SurveysDetected += surveys
+		}
+		for year, surveys := range lake.surveyed {
+			if detection.FirstDetected != 0 && year >= detection.FirstDetected {
+				detection.SurveysSinceFirst += surveys
+			}
+		}
+
+		for period := range periodSurveyed {
+			p := periods[period]
+			if p == nil {
+				p = &DistributionPeriod{Period: period}
+				periods[period] = p
+				periodCounties[period] = make(map[string]*DistributionCounty)
+			}
+			p.LakesSurveyed++
+			if periodDetected[period] {
+				p.LakesDetected++
+			}
+			if detection.FirstDetected != 0 && bucket(detection.FirstDetected) == period {
+				p.NewLakes++
+			}
+			for _, match := range lake.counties {
+				if !match.Matched() {
+					continue
+				}
+				county := periodCounties[period][match.CountyID]
+				if county == nil {
+					county = &DistributionCounty{ID: match.CountyID, CountyName: match.CountyName}
+					periodCounties[period][match.CountyID] = county
+				}
+				county.LakesSurveyed++
+				if periodDetected[period] {
+					county.LakesDetected++
+				}
+			}
+		}
+
+		if detection.FirstDetected == 0 {
+			continue
+		}
+		for _, match := range lake.counties {
+			if !match.Matched() {
+				continue
+			}
+			detection.CountyIDs = append(detection.CountyIDs, match.CountyID)
+			county := countyFirst[match.CountyID]
+			if county == nil {
+				county = &CountyDetection{ID: match.CountyID, CountyName: match.CountyName, FirstDetected: detection.FirstDetected}
+				countyFirst[match.CountyID] = county
+			}
+			if detection.FirstDetected < county.FirstDetected {
+				county.FirstDetected = detection.FirstDetected
+			}
+			county.LakesDetected++
+		}
+		detections = append(detections, detection)
+	}
+
+	timeline := make([]DistributionPeriod, 0, len(periods))
+	for period, p := range periods {
+		p.Percentage = roundTo(percentOf(p.LakesDetected, p.LakesSurveyed), 1)
+		p.Counties = make([]DistributionCounty, 0, len(periodCounties[period]))
+		for _, county := range periodCounties[period] {
+			county.Percentage = roundTo(percentOf(county.LakesDetected, county.LakesSurveyed), 1)
+			p.Counties = append(p.Counties, *county)
+		}
+		sort.Slice(p.Counties, func(i, j int) bool {
+			return p.Counties[i].ID < p.Counties[j].ID
+		})
+		timeline = append(timeline, *p)
+	}
+	sort.Slice(timeline, func(i, j int) bool {
+		return timeline[i].Period < timeline[j].Period
+	})
+
+	counties := make([]CountyDetection, 0, len(countyFirst))
+	for _, county := range countyFirst {
+		counties = append(counties, *county)
+	}
+	sort.Slice(counties, func(i, j int) bool {
+		if counties[i].FirstDetected != counties[j].FirstDetected {
+			return counties[i].FirstDetected < counties[j].FirstDetected
+		}
+		return counties[i].ID < counties[j].ID
+	})
+	if detections == nil {
+		detections = []LakeDetection{}
+	}
+	sort.Slice(detections, func(i, j int) bool {
+		if detections[i].FirstDetected != detections[j].FirstDetected {
+			return detections[i].FirstDetected < detections[j].FirstDetected
+		}
+		return detections[i].DOWNumber < detections[j].DOWNumber
+	})
+
+	species := c.Model.Species()[code]
+	return map[string]interface{}{
+		"species":    species.CommonName,
+		"species_id": species.ID,
+		"code":       code,
+		"by":         by,
+		"periods":    timeline,
+		"counties":   counties,
+		"lakes":      detections,
+	}, nil
+}
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestDistributionDetectsFishOnly(t *testing.T) {
+	c := NewFishSurveyController(newFixtureModel(t))
+	c.Reconciler = newFixtureReconciler()
+
+	// Long Lake's 2019 survey lists northern pike without any fish.
+	result, err := c.GetSpeciesDistributionContext(context.Background(), "NOP", DistributionByYear)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lakes := result["lakes"].([]LakeDetection)
+	if len(lakes) != 1 || lakes[0].LakeName != "Big Lake" || lakes[0].FirstDetected != 2015 || lakes[0].SurveysDetected != 1 {
+		t.Errorf("lakes = %+v, want only Big Lake from 2015", lakes)
+	}
+	var detected []int
+	for _, period := range result["periods"].([]DistributionPeriod) {
+		detected = append(detected, period.LakesDetected)
+	}
+	// 2015, 2019, 2020 and 2021 each had one lake surveyed.
+	if want := []int{1, 0, 0, 0}; !reflect.DeepEqual(detected, want) {
+		t.Errorf("lakes detected per year = %v, want %v", detected, want)
+	}
+
+	// A catch without length data is a detection.
+	delete(c.Model.FishDataByCounty["Cass"][0].Result.Surveys[0].Lengths, "BLG")
+	result, err = c.GetSpeciesDistributionContext(context.Background(), "BLG", DistributionByDecade)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lakes := result["lakes"].([]LakeDetection); len(lakes) != 1 || lakes[0].LakeName != "Long Lake" || lakes[0].FirstDetected != 2019 {
+		t.Errorf("bluegill lakes = %+v, want Long Lake from 2019", lakes)
+	}
+}
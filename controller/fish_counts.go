@@ -1,32 +1,44 @@
 package controller
 
 import (
-	"log"
+	"context"
 	"strconv"
 )
 
 // GetFishCountData retrieves fish count data based on the provided DOW, species name, and survey date.
 func (c *FishSurveyController) GetFishCountData(dowStr, speciesName, surveyDate string) map[string]interface{} {
-	log.Printf("🔍 GetFishCountData called with dowStr=%s, species=%s, surveyDate=%s", dowStr, speciesName, surveyDate)
+	graphData, _ := c.GetFishCountDataContext(context.Background(), dowStr, speciesName, surveyDate)
+	return graphData
+}
 
+// GetFishCountDataContext is GetFishCountData with a context. The scan stops
+// and returns the context's error once it is cancelled or its deadline passes.
+func (c *FishSurveyController) GetFishCountDataContext(ctx context.Context, dowStr, speciesName, surveyDate string) (map[string]interface{}, error) {
+	return c.GetFishCountDataWithOptions(ctx, dowStr, speciesName, surveyDate, DefaultHistogramOptions())
+}
+
+// GetFishCountDataWithOptions is GetFishCountDataContext with the length
+// histogram binned and reported as opts asks.
+func (c *FishSurveyController) GetFishCountDataWithOptions(ctx context.Context, dowStr, speciesName, surveyDate string, opts HistogramOptions) (map[string]interface{}, error) {
 	// Convert DOW to integer
 	dow, err := strconv.Atoi(dowStr)
 	if err != nil {
-		log.Printf("❌ Invalid DOW number: %s", dowStr)
-		return nil
+		return nil, nil
 	}
 
 	// Normalize species name to abbreviation
 	speciesAbbr := c.NormalizeSpecies(speciesName)
-	log.Printf("✅ Normalized species '%s' to abbreviation '%s'", speciesName, speciesAbbr)
 	if speciesAbbr == "" {
-		log.Printf("❌ Species not found in SpeciesMap")
-		return nil
+		return nil, nil
 	}
 
 	// Iterate through fish data
-	for _, fishDataList := range c.Model.FishDataByCounty {
+	fishDataByCounty, _ := c.Model.Snapshot()
+	for _, fishDataList := range fishDataByCounty {
 		for _, data := range fishDataList {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
 			if data.Result.DOWNumber != dow {
 				continue
 			}
@@ -40,30 +52,23 @@ func (c *FishSurveyController) GetFishCountData(dowStr, speciesName, surveyDate
 
 				// Retrieve length data for the requested species
 				lengthData, exists := survey.Lengths[speciesAbbr]
-				if !exists {
-					log.Printf("❌ No length data found for species: %s", speciesName)
-					return nil
-				}
-
-				// Convert fish count to a structured response.
-				fishCounts := make([]map[string]int, len(lengthData.FishCount))
-				for i, count := range lengthData.FishCount {
-					fishCounts[i] = map[string]int{
-						"length":   count.Length,
-						"quantity": count.Quantity,
-					}
+				if !exists || lengthData == nil {
+					return nil, nil
 				}
 
+				// Bin the fish counts into the requested histogram.
+				counts := make(map[int]int, len(lengthData.FishCount))
+				histogramCounts(counts, lengthData)
 
 				return map[string]interface{}{
 					"species":    speciesName,
 					"surveyDate": surveyDate,
-					"data":       fishCounts,
-				}
+					"histogram":  opts,
+					"data":       BuildHistogram(counts, opts),
+				}, nil
 			}
 		}
 	}
 
-	log.Printf("❌ No data found for given filters")
-	return nil
+	return nil, nil
 }
\ No newline at end of file
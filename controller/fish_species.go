@@ -1,18 +1,19 @@
 package controller
 
 import (
-	"log"
+	"context"
+	"fishreports/model"
+	"math"
 	"sort"
 	"strconv"
 	"strings"
-	"math"
 )
 
 func (c *FishSurveyController) GetAllSpecies() []map[string]string {
     var speciesList []map[string]string
 
     // Iterate through the species map.
-    for abbr, species := range c.Model.SpeciesMap {
+    for abbr, species := range c.Model.Species() {
         // Only include species if survey data exists for it.
         if !c.HasSurveyDataForSpecies(abbr) {
             continue
@@ -23,6 +24,8 @@ func (c *FishSurveyController) GetAllSpecies() []map[string]string {
             "image_url":      species.ImageURL,
             "description":    species.Description,
             "game_fish":      strconv.FormatBool(species.GameFish),
+            "invasive":       strconv.FormatBool(species.HasStatus(model.StatusInvasive)),
+            "status":         strings.Join(species.Status, ","),
             "ScientificName": species.ScientificName,
             "SpeciesGroup":   species.SpeciesGroup,
         })
@@ -39,45 +42,66 @@ func (c *FishSurveyController) GetAllSpecies() []map[string]string {
 
 // GetSpeciesStats aggregates statistics for a given species (by common name)
 // across all surveys and returns county stats with integer percentages.
+// Materialized aggregates are used when available; otherwise the stats are
+// computed on the fly.
 func (c *FishSurveyController) GetSpeciesStats(commonName string) map[string]interface{} {
+	stats, _ := c.GetSpeciesStatsContext(context.Background(), commonName)
+	return stats
+}
+
+// GetSpeciesStatsContext is GetSpeciesStats with a context. The on-the-fly
+// scan stops and returns the context's error once it is cancelled or its
+// deadline passes.
+func (c *FishSurveyController) GetSpeciesStatsContext(ctx context.Context, commonName string) (map[string]interface{}, error) {
+	return c.speciesStats(ctx, commonName, DefaultHistogramOptions())
+}
+
+// speciesStats builds the species stats with graph_data binned as opts asks.
+func (c *FishSurveyController) speciesStats(ctx context.Context, commonName string, opts HistogramOptions) (map[string]interface{}, error) {
 	speciesAbbr := c.NormalizeSpecies(commonName)
 	if speciesAbbr == "" {
-		return nil
+		return nil, nil
+	}
+
+	fishDataByCounty, agg := c.Model.Snapshot()
+	if agg != nil {
+		sa := agg.Species[speciesAbbr]
+		if sa == nil {
+			sa = newSpeciesAggregate(speciesAbbr)
+		}
+		return buildSpeciesStats(commonName, sa, agg.AllLakes, agg.AllLakesByCounty, opts, c.Reconciler), nil
 	}
+	return computeSpeciesStats(ctx, fishDataByCounty, commonName, speciesAbbr, opts, c.Reconciler)
+}
 
-	var (
-		totalLengthSum int
-		totalQuantity  int
-		biggestLength  int
-		shortestLength int = 1<<31 - 1 // max int value
-	)
+// computeSpeciesStats walks every survey to build the stats for one species.
+func computeSpeciesStats(ctx context.Context, fishDataByCounty map[string][]model.FishData, commonName, speciesAbbr string, opts HistogramOptions, reconciler *CountyReconciler) (map[string]interface{}, error) {
+	sa := newSpeciesAggregate(speciesAbbr)
 
 	// Global sets for lakes (for overall stats).
-	lakesWithSpecies := make(map[string]bool)
 	allLakes := make(map[string]bool)
-
-	// Map to aggregate quantities by fish length for graph data.
-	graphMap := make(map[int]int)
-
-	// Maps to track lakes per county.
 	allLakesByCounty := make(map[string]map[string]bool)
-	speciesLakesByCounty := make(map[string]map[string]bool)
 
 	// Iterate over all fish data by county.
-	for _, fishDataList := range c.Model.FishDataByCounty {
+	for _, fishDataList := range fishDataByCounty {
 		for _, data := range fishDataList {
-			// Normalize the county name from the fish data.
-			normalizedFishCounty := NormalizeCountyName(data.Result.CountyName)
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			// Normalized names of every county the lake lies in.
+			lakeCounties := reconciler.countyKeys(data)
 			lakeName := data.Result.LakeName
 
 			// Record this lake in the overall set.
 			allLakes[strings.ToLower(lakeName)] = true
 
-			// Use normalized county name as key for aggregation.
-			if allLakesByCounty[normalizedFishCounty] == nil {
-				allLakesByCounty[normalizedFishCounty] = make(map[string]bool)
+			// Use normalized county names as keys for aggregation.
+			for _, normalizedFishCounty := range lakeCounties {
+				if allLakesByCounty[normalizedFishCounty] == nil {
+					allLakesByCounty[normalizedFishCounty] = make(map[string]bool)
+				}
+				allLakesByCounty[normalizedFishCounty][lakeName] = true
 			}
-			allLakesByCounty[normalizedFishCounty][lakeName] = true
 
 			// Check if this survey contains data for the species.
 			for _, survey := range data.Result.Surveys {
@@ -85,41 +109,22 @@ func (c *FishSurveyController) GetSpeciesStats(commonName string) map[string]int
 				if !exists {
 					continue
 				}
-
-				// Mark the lake as having the species.
-				lakesWithSpecies[strings.ToLower(lakeName)] = true
-
-				if speciesLakesByCounty[normalizedFishCounty] == nil {
-					speciesLakesByCounty[normalizedFishCounty] = make(map[string]bool)
-				}
-				speciesLakesByCounty[normalizedFishCounty][lakeName] = true
-
-				// Process each fish count entry.
-				for _, count := range lengthData.FishCount {
-					graphMap[count.Length] += count.Quantity
-					totalLengthSum += count.Length * count.Quantity
-					totalQuantity += count.Quantity
-
-					if count.Length > biggestLength {
-						biggestLength = count.Length
-					}
-					if count.Length < shortestLength {
-						shortestLength = count.Length
-					}
-				}
+				accumulateSpecies(sa, lakeCounties, lakeName, lengthData)
 			}
 		}
 	}
 
-	// Debug: Log all aggregated fish data county keys.
-	for key := range allLakesByCounty {
-		log.Printf("Aggregated fish data county key: '%s'", key)
-	}
+	return buildSpeciesStats(commonName, sa, allLakes, allLakesByCounty, opts, reconciler), nil
+}
+
+// buildSpeciesStats formats a species rollup into the /species/id/:species_id response.
+func buildSpeciesStats(commonName string, sa *model.SpeciesAggregate, allLakes map[string]bool, allLakesByCounty map[string]map[string]bool, opts HistogramOptions, reconciler *CountyReconciler) map[string]interface{} {
+	shortestLength := sa.ShortestLength
 
 	// Calculate weighted average length.
 	avgLength := 0.0
-	if totalQuantity > 0 {
-		avgLength = float64(totalLengthSum) / float64(totalQuantity)
+	if sa.TotalQuantity > 0 {
+		avgLength = float64(sa.TotalLengthSum) / float64(sa.TotalQuantity)
 	} else {
 		shortestLength = 0
 	}
@@ -127,21 +132,11 @@ func (c *FishSurveyController) GetSpeciesStats(commonName string) map[string]int
 	// Calculate overall percentage of lakes with the species as an int.
 	overallPercent := 0
 	if len(allLakes) > 0 {
-		overallPercent = int(math.Round((float64(len(lakesWithSpecies)) / float64(len(allLakes))) * 100))
+		overallPercent = int(math.Round((float64(len(sa.Lakes)) / float64(len(allLakes))) * 100))
 	}
 
-	// Convert aggregated graphMap to a slice of maps.
-	aggregatedGraphData := []map[string]int{}
-	for length, quantity := range graphMap {
-		aggregatedGraphData = append(aggregatedGraphData, map[string]int{
-			"length":   length,
-			"quantity": quantity,
-		})
-	}
-
-	sort.Slice(aggregatedGraphData, func(i, j int) bool {
-		return aggregatedGraphData[i]["length"] < aggregatedGraphData[j]["length"]
-	})
+	// Bin the aggregated histogram.
+	aggregatedGraphData := BuildHistogram(sa.Histogram, opts)
 
 	// Build county stats: for each normalized county, compute the percentage of lakes with the species and include the county ID.
 	var countyStats []map[string]interface{}
@@ -149,56 +144,74 @@ func (c *FishSurveyController) GetSpeciesStats(commonName string) map[string]int
 		totalLakes := len(allLakesSet)
 		percentage := 0
 		if totalLakes > 0 {
-			speciesLakesCount := 0
-			if speciesLakesByCounty[normalizedCounty] != nil {
-				speciesLakesCount = len(speciesLakesByCounty[normalizedCounty])
-			}
+			speciesLakesCount := len(sa.LakesByCounty[normalizedCounty])
 			percentage = int(math.Round((float64(speciesLakesCount) / float64(totalLakes)) * 100))
 		}
 		countyStats = append(countyStats, map[string]interface{}{
-			"id":         getCountyID(normalizedCounty), // pass normalized key
+			"id":         reconciler.CountyIDByKey(normalizedCounty), // state-qualified normalized key
 			"percentage": percentage,
 		})
 	}
 
 	sort.Slice(countyStats, func(i, j int) bool {
-		return countyStats[i]["id"].(string) < countyStats[j]["id"].(string)
+		idI, _ := countyStats[i]["id"].(string)
+		idJ, _ := countyStats[j]["id"].(string)
+		return idI < idJ
 	})
 
 	return map[string]interface{}{
 		"species":         commonName,
 		"percent_lakes":   overallPercent,
 		"average_length":  avgLength,
-		"biggest_length":  biggestLength,
+		"biggest_length":  sa.BiggestLength,
 		"shortest_length": shortestLength,
 		"graph_data":      aggregatedGraphData,
-		"total_fish":      totalQuantity,
+		"histogram":       opts,
+		"total_fish":      sa.TotalQuantity,
 		"counties":        countyStats,
 	}
 }
 
 // GetSpeciesStatsByID finds the species by its ID and returns the aggregated stats.
 func (c *FishSurveyController) GetSpeciesStatsByID(speciesID string) map[string]interface{} {
+    stats, _ := c.GetSpeciesStatsByIDContext(context.Background(), speciesID)
+    return stats
+}
+
+// GetSpeciesStatsByIDContext is GetSpeciesStatsByID with a context.
+func (c *FishSurveyController) GetSpeciesStatsByIDContext(ctx context.Context, speciesID string) (map[string]interface{}, error) {
+    return c.GetSpeciesStatsByIDWithOptions(ctx, speciesID, DefaultHistogramOptions())
+}
+
+// GetSpeciesStatsByIDWithOptions is GetSpeciesStatsByIDContext with graph_data
+// binned and reported as opts asks.
+func (c *FishSurveyController) GetSpeciesStatsByIDWithOptions(ctx context.Context, speciesID string, opts HistogramOptions) (map[string]interface{}, error) {
     var speciesKey string
+    speciesMap := c.Model.Species()
     // Iterate over the species map (which is keyed by species code)
-    for key, sp := range c.Model.SpeciesMap {
+    for key, sp := range speciesMap {
         if sp.ID == speciesID {
             speciesKey = key
             break
         }
     }
     if speciesKey == "" {
-        return nil
+        return nil, nil
     }
     // Retrieve the species using the found key.
-    species := c.Model.SpeciesMap[speciesKey]
-    // Now call the existing GetSpeciesStats using the species common name.
-    return c.GetSpeciesStats(species.CommonName)
+    species := speciesMap[speciesKey]
+    // Now build the stats using the species common name.
+    return c.speciesStats(ctx, species.CommonName, opts)
 }
 
 // HasSurveyDataForSpecies checks if any survey contains data for the given species abbreviation.
 func (c *FishSurveyController) HasSurveyDataForSpecies(speciesAbbr string) bool {
-    for _, fishDataList := range c.Model.FishDataByCounty {
+    fishDataByCounty, agg := c.Model.Snapshot()
+    if agg != nil {
+        _, exists := agg.Species[speciesAbbr]
+        return exists
+    }
+    for _, fishDataList := range fishDataByCounty {
         for _, data := range fishDataList {
             // Iterate through each survey in the county.
             for _, survey := range data.Result.Surveys {
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"context"
 	"fishreports/model"
 	"fishreports/utils"
 	"sort"
@@ -11,18 +12,31 @@ import (
 // FishSurveyController provides methods for filtering, sorting,
 // and paginating fish survey data.
 type FishSurveyController struct {
-	Model *model.FishSurveyModel
+	Model           *model.FishSurveyModel
+	States          []StateDataset          // configured state datasets
+	Reconciler      *CountyReconciler       // matches survey county names to counties
+	Validation      *Validator              // rules every survey file and ingested document is run through
+	Rankings        RankingSettings         // weights and windows of the lake rankings
+	AgeLengthKeys   map[string]AgeLengthKey // by species code, for aging cohorts
+	SpeciesStatuses map[string][]string     // status categories by species code
 }
 
-// NewFishSurveyController creates a new instance of FishSurveyController.
+// NewFishSurveyController creates a new instance of FishSurveyController
+// with the default settings.
 func NewFishSurveyController(model *model.FishSurveyModel) *FishSurveyController {
-	return &FishSurveyController{Model: model}
+	return &FishSurveyController{
+		Model:      model,
+		States:     []StateDataset{DefaultStateDataset("data/minnesota_counties.json", "data/surveys")},
+		Reconciler: NewCountyReconciler(DefaultCountyAliases(), 0.8),
+		Validation: NewValidator(DefaultValidationRules()...),
+		Rankings:   DefaultRankingSettings(),
+	}
 }
 
 // NormalizeSpecies converts a common species name to its abbreviation.
 func (c *FishSurveyController) NormalizeSpecies(commonName string) string {
 	commonName = strings.ToLower(commonName)
-	for code, species := range c.Model.SpeciesMap {
+	for code, species := range c.Model.Species() {
 		if strings.ToLower(species.CommonName) == commonName {
 			return code
 		}
@@ -30,6 +44,20 @@ func (c *FishSurveyController) NormalizeSpecies(commonName string) string {
 	return ""
 }
 
+// ResolveSpecies returns the code of a species given by common name, code or
+// ID, or "" when none matches.
+func (c *FishSurveyController) ResolveSpecies(name string) string {
+	if code := c.NormalizeSpecies(name); code != "" {
+		return code
+	}
+	for code, species := range c.Model.Species() {
+		if strings.EqualFold(code, name) || species.ID == name {
+			return code
+		}
+	}
+	return ""
+}
+
 // FilterAndSortData is the entry point for filtering, sorting, and paginating fish survey data.
 func (c *FishSurveyController) FilterAndSortData(
 	// Now, species and counties are slices of IDs.
@@ -42,6 +70,25 @@ func (c *FishSurveyController) FilterAndSortData(
 	search string,
 	limit, page int,
 ) map[string]interface{} {
+	result, _ := c.FilterAndSortDataContext(context.Background(), species, minYear, maxYear, counties, lakes, sortBy, order, gameFishOnly, false, search, limit, page)
+	return result
+}
+
+// FilterAndSortDataContext is FilterAndSortData with a context. The scan stops
+// and returns the context's error once it is cancelled or its deadline passes.
+// invasiveOnly keeps only species listed as invasive.
+func (c *FishSurveyController) FilterAndSortDataContext(
+	ctx context.Context,
+	species []string,
+	minYear, maxYear string,
+	counties []string,
+	lakes []string,
+	sortBy, order string,
+	gameFishOnly bool,
+	invasiveOnly bool,
+	search string,
+	limit, page int,
+) (map[string]interface{}, error) {
 	var result []map[string]interface{}
 
 	// Build lookup sets for counties and lakes.
@@ -68,13 +115,17 @@ func (c *FishSurveyController) FilterAndSortData(
 	}
 
 	// Iterate through each county’s fish data.
-	for key, fishDataList := range c.Model.FishDataByCounty {
-		// Convert the county name (key) into its ID.
-		countyID := strings.ToLower(getCountyID(key))
-		if len(counties) > 0 && !countySet[countyID] {
-			continue
-		}
+	fishDataByCounty, _ := c.Model.Snapshot()
+	speciesMap := c.Model.Species()
+	for _, fishDataList := range fishDataByCounty {
 		for _, data := range fishDataList {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			// Filter by the IDs of the counties the lake is matched to.
+			if len(counties) > 0 && !anyInSet(c.Reconciler.lakeCountyIDs(data), countySet) {
+				continue
+			}
 			// Filter by lake.
 			if len(lakes) > 0 && !lakeSet[strings.ToLower(data.Result.LakeName)] {
 				continue
@@ -83,7 +134,7 @@ func (c *FishSurveyController) FilterAndSortData(
 				continue
 			}
 			for _, survey := range data.Result.Surveys {
-				rows := c.processSurvey(data, survey, speciesSet, minYearInt, maxYearInt, gameFishOnly, search)
+				rows := c.processSurvey(data, survey, speciesMap, speciesSet, minYearInt, maxYearInt, gameFishOnly, invasiveOnly, search)
 				result = append(result, rows...)
 			}
 		}
@@ -95,6 +146,12 @@ func (c *FishSurveyController) FilterAndSortData(
 		order = "desc"
 	}
 	sortRows(result, sortBy, order)
+	if limit < 1 {
+		limit = 1
+	}
+	if page < 1 {
+		page = 1
+	}
 	paginatedData, prevPage, nextPage := paginate(result, limit, page)
 
 	return map[string]interface{}{
@@ -104,7 +161,7 @@ func (c *FishSurveyController) FilterAndSortData(
 		"prev_page": prevPage,
 		"next_page": nextPage,
 		"total":     len(result),
-	}
+	}, nil
 }
 
 
@@ -120,9 +177,11 @@ func parseMinYear(minYear string) int {
 func (c *FishSurveyController) processSurvey(
 	data model.FishData,
 	survey model.Survey,
+	speciesMap map[string]model.Species,
 	speciesSet map[string]bool, // species filter set of IDs (already lowercased)
 	minYearInt, maxYearInt int,
 	gameFishOnly bool,
+	invasiveOnly bool,
 	search string,
 ) []map[string]interface{} {
 	var rows []map[string]interface{}
@@ -140,23 +199,33 @@ func (c *FishSurveyController) processSurvey(
 	}
 
 	for abbreviation, lengthData := range survey.Lengths {
-		// Ensure species is set.
-		if lengthData.Species == nil {
-			if speciesObj, exists := c.Model.SpeciesMap[abbreviation]; exists {
-				lengthData.Species = &speciesObj
-			} else {
+		// Skip malformed entries whose length data is null.
+		if lengthData == nil {
+			continue
+		}
+		// Resolve the species without mutating the shared length data, which
+		// concurrent requests read at the same time.
+		species := lengthData.Species
+		if species == nil {
+			speciesObj, exists := speciesMap[abbreviation]
+			if !exists {
 				continue
 			}
+			species = &speciesObj
 		}
 
 		// If gameFishOnly is true, skip non-game fish.
-		if gameFishOnly && !lengthData.Species.GameFish {
+		if gameFishOnly && !species.GameFish {
+			continue
+		}
+		// Statuses come from the catalog, which the embedded species may predate.
+		if invasiveOnly && !speciesMap[abbreviation].HasStatus(model.StatusInvasive) {
 			continue
 		}
 
 		// If a species filter is applied, compare the species ID using case-insensitive match.
 		if len(speciesSet) > 0 {
-			speciesID := lengthData.Species.ID
+			speciesID := species.ID
 			matched := false
 			for filterID := range speciesSet {
 				if strings.EqualFold(filterID, speciesID) {
@@ -169,21 +238,19 @@ func (c *FishSurveyController) processSurvey(
 			}
 		}
 
-		imageURL := ""
-		if lengthData.Species != nil {
-			imageURL = lengthData.Species.ImageURL
-		}
+		imageURL := species.ImageURL
 
 		// Build the row.
 		row := map[string]interface{}{
 			"surveyID":        survey.SurveyID,
 			"dow_number":      data.Result.DOWNumber,
+			"state":           model.LakeState(data),
 			"survey_type":     survey.SurveyType,
 			"survey_sub_type": survey.SurveySubType,
 			"county_name":     data.Result.CountyName,
 			"lake_name":       data.Result.LakeName,
 			"survey_date":     survey.SurveyDate,
-			"species_name":    lengthData.Species.CommonName,
+			"species_name":    species.CommonName,
 			"image_url":       imageURL,
 			"narrative":       survey.Narrative,
 			"min_length":      lengthData.MinimumLength,
@@ -201,13 +268,14 @@ func (c *FishSurveyController) processSurvey(
 			}
 		}
 		row["total_catch"] = totalCatch
+		row["quality_flags"] = rowQualityFlags(survey, abbreviation)
 
 		// Apply search filter if provided.
 		if search != "" {
 			lowerSearch := strings.ToLower(search)
-			if !(strings.Contains(strings.ToLower(row["species_name"].(string)), lowerSearch) ||
-				strings.Contains(strings.ToLower(row["county_name"].(string)), lowerSearch) ||
-				strings.Contains(strings.ToLower(row["lake_name"].(string)), lowerSearch)) {
+			if !(strings.Contains(strings.ToLower(species.CommonName), lowerSearch) ||
+				strings.Contains(strings.ToLower(data.Result.CountyName), lowerSearch) ||
+				strings.Contains(strings.ToLower(data.Result.LakeName), lowerSearch)) {
 				continue
 			}
 		}
@@ -217,6 +285,18 @@ func (c *FishSurveyController) processSurvey(
 	return rows
 }
 
+// rowQualityFlags returns the survey's quality flags that apply to one
+// species row: survey-wide flags and flags for that species.
+func rowQualityFlags(survey model.Survey, abbreviation string) []model.QualityFlag {
+	flags := []model.QualityFlag{}
+	for _, flag := range survey.QualityFlags {
+		if flag.Species == "" || flag.Species == abbreviation {
+			flags = append(flags, flag)
+		}
+	}
+	return flags
+}
+
 // sortRows sorts the rows based on the provided field and order.
 func sortRows(rows []map[string]interface{}, sortBy, order string) {
 	sort.Slice(rows, func(i, j int) bool {
@@ -263,7 +343,14 @@ func sortRows(rows []map[string]interface{}, sortBy, order string) {
 }
 
 // paginate returns the slice of rows for the requested page along with previous and next page numbers.
+// Limits and pages below 1 are treated as 1.
 func paginate(rows []map[string]interface{}, limit, page int) ([]map[string]interface{}, int, int) {
+	limit = max(limit, 1)
+	page = max(page, 1)
+	// Checked before multiplying so huge pages can't overflow.
+	if page-1 > len(rows)/limit {
+		return []map[string]interface{}{}, max(page-1, 1), page
+	}
 	startIndex := (page - 1) * limit
 	if startIndex >= len(rows) {
 		return []map[string]interface{}{}, max(page-1, 1), page
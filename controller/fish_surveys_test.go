@@ -0,0 +1,35 @@
+package controller
+
+import "testing"
+
+func TestPaginate(t *testing.T) {
+	rows := make([]map[string]interface{}, 5)
+	for i := range rows {
+		rows[i] = map[string]interface{}{"n": i}
+	}
+	tests := []struct {
+		limit, page        int
+		first, count       int
+		prevPage, nextPage int
+	}{
+		{2, 1, 0, 2, 1, 2},
+		{2, 3, 4, 1, 2, 4},
+		{2, 4, 0, 0, 3, 4},
+		{2, 0, 0, 2, 1, 2},
+		{2, -3, 0, 2, 1, 2},
+		{0, 1, 0, 1, 1, 2},
+		{-1, 2, 1, 1, 1, 3},
+		{10, 1, 0, 5, 1, 2},
+		{int(^uint(0) >> 1), 1, 0, 5, 1, 2},
+		{3, int(^uint(0) >> 1), 0, 0, int(^uint(0)>>1) - 1, int(^uint(0) >> 1)},
+	}
+	for _, tt := range tests {
+		page, prevPage, nextPage := paginate(rows, tt.limit, tt.page)
+		if len(page) != tt.count || (tt.count > 0 && page[0]["n"] != tt.first) {
+			t.Errorf("paginate(limit %d, page %d) = %v, want %d rows from %d", tt.limit, tt.page, page, tt.count, tt.first)
+		}
+		if prevPage != tt.prevPage || nextPage != tt.nextPage {
+			t.Errorf("paginate(limit %d, page %d) pages = %d, %d; want %d, %d", tt.limit, tt.page, prevPage, nextPage, tt.prevPage, tt.nextPage)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"fishreports/model"
+)
+
+// Histogram modes.
+const (
+	HistogramCount      = "count"
+	HistogramPercent    = "percent"
+	HistogramCumulative = "cumulative"
+)
+
+// histogramUnits converts the survey lengths into each supported unit. The
+// scraped survey data carries no length unit; its lengths are taken to be
+// whole inches, as in the DNR's published length tables, so survey adapters
+// for agencies measuring in other units must convert to inches.
+var histogramUnits = map[string]float64{
+	"in": 1,
+	"cm": 2.54,
+	"mm": 25.4,
+}
+
+// ErrInvalidHistogram is returned for unsupported histogram options.
+var ErrInvalidHistogram = errors.New("invalid histogram options")
+
+// HistogramOptions controls how length counts are binned and reported.
+type HistogramOptions struct {
+	Bin     int    `json:"bin"`     // bin width in Unit: 1, 2 or 5
+	Unit    string `json:"unit"`    // "in", "cm" or "mm"
+	Mode    string `json:"mode"`    // HistogramCount, HistogramPercent or HistogramCumulative
+	Density bool   `json:"density"` // also report each bin's share of the fish per unit of length
+}
+
+// DefaultHistogramOptions returns 1-inch bins of fish counts, the lengths as
+// scraped.
+func DefaultHistogramOptions() HistogramOptions {
+	return HistogramOptions{Bin: 1, Unit: "in", Mode: HistogramCount}
+}
+
+// ParseHistogramOptions reads the bin, unit, mode and density query
+// parameters. Empty values keep the defaults.
+func ParseHistogramOptions(bin, unit, mode, density string) (HistogramOptions, error) {
+	opts := DefaultHistogramOptions()
+	if bin != "" {
+		width, err := strconv.Atoi(bin)
+		if err != nil || (width != 1 && width != 2 && width != 5) {
+			return opts, fmt.Errorf("%w: bin must be 1, 2 or 5", ErrInvalidHistogram)
+		}
+		opts.Bin = width
+	}
+	if unit != "" {
+		if _, exists := histogramUnits[unit]; !exists {
+			return opts, fmt.Errorf("%w: unit must be in, cm or mm", ErrInvalidHistogram)
+		}
+		opts.Unit = unit
+	}
+	if mode != "" {
+		if mode != HistogramCount && mode != HistogramPercent && mode != HistogramCumulative {
+			return opts, fmt.Errorf("%w: mode must be count, percent or cumulative", ErrInvalidHistogram)
+		}
+		opts.Mode = mode
+	}
+	if density != "" {
+		enabled, err := strconv.ParseBool(density)
+		if err != nil {
+			return opts, fmt.Errorf("%w: density must be true or false", ErrInvalidHistogram)
+		}
+		opts.Density = enabled
+	}
+	return opts, nil
+}
+
+// HistogramBin is one bin of a length histogram. Length is the bin's lower
+// edge in the histogram's unit and Quantity the fish in the bin. Value is the
+// quantity, the percentage of all fish, or the fish in this and every shorter
+// bin, depending on the mode. Density is the bin's share of the fish divided
+// by the bin width, so the densities integrate to 1.
+type HistogramBin struct {
+	Length   int      `json:"length"`
+	Quantity int      `json:"quantity"`
+	Value    float64  `json:"value"`
+	Density  *float64 `json:"density,omitempty"`
+}
+
+// BuildHistogram bins fish counts keyed by length in inches. A fish of length
+// L inches is counted in the bin holding L converted to the unit. Bins without
+// fish are left out, and the bins are sorted by length.
+func BuildHistogram(counts map[int]int, opts HistogramOptions) []HistogramBin {
+	width := opts.Bin
+	if width <= 0 {
+		width = 1
+	}
+
+	binned := make(map[int]int)
+	total := 0
+	for length, quantity := range counts {
+		// The small epsilon keeps exact conversions such as 5 in = 127 mm from
+		// falling into the bin below through floating point error.
+		converted := convertLength(float64(length), opts.Unit) + 1e-9
+		edge := int(math.Floor(converted/float64(width))) * width
+		binned[edge] += quantity
+		total += quantity
+	}
+
+	bins := make([]HistogramBin, 0, len(binned))
+	for edge, quantity := range binned {
+		bins = append(bins, HistogramBin{Length: edge, Quantity: quantity})
+	}
+	sort.Slice(bins, func(i, j int) bool {
+		return bins[i].Length < bins[j].Length
+	})
+
+	running := 0
+	for i := range bins {
+		running += bins[i].Quantity
+		switch opts.Mode {
+		case HistogramPercent:
+			bins[i].Value = roundTo(percentOf(bins[i].Quantity, total), 2)
+		case HistogramCumulative:
+			bins[i].Value = float64(running)
+		default:
+			bins[i].Value = float64(bins[i].Quantity)
+		}
+		if opts.Density {
+			density := 0.0
+			if total > 0 {
+				density = roundTo(float64(bins[i].Quantity)/float64(total*width), 6)
+			}
+			bins[i].Density = &density
+		}
+	}
+	return bins
+}
+
+// convertLength converts a length in inches to unit.
+func convertLength(inches float64, unit string) float64 {
+	factor, exists := histogramUnits[unit]
+	if !exists {
+		factor = 1
+	}
+	return inches * factor
+}
+
+// histogramCounts folds length data into fish counts keyed by length.
+func histogramCounts(counts map[int]int, lengthData *model.LengthData) {
+	if lengthData == nil {
+		return
+	}
+	for _, count := range lengthData.FishCount {
+		counts[count.Length] += count.Quantity
+	}
+}
+
+// percentOf returns part as a percentage of total, or 0 when total is 0.
+func percentOf(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
+
+// roundTo rounds value to the given number of decimal places.
+func roundTo(value float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(value*scale) / scale
+}
@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// histogramBins shortens the histogram fixtures: length, quantity and value triples.
+func histogramBins(triples ...float64) []HistogramBin {
+	out := make([]HistogramBin, 0, len(triples)/3)
+	for i := 0; i+2 < len(triples); i += 3 {
+		out = append(out, HistogramBin{Length: int(triples[i]), Quantity: int(triples[i+1]), Value: triples[i+2]})
+	}
+	return out
+}
+
+func TestBuildHistogram(t *testing.T) {
+	counts := map[int]int{10: 2, 11: 1, 12: 3, 15: 4}
+	for _, tt := range []struct {
+		name string
+		opts HistogramOptions
+		want []HistogramBin
+	}{
+		{"1 in", HistogramOptions{Bin: 1, Unit: "in", Mode: HistogramCount}, histogramBins(10, 2, 2, 11, 1, 1, 12, 3, 3, 15, 4, 4)},
+		{"2 in", HistogramOptions{Bin: 2, Unit: "in", Mode: HistogramCount}, histogramBins(10, 3, 3, 12, 3, 3, 14, 4, 4)},
+		{"5 in", HistogramOptions{Bin: 5, Unit: "in", Mode: HistogramCount}, histogramBins(10, 6, 6, 15, 4, 4)},
+		// 25.4, 27.94, 30.48 and 38.1 cm.
+		{"5 cm", HistogramOptions{Bin: 5, Unit: "cm", Mode: HistogramCount}, histogramBins(25, 3, 3, 30, 3, 3, 35, 4, 4)},
+		{"no width", HistogramOptions{Unit: "in", Mode: HistogramCount}, histogramBins(10, 2, 2, 11, 1, 1, 12, 3, 3, 15, 4, 4)},
+		{"percent", HistogramOptions{Bin: 1, Unit: "in", Mode: HistogramPercent}, histogramBins(10, 2, 20, 11, 1, 10, 12, 3, 30, 15, 4, 40)},
+		{"cumulative", HistogramOptions{Bin: 2, Unit: "in", Mode: HistogramCumulative}, histogramBins(10, 3, 3, 12, 3, 6, 14, 4, 10)},
+	} {
+		if got := BuildHistogram(counts, tt.opts); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	if got := BuildHistogram(nil, DefaultHistogramOptions()); got == nil || len(got) != 0 {
+		t.Errorf("no counts = %#v, want no bins", got)
+	}
+}
+
+func TestBuildHistogramUnitEdges(t *testing.T) {
+	for _, tt := range []struct {
+		inches int
+		unit   string
+		bin    int
+		want   int
+	}{
+		{5, "mm", 1, 127}, // exactly 127 mm, not the bin below
+		{5, "mm", 5, 125},
+		{5, "cm", 1, 12}, // 12.7 cm
+		{5, "cm", 2, 12},
+		{10, "cm", 1, 25}, // 25.4 cm
+		{1, "mm", 1, 25},  // 25.4 mm
+		{20, "mm", 5, 505},
+		{7, "in", 5, 5},
+	} {
+		got := BuildHistogram(map[int]int{tt.inches: 1}, HistogramOptions{Bin: tt.bin, Unit: tt.unit, Mode: HistogramCount})
+		if len(got) != 1 || got[0].Length != tt.want {
+			t.Errorf("%d in in %d %s bins = %+v, want the bin at %d", tt.inches, tt.bin, tt.unit, got, tt.want)
+		}
+	}
+}
+
+func TestBuildHistogramDensity(t *testing.T) {
+	got := BuildHistogram(map[int]int{10: 2, 11: 1, 12: 3, 15: 4}, HistogramOptions{Bin: 2, Unit: "in", Mode: HistogramCount, Density: true})
+	// Each bin's share of the 10 fish over its 2 inches.
+	want := []float64{0.15, 0.15, 0.2}
+	if len(got) != len(want) {
+		t.Fatalf("got %d bins, want %d", len(got), len(want))
+	}
+	integral := 0.0
+	for i, bin := range got {
+		if bin.Density == nil || *bin.Density != want[i] {
+			t.Errorf("bin %d density = %v, want %v", bin.Length, bin.Density, want[i])
+			continue
+		}
+		integral += *bin.Density * 2
+	}
+	if math.Abs(integral-1) > 1e-9 {
+		t.Errorf("densities integrate to %v, want 1", integral)
+	}
+
+	// Percentages are rounded to two places.
+	got = BuildHistogram(map[int]int{1: 1, 2: 2}, HistogramOptions{Bin: 1, Unit: "in", Mode: HistogramPercent})
+	if got[0].Value != 33.33 || got[1].Value != 66.67 || got[0].Density != nil {
+		t.Errorf("percent bins = %+v, want 33.33 and 66.67 without densities", got)
+	}
+}
+
+func TestParseHistogramOptions(t *testing.T) {
+	for _, tt := range []struct {
+		bin, unit, mode, density string
+		want                     HistogramOptions
+	}{
+		{"", "", "", "", DefaultHistogramOptions()},
+		{"2", "cm", "percent", "true", HistogramOptions{Bin: 2, Unit: "cm", Mode: HistogramPercent, Density: true}},
+		{"5", "mm", "cumulative", "0", HistogramOptions{Bin: 5, Unit: "mm", Mode: HistogramCumulative}},
+	} {
+		got, err := ParseHistogramOptions(tt.bin, tt.unit, tt.mode, tt.density)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseHistogramOptions(%q, %q, %q, %q) = %+v, %v; want %+v", tt.bin, tt.unit, tt.mode, tt.density, got, err, tt.want)
+		}
+	}
+
+	for _, args := range [][4]string{
+		{"3", "", "", ""},
+		{"x", "", "", ""},
+		{"", "ft", "", ""},
+		{"", "IN", "", ""},
+		{"", "", "median", ""},
+		{"", "", "", "maybe"},
+	} {
+		if _, err := ParseHistogramOptions(args[0], args[1], args[2], args[3]); !errors.Is(err, ErrInvalidHistogram) {
+			t.Errorf("ParseHistogramOptions(%q) error = %v, want ErrInvalidHistogram", args, err)
+		}
+	}
+}
+
+func TestConvertLength(t *testing.T) {
+	for _, tt := range []struct {
+		unit string
+		want float64
+	}{
+		{"in", 5},
+		{"cm", 12.7},
+		{"mm", 127},
+		{"ft", 5}, // unknown units are left in inches
+	} {
+		if got := convertLength(5, tt.unit); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("convertLength(5, %q) = %v, want %v", tt.unit, got, tt.want)
+		}
+	}
+}
+
+func TestAlignHistograms(t *testing.T) {
+	a := histogramBins(10, 1, 1, 14, 2, 3)
+	b := histogramBins(12, 4, 4)
+
+	// Gaps in cumulative histograms carry the running total.
+	got := alignHistograms([][]HistogramBin{a, b}, HistogramOptions{Bin: 2, Unit: "in", Mode: HistogramCumulative})
+	want := [][]HistogramBin{histogramBins(10, 1, 1, 12, 0, 1, 14, 2, 3), histogramBins(10, 0, 0, 12, 4, 4, 14, 0, 4)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("cumulative = %+v, want %+v", got, want)
+	}
+
+	got = alignHistograms([][]HistogramBin{a, b}, HistogramOptions{Bin: 2, Unit: "in", Mode: HistogramCount, Density: true})
+	if len(got[1]) != 3 || got[1][0].Value != 0 || got[1][0].Density == nil || *got[1][0].Density != 0 {
+		t.Errorf("count bins = %+v, want empty bins with zero values and densities", got[1])
+	}
+
+	got = alignHistograms([][]HistogramBin{nil, {}}, DefaultHistogramOptions())
+	if len(got) != 2 || got[0] == nil || len(got[0]) != 0 || got[1] == nil || len(got[1]) != 0 {
+		t.Errorf("empty histograms = %#v, want two empty lists", got)
+	}
+}
@@ -0,0 +1,401 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"fishreports/model"
+)
+
+// ErrInvalidIngest is returned when an ingest body can't be read as JSON.
+var ErrInvalidIngest = errors.New("invalid ingest body")
+
+// errSharedLakeFile is returned for a lake stored in a file that also holds
+// other lakes, which the ingest API would lose by rewriting it.
+var errSharedLakeFile = errors.New("survey file holds other lakes")
+
+// IngestRejection describes a record the ingest API refused.
+type IngestRejection struct {
+	Record    int    `json:"record"` // 1-based position in the request body
+	DOWNumber int    `json:"dow_number,omitempty"`
+	Error     string `json:"error"`
+}
+
+// IngestReport summarizes one POST /admin/surveys request.
+type IngestReport struct {
+	Records          int                     `json:"records"`
+	Lakes            int                     `json:"lakes"`
+	SurveysAdded     int                     `json:"surveys_added"`
+	SurveysUpdated   int                     `json:"surveys_updated"`
+	SurveysUnchanged int                     `json:"surveys_unchanged"`
+	Rejected         []IngestRejection       `json:"rejected"`
+	Validation       *model.ValidationReport `json:"validation"`
+	Events           []model.SurveyEvent     `json:"events,omitempty"`
+}
+
+// Ingest accepts raw survey documents in a state's agency format, either one
+// JSON object or a stream of them (NDJSON); an empty state means the default
+// state. Each is converted like a survey file and its surveys are upserted,
+// as received, into the lake's stored surveys by survey ID, falling back to
+// survey key. The lake is written back to the state's survey directory before
+// IDs are assigned and before validation, so generated IDs, fixes and drops
+// stay out of the files; the validated lake then replaces the in-memory data
+// and aggregates, keeping the IDs already assigned to its surveys. Invalid
+// records are reported and skipped.
+func (r *DataReloader) Ingest(ctx context.Context, body []byte, stateCode string) (*IngestReport, error) {
+	report := &IngestReport{Rejected: []IngestRejection{}, Validation: model.NewValidationReport()}
+	state, exists := r.state(stateCode)
+	if !exists {
+		return nil, fmt.Errorf("%w: unknown state %q", ErrInvalidIngest, stateCode)
+	}
+	if _, ok := state.Adapter.(SurveyEncoder); !ok {
+		return nil, fmt.Errorf("%w: surveys in %s's format can't be written back", ErrInvalidIngest, state.Code)
+	}
+
+	// Parse every record, grouping the valid ones by lake in request order.
+	incoming := make(map[int][]model.FishData)
+	firstRecord := make(map[int]int)
+	var order []int
+	speciesMap := r.Model.Species()
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	for {
+		var raw json.RawMessage
+		err := decoder.Decode(&raw)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: record %d: %v", ErrInvalidIngest, report.Records+1, err)
+		}
+		report.Records++
+
+		lakes, err := convertStateData(raw, state)
+		if err != nil {
+			rejection := IngestRejection{Record: report.Records, Error: err.Error()}
+			if len(lakes) == 1 {
+				rejection.DOWNumber = lakes[0].Result.DOWNumber
+			}
+			report.Rejected = append(report.Rejected, rejection)
+			continue
+		}
+		for _, fishData := range lakes {
+			// Validate a copy for the report; the lake is stored as received.
+			validated := fishData
+			report.Validation.Merge(validateLake(&validated, speciesMap, r.Validation))
+			dow := fishData.Result.DOWNumber
+			if _, seen := incoming[dow]; !seen {
+				order = append(order, dow)
+				firstRecord[dow] = report.Records
+			}
+			incoming[dow] = append(incoming[dow], fishData)
+		}
+	}
+	if report.Records == 0 {
+		return nil, fmt.Errorf("%w: no records", ErrInvalidIngest)
+	}
+	if len(order) == 0 {
+		return report, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Lakes read from archives or CSV exports have no file to write back to.
+	writable := order[:0]
+	for _, dow := range order {
+		if source, readOnly := r.readOnlyLakes[dow]; readOnly {
+			report.Rejected = append(report.Rejected, IngestRejection{
+				Record:    firstRecord[dow],
+				DOWNumber: dow,
+				Error:     fmt.Sprintf("lake %d is loaded from read-only source %s", dow, source),
+			})
+			continue
+		}
+		writable = append(writable, dow)
+	}
+	order = writable
+	if len(order) == 0 {
+		return report, nil
+	}
+
+	current, _ := r.Model.Snapshot()
+
+	// Merge each lake's stored surveys with the incoming ones.
+	merged := make(map[int]model.FishData, len(order))
+	stored := order[:0]
+	for _, dow := range order {
+		lake, err := r.storedLake(dow, state)
+		if errors.Is(err, errSharedLakeFile) {
+			report.Rejected = append(report.Rejected, IngestRejection{Record: firstRecord[dow], DOWNumber: dow, Error: err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		stored = append(stored, dow)
+		for _, fishData := range incoming[dow] {
+			lake.Result.DOWNumber = dow
+			lake.Result.State = fishData.Result.State
+			lake.Result.CountyName = fishData.Result.CountyName
+			lake.Result.CountyFIPS = fishData.Result.CountyFIPS
+			if fishData.Result.LakeName != "" {
+				lake.Result.LakeName = fishData.Result.LakeName
+			}
+			for _, survey := range fishData.Result.Surveys {
+				switch upsertSurvey(&lake, survey) {
+				case upsertAdded:
+					report.SurveysAdded++
+				case upsertUpdated:
+					report.SurveysUpdated++
+				default:
+					report.SurveysUnchanged++
+				}
+			}
+		}
+		merged[dow] = lake
+	}
+	order = stored
+	report.Lakes = len(merged)
+	if len(order) == 0 {
+		return report, nil
+	}
+
+	// Persist before touching the live data so a write failure changes nothing.
+	for _, dow := range order {
+		if err := r.writeLake(merged[dow]); err != nil {
+			return nil, err
+		}
+	}
+
+	// Rebuild the county index with the merged lakes in place of the old ones,
+	// noting the IDs the replaced lakes' surveys were loaded with.
+	fishDataByCounty := make(map[string][]model.FishData, len(current)+1)
+	surveyIDs := make(map[string]string)
+	for countyName, fishDataList := range current {
+		kept := make([]model.FishData, 0, len(fishDataList))
+		for _, data := range fishDataList {
+			if _, replaced := merged[data.Result.DOWNumber]; !replaced {
+				kept = append(kept, data)
+				continue
+			}
+			for _, survey := range data.Result.Surveys {
+				surveyIDs[model.SurveyKey(data.Result.DOWNumber, survey)] = survey.SurveyID
+			}
+		}
+		if len(kept) > 0 {
+			fishDataByCounty[countyName] = kept
+		}
+	}
+	for _, dow := range order {
+		lake := merged[dow]
+		lakeState, _ := r.state(lake.Result.State)
+		assignSurveyIDs(&lake, lakeState, surveyIDs)
+		validateLake(&lake, speciesMap, r.Validation)
+		key := fishDataKey(lake)
+		fishDataByCounty[key] = append(fishDataByCounty[key], lake)
+	}
+
+	fresh := &model.FishSurveyModel{FishDataByCounty: fishDataByCounty, SpeciesMap: speciesMap}
+	MaterializeAggregates(fresh, r.Reconciler)
+	fingerprints := surveyFingerprints(fishDataByCounty)
+	diff := DiffDatasets(current, fishDataByCounty, "before ingest", "ingest")
+
+	r.Model.Replace(fresh.FishDataByCounty, fresh.Aggregates)
+	if r.CountyController != nil {
+		counties := append([]model.County(nil), r.CountyController.GetCounties()...)
+		r.CountyController.SetCounties(EnhanceCountiesWithLakes(fresh, counties, r.Reconciler))
+	}
+	result, err := r.finish(current, fishDataByCounty, fingerprints, diff)
+	if err != nil {
+		return nil, err
+	}
+	report.Events = result.Events
+	log.Printf("✅ Ingested %d records: %d lakes, %d surveys added, %d updated, %d rejected records",
+		report.Records, report.Lakes, report.SurveysAdded, report.SurveysUpdated, len(report.Rejected))
+	return report, nil
+}
+
+// storedLake reads a lake back from the files it was loaded from, before
+// validation, combining them since a lake may have been loaded from several
+// files. A lake without files has no surveys. It returns errSharedLakeFile
+// when a file also holds other lakes. Callers hold r.mu.
+func (r *DataReloader) storedLake(dow int, state StateDataset) (model.FishData, error) {
+	var lake model.FishData
+	if len(r.lakeFiles[dow]) == 0 {
+		// A file of another lake may already have the new lake's name.
+		path := newLakeFile(state, dow)
+		if _, err := os.Stat(path); err == nil {
+			return lake, fmt.Errorf("lake %d can't be added: %w (%s)", dow, errSharedLakeFile, path)
+		}
+	}
+	for _, path := range r.lakeFiles[dow] {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return lake, fmt.Errorf("failed to read lake %d: %w", dow, err)
+		}
+		lakes, err := convertStateData(raw, state)
+		if err != nil {
+			return lake, fmt.Errorf("failed to read lake %d from %s: %w", dow, path, err)
+		}
+		for _, data := range lakes {
+			if data.Result.DOWNumber != dow {
+				return lake, fmt.Errorf("lake %d can't be updated: %w (%s)", dow, errSharedLakeFile, path)
+			}
+			lake.Result.DOWNumber = dow
+			lake.Result.State = data.Result.State
+			lake.Result.CountyName = data.Result.CountyName
+			lake.Result.CountyFIPS = data.Result.CountyFIPS
+			lake.Result.LakeName = data.Result.LakeName
+			lake.Result.Surveys = append(lake.Result.Surveys, data.Result.Surveys...)
+		}
+	}
+	return lake, nil
+}
+
+type upsertOutcome int
+
+const (
+	upsertUnchanged upsertOutcome = iota
+	upsertAdded
+	upsertUpdated
+)
+
+// upsertSurvey replaces the lake's survey with the same survey ID, or else
+// the same survey key, and appends the survey when neither matches. Surveys
+// without an ID only match by key.
+func upsertSurvey(lake *model.FishData, survey model.Survey) upsertOutcome {
+	key := model.SurveyKey(lake.Result.DOWNumber, survey)
+	match := -1
+	for i, existing := range lake.Result.Surveys {
+		if survey.SurveyID != "" && existing.SurveyID == survey.SurveyID {
+			match = i
+			break
+		}
+		if match < 0 && model.SurveyKey(lake.Result.DOWNumber, existing) == key {
+			match = i
+		}
+	}
+	if match < 0 {
+		lake.Result.Surveys = append(lake.Result.Surveys, survey)
+		return upsertAdded
+	}
+	if surveyFingerprint(lake.Result.Surveys[match]) == surveyFingerprint(survey) {
+		return upsertUnchanged
+	}
+	lake.Result.Surveys[match] = survey
+	return upsertUpdated
+}
+
+// state returns the dataset for a state code, falling back to the default
+// state in SurveyDir when no states are configured.
+func (r *DataReloader) state(code string) (StateDataset, bool) {
+	code = model.NormalizeState(code)
+	for _, state := range r.States {
+		if state.Code == code {
+			return state, true
+		}
+	}
+	if len(r.States) == 0 && code == model.DefaultState {
+		return DefaultStateDataset("", r.SurveyDir), true
+	}
+	return StateDataset{}, false
+}
+
+// newLakeFile is the file a lake without survey files is written to.
+func newLakeFile(state StateDataset, dow int) string {
+	return filepath.Join(state.SurveyDir, strconv.Itoa(state.AgencyLakeID(dow))+".json")
+}
+
+// writeLake writes a merged lake in its state's raw format to the file it
+// was loaded from (or <state survey dir>/<agency lake ID>.json for a new
+// lake) and removes any other files that held part of it; storedLake has
+// checked that none of them holds another lake. Callers hold r.mu.
+func (r *DataReloader) writeLake(lake model.FishData) error {
+	dow := lake.Result.DOWNumber
+	state, exists := r.state(lake.Result.State)
+	if !exists {
+		return fmt.Errorf("lake %d is in unknown state %q", dow, lake.Result.State)
+	}
+	files := r.lakeFiles[dow]
+	target := newLakeFile(state, dow)
+	if len(files) > 0 {
+		target = files[0]
+	}
+
+	data, err := encodeStateLake(lake, state)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(target, data); err != nil {
+		return fmt.Errorf("failed to write lake %d: %w", dow, err)
+	}
+	for _, path := range files {
+		if path == target {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("❌ Error removing merged survey file %s: %v", path, err)
+		}
+	}
+
+	if r.lakeFiles == nil {
+		r.lakeFiles = make(map[int][]string)
+	}
+	r.lakeFiles[dow] = []string{target}
+	return nil
+}
+
+// encodeFishData serializes a lake the way the scraper writes it: fishCount
+// entries become [length, quantity] pairs and unset species are omitted.
+func encodeFishData(fishData model.FishData) ([]byte, error) {
+	encoded, err := json.Marshal(fishData)
+	if err != nil {
+		return nil, err
+	}
+	var rawData map[string]interface{}
+	if err := json.Unmarshal(encoded, &rawData); err != nil {
+		return nil, err
+	}
+	untransformFishCount(rawData)
+	return json.Marshal(rawData)
+}
+
+// untransformFishCount reverses TransformFishCount.
+func untransformFishCount(data map[string]interface{}) {
+	for key, value := range data {
+		switch {
+		case key == "fishCount":
+			list, _ := value.([]interface{})
+			pairs := make([][2]interface{}, 0, len(list))
+			for _, item := range list {
+				if count, ok := item.(map[string]interface{}); ok {
+					pairs = append(pairs, [2]interface{}{count["length"], count["quantity"]})
+				}
+			}
+			data[key] = pairs
+		case key == "species" && value == nil, key == "quality_flags":
+			delete(data, key)
+		default:
+			if nested, ok := value.(map[string]interface{}); ok {
+				untransformFishCount(nested)
+			} else if nestedList, ok := value.([]interface{}); ok {
+				for _, item := range nestedList {
+					if itemMap, ok := item.(map[string]interface{}); ok {
+						untransformFishCount(itemMap)
+					}
+				}
+			}
+		}
+	}
+}
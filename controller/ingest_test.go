@@ -0,0 +1,253 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"fishreports/model"
+)
+
+// mnSurvey is a survey in the Minnesota DNR format with one species.
+func mnSurvey(id, date, code string, catch int, lengths string) string {
+	survey := `{"surveyDate": "` + date + `", "surveyType": "Standard Survey",
+		"fishCatchSummaries": [{"species": "` + code + `", "totalCatch": ` + strconv.Itoa(catch) + `}],
+		"lengths": {"` + code + `": {"fishCount": ` + lengths + `}}`
+	if id != "" {
+		survey += `, "surveyID": "` + id + `"`
+	}
+	return survey + "}"
+}
+
+// mnLake is a lake document in the Minnesota DNR format.
+func mnLake(dow int, county, name string, surveys ...string) string {
+	return `{"result": {"DOWNumber": ` + strconv.Itoa(dow) + `, "countyName": "` + county + `", "lakeName": "` + name +
+		`", "surveys": [` + strings.Join(surveys, ", ") + `]}}`
+}
+
+// newIngestReloader loads the survey files in dir, and any other sources, as
+// the default state and returns a reloader for them.
+func newIngestReloader(t *testing.T, dir string, adapter SurveyAdapter, sources ...SurveySource) *DataReloader {
+	t.Helper()
+	state := DefaultStateDataset("", dir)
+	state.Sources = sources
+	if adapter != nil {
+		state.Adapter = adapter
+	}
+	m := &model.FishSurveyModel{SpeciesMap: fixtureSpecies()}
+	fish := NewFishSurveyController(m)
+	fish.States = []StateDataset{state}
+	fish.Reconciler = newFixtureReconciler()
+	if err := LoadStateData(context.Background(), m, fish.States, fish.Validation); err != nil {
+		t.Fatalf("LoadStateData: %v", err)
+	}
+	MaterializeAggregates(m, fish.Reconciler)
+	r := NewDataReloader(fish, nil, dir, "", nil)
+	if _, err := r.Baseline(); err != nil {
+		t.Fatalf("Baseline: %v", err)
+	}
+	return r
+}
+
+// storedSurveys reads a survey file back as the agency wrote it.
+func storedSurveys(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lake struct {
+		Result struct {
+			Surveys []map[string]interface{} `json:"surveys"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(data, &lake); err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	return lake.Result.Surveys
+}
+
+// liveSurveys returns a lake's loaded surveys by date.
+func liveSurveys(r *DataReloader, dow int) map[string]model.Survey {
+	surveys := make(map[string]model.Survey)
+	fishDataByCounty, _ := r.Model.Snapshot()
+	for _, fishDataList := range fishDataByCounty {
+		for _, data := range fishDataList {
+			if data.Result.DOWNumber != dow {
+				continue
+			}
+			for _, survey := range data.Result.Surveys {
+				surveys[survey.SurveyDate] = survey
+			}
+		}
+	}
+	return surveys
+}
+
+func TestIngestUpsertsSurveys(t *testing.T) {
+	dir := t.TempDir()
+	writeSourceFile(t, filepath.Join(dir, "1000100.json"), []byte(mnLake(1000100, "Aitkin", "Big Lake",
+		mnSurvey("A1", "2019-06-01", "WAE", 3, "[[14, 3]]"),
+		mnSurvey("", "2020-06-01", "WAE", 2, "[[16, 2]]"))))
+	r := newIngestReloader(t, dir, nil)
+	loadedID := liveSurveys(r, 1000100)["2020-06-01"].SurveyID
+
+	body := mnLake(1000100, "Aitkin", "Big Lake",
+		mnSurvey("A1", "2019-06-01", "WAE", 4, "[[14, 4]]"), // by survey ID
+		mnSurvey("", "2020-06-01", "WAE", 5, "[[16, 5]]"),   // by survey key
+		mnSurvey("", "2022-06-01", "NOP", 1, "[[30, 1]]")) + "\n" +
+		mnLake(2000100, "Cass", "New Lake", mnSurvey("", "2021-06-01", "BLG", 6, "[[7, 6]]")) + "\n" +
+		mnLake(1000100, "Aitkin", "Big Lake", mnSurvey("A1", "2019-06-01", "WAE", 4, "[[14, 4]]"))
+	report, err := r.Ingest(context.Background(), []byte(body), "")
+	if err != nil {
+		t.Fatalf("Ingest: %v", err)
+	}
+	if report.Records != 3 || report.Lakes != 2 || report.SurveysAdded != 2 || report.SurveysUpdated != 2 || report.SurveysUnchanged != 1 {
+		t.Errorf("report = %+v, want 3 records, 2 lakes, 2 added, 2 updated, 1 unchanged", report)
+	}
+	if len(report.Rejected) != 0 || len(report.Events) != 4 {
+		t.Errorf("rejected %+v, %d events; want none rejected and 4 events", report.Rejected, len(report.Events))
+	}
+
+	// The files hold the surveys as received, without generated IDs.
+	stored := storedSurveys(t, filepath.Join(dir, "1000100.json"))
+	if len(stored) != 3 || stored[0]["surveyID"] != "A1" || stored[1]["surveyID"] != "" || stored[2]["surveyID"] != "" {
+		t.Errorf("stored surveys = %v, want A1 and two without IDs", stored)
+	}
+	if catch := stored[1]["fishCatchSummaries"].([]interface{})[0].(map[string]interface{})["totalCatch"]; catch != 5.0 {
+		t.Errorf("2020 survey total catch = %v, want 5", catch)
+	}
+	if stored := storedSurveys(t, filepath.Join(dir, "2000100.json")); len(stored) != 1 {
+		t.Errorf("new lake file surveys = %v", stored)
+	}
+
+	// The live data has IDs, and surveys keep the ones they were loaded with.
+	live := liveSurveys(r, 1000100)
+	if live["2020-06-01"].SurveyID != loadedID || live["2019-06-01"].SurveyID != "A1" || live["2022-06-01"].SurveyID == "" {
+		t.Errorf("live survey IDs = %q, %q, %q; want %q, A1 and a new ID",
+			live["2020-06-01"].SurveyID, live["2019-06-01"].SurveyID, live["2022-06-01"].SurveyID, loadedID)
+	}
+	if len(liveSurveys(r, 2000100)) != 1 {
+		t.Error("the new lake isn't loaded")
+	}
+
+	// Reloading the files gives the same surveys.
+	reloaded := newIngestReloader(t, dir, nil)
+	if got := liveSurveys(reloaded, 1000100); len(got) != 3 || got["2020-06-01"].FishCatchSummaries[0].TotalCatch == nil || *got["2020-06-01"].FishCatchSummaries[0].TotalCatch != 5 {
+		t.Errorf("reloaded surveys = %+v", got)
+	}
+}
+
+func TestIngestLakeFromSeveralFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeSourceFile(t, filepath.Join(dir, "a.json"), []byte(mnLake(1000100, "Aitkin", "Big Lake",
+		mnSurvey("", "2019-06-01", "WAE", 3, "[[14, 3]]"))))
+	writeSourceFile(t, filepath.Join(dir, "b.json"), []byte(mnLake(1000100, "Aitkin", "Big Lake",
+		mnSurvey("", "2020-06-01", "WAE", 2, "[[16, 2]]"))))
+	r := newIngestReloader(t, dir, nil)
+
+	body := mnLake(1000100, "Aitkin", "Big Lake", mnSurvey("", "2021-06-01", "NOP", 1, "[[30, 1]]"))
+	if report, err := r.Ingest(context.Background(), []byte(body), "MN"); err != nil || report.SurveysAdded != 1 {
+		t.Fatalf("Ingest = %+v, %v", report, err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("survey files = %v, want the lake merged into one", files)
+	}
+	if stored := storedSurveys(t, files[0]); len(stored) != 3 {
+		t.Errorf("stored surveys = %v, want all three", stored)
+	}
+}
+
+// fishDataLake is a lake document in the fishdata format with one survey.
+func fishDataLake(dow int, county, date string) string {
+	return `{"result": {"DOWNumber": ` + strconv.Itoa(dow) + `, "countyName": "` + county + `", "surveys": [` +
+		`{"surveyID": "` + date + `", "surveyDate": "` + date + `", "surveyType": "Standard Survey",` +
+		` "lengths": {"WAE": {"fishCount": [{"length": 14, "quantity": 1}]}}}]}}`
+}
+
+func TestIngestRejectsSharedFiles(t *testing.T) {
+	dir := t.TempDir()
+	shared := filepath.Join(dir, "both.json")
+	writeSourceFile(t, shared, []byte("["+fishDataLake(1000100, "Aitkin", "2019-06-01")+", "+fishDataLake(2000100, "Cass", "2019-06-01")+"]"))
+	// The file a new lake 3000100 would be written to holds lake 4000100.
+	taken := filepath.Join(dir, "3000100.json")
+	writeSourceFile(t, taken, []byte(fishDataLake(4000100, "Aitkin", "2019-06-01")))
+	before, _ := os.ReadFile(shared)
+	r := newIngestReloader(t, dir, fishDataAdapter{})
+
+	body := fishDataLake(1000100, "Aitkin", "2021-06-01") + "\n" + fishDataLake(3000100, "Aitkin", "2020-06-01")
+	report, err := r.Ingest(context.Background(), []byte(body), "")
+	if err != nil {
+		t.Fatalf("Ingest: %v", err)
+	}
+	if report.Lakes != 0 || len(report.Rejected) != 2 {
+		t.Fatalf("report = %+v, want both lakes rejected", report)
+	}
+	for i, dow := range []int{1000100, 3000100} {
+		if rejection := report.Rejected[i]; rejection.DOWNumber != dow || rejection.Record != i+1 || !strings.Contains(rejection.Error, "holds other lakes") {
+			t.Errorf("rejection %d = %+v, want lake %d", i, rejection, dow)
+		}
+	}
+	if after, _ := os.ReadFile(shared); string(after) != string(before) {
+		t.Errorf("the shared file was rewritten:\n%s", after)
+	}
+	if len(liveSurveys(r, 2000100)) != 1 || len(liveSurveys(r, 4000100)) != 1 {
+		t.Error("the other lakes were dropped")
+	}
+}
+
+func TestIngestRejectsReadOnlyLakes(t *testing.T) {
+	dir := t.TempDir()
+	exports := t.TempDir()
+	writeSourceFile(t, filepath.Join(exports, "lakes.csv"), []byte(lengthFrequencyCSV))
+	r := newIngestReloader(t, dir, nil, CSVSource{Path: exports})
+
+	body := mnLake(11000200, "Cass", "Long Lake", mnSurvey("", "2022-06-01", "WAE", 1, "[[15, 1]]"))
+	report, err := r.Ingest(context.Background(), []byte(body), "")
+	if err != nil {
+		t.Fatalf("Ingest: %v", err)
+	}
+	if report.Lakes != 0 || len(report.Rejected) != 1 || !strings.Contains(report.Rejected[0].Error, "read-only source") {
+		t.Errorf("report = %+v, want the lake rejected as read-only", report)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*.json")); len(files) != 0 {
+		t.Errorf("files written: %v", files)
+	}
+}
+
+func TestIngestRejectsInvalidRecords(t *testing.T) {
+	dir := t.TempDir()
+	r := newIngestReloader(t, dir, nil)
+
+	body := mnLake(1000100, "", "No County", mnSurvey("", "2020-06-01", "WAE", 1, "[[15, 1]]")) + "\n" +
+		mnLake(2000100, "Cass", "Good Lake", mnSurvey("", "2020-06-01", "WAE", 1, "[[15, 1]]"))
+	report, err := r.Ingest(context.Background(), []byte(body), "")
+	if err != nil {
+		t.Fatalf("Ingest: %v", err)
+	}
+	if len(report.Rejected) != 1 || report.Rejected[0].Record != 1 || report.Rejected[0].DOWNumber != 1000100 {
+		t.Errorf("rejected = %+v, want record 1 for lake 1000100", report.Rejected)
+	}
+	if report.Lakes != 1 || report.SurveysAdded != 1 {
+		t.Errorf("report = %+v, want the valid record ingested", report)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "1000100.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("the rejected lake was written: %v", err)
+	}
+
+	for _, tt := range []struct{ body, state string }{
+		{"", ""},
+		{"{not json", ""},
+		{body, "ZZ"},
+	} {
+		if _, err := r.Ingest(context.Background(), []byte(tt.body), tt.state); !errors.Is(err, ErrInvalidIngest) {
+			t.Errorf("body %q in state %q: err = %v, want ErrInvalidIngest", tt.body, tt.state, err)
+		}
+	}
+}
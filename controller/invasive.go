@@ -0,0 +1,206 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"fishreports/model"
+)
+
+// Limits of the invasive species alerts.
+const (
+	defaultAlertLimit = 100
+	maxAlertLimit     = 1000
+)
+
+// ErrInvalidAlerts is returned for unsupported alert filters.
+var ErrInvalidAlerts = errors.New("invalid alert filters")
+
+// FirstDetection is the first survey of a lake that caught or measured a
+// species.
+type FirstDetection struct {
+	Code               string   `json:"code"`
+	Species            string   `json:"species"`
+	SpeciesID          string   `json:"species_id"`
+	Status             []string `json:"status"`
+	DOWNumber          int      `json:"dow_number"`
+	LakeName           string   `json:"lake_name"`
+	CountyName         string   `json:"county_name"`
+	CountyIDs          []string `json:"county_ids"`
+	State              string   `json:"state"`
+	SurveyID           string   `json:"survey_id"`
+	SurveyDate         string   `json:"survey_date"`
+	SurveyType         string   `json:"survey_type"`
+	FishMeasured       int      `json:"fish_measured"`
+	TotalCatch         int      `json:"total_catch"`
+	SurveysBefore      int      `json:"surveys_before"`       // earlier surveys of the lake, none of which found the species
+	PreviousSurveyDate *string  `json:"previous_survey_date"` // the latest of them
+	NewToLake          bool     `json:"new_to_lake"`          // false when found by the lake's first survey
+}
+
+// AlertFilters narrows the invasive species alerts.
+type AlertFilters struct {
+	Since           string   // survey dates on or after, as YYYY or YYYY-MM-DD
+	Counties        []string // county IDs
+	Species         []string // species IDs, codes or common names
+	DOW             int
+	IncludeBaseline bool // include species already present in a lake's first survey
+	Limit           int
+}
+
+// LakeFirstDetections returns the first survey of a lake to catch or measure
+// each species with a status, earliest first. Surveys are ordered by date;
+// species found in the catch summaries but not measured count as detected.
+func (c *FishSurveyController) LakeFirstDetections(data model.FishData, speciesMap map[string]model.Species, status string) []FirstDetection {
+	surveys := make([]model.Survey, len(data.Result.Surveys))
+	copy(surveys, data.Result.Surveys)
+	sort.SliceStable(surveys, func(i, j int) bool {
+		return surveys[i].SurveyDate < surveys[j].SurveyDate
+	})
+
+	seen := make(map[string]bool)
+	var detections []FirstDetection
+	for i, survey := range surveys {
+		counts := make(map[string]int)
+		for code, lengthData := range survey.Lengths {
+			measured := make(map[int]int)
+			histogramCounts(measured, lengthData)
+			counts[code] += sumCounts(measured)
+		}
+		catches := totalCatches(survey)
+		for code := range catches {
+			if _, exists := counts[code]; !exists {
+				counts[code] = 0
+			}
+		}
+
+		codes := make([]string, 0, len(counts))
+		for code := range counts {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		for _, code := range codes {
+			species, exists := speciesMap[code]
+			if seen[code] || !exists || !species.HasStatus(status) {
+				continue
+			}
+			if counts[code] == 0 && catches[code] == 0 {
+				if _, measured := survey.Lengths[code]; !measured {
+					continue
+				}
+			}
+			seen[code] = true
+			detection := FirstDetection{
+				Code:          code,
+				Species:       species.CommonName,
+				SpeciesID:     species.ID,
+				Status:        species.Status,
+				DOWNumber:     data.Result.DOWNumber,
+				LakeName:      data.Result.LakeName,
+				CountyName:    data.Result.CountyName,
+				CountyIDs:     c.Reconciler.lakeCountyIDs(data),
+				State:         model.LakeState(data),
+				SurveyID:      survey.SurveyID,
+				SurveyDate:    survey.SurveyDate,
+				SurveyType:    survey.SurveyType,
+				FishMeasured:  counts[code],
+				TotalCatch:    catches[code],
+				SurveysBefore: i,
+				NewToLake:     i > 0,
+			}
+			if detection.CountyIDs == nil {
+				detection.CountyIDs = []string{}
+			}
+			if i > 0 {
+				previous := surveys[i-1].SurveyDate
+				detection.PreviousSurveyDate = &previous
+			}
+			detections = append(detections, detection)
+		}
+	}
+	return detections
+}
+
+// InvasiveAlertsContext lists the surveys in which an invasive species was
+// found in a lake for the first time, newest first. Unless
+// filters.IncludeBaseline is set, species already present in a lake's first
+// survey are left out, as nothing tells when they arrived.
+func (c *FishSurveyController) InvasiveAlertsContext(ctx context.Context, filters AlertFilters) (map[string]interface{}, error) {
+	if filters.Since != "" && len(filters.Since) != 4 && len(filters.Since) != 10 {
+		return nil, fmt.Errorf("%w: since must be a year or a YYYY-MM-DD date", ErrInvalidAlerts)
+	}
+	limit := filters.Limit
+	if limit <= 0 {
+		limit = defaultAlertLimit
+	}
+	if limit > maxAlertLimit {
+		limit = maxAlertLimit
+	}
+
+	speciesMap := c.Model.Species()
+	codes := make(map[string]bool)
+	for _, name := range filters.Species {
+		code := c.ResolveSpecies(name)
+		if code == "" {
+			return nil, fmt.Errorf("%w: unknown species %q", ErrInvalidAlerts, name)
+		}
+		if !speciesMap[code].HasStatus(model.StatusInvasive) {
+			return nil, fmt.Errorf("%w: %s is not listed as invasive", ErrInvalidAlerts, speciesMap[code].CommonName)
+		}
+		codes[code] = true
+	}
+	countySet := make(map[string]bool)
+	for _, id := range filters.Counties {
+		countySet[strings.ToLower(id)] = true
+	}
+
+	alerts := []FirstDetection{}
+	fishDataByCounty, _ := c.Model.Snapshot()
+	for _, fishDataList := range fishDataByCounty {
+		for _, data := range fishDataList {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if filters.DOW != 0 && data.Result.DOWNumber != filters.DOW {
+				continue
+			}
+			if len(countySet) > 0 && !anyInSet(c.Reconciler.lakeCountyIDs(data), countySet) {
+				continue
+			}
+			for _, detection := range c.LakeFirstDetections(data, speciesMap, model.StatusInvasive) {
+				if !detection.NewToLake && !filters.IncludeBaseline {
+					continue
+				}
+				if len(codes) > 0 && !codes[detection.Code] {
+					continue
+				}
+				if filters.Since != "" && detection.SurveyDate < filters.Since {
+					continue
+				}
+				alerts = append(alerts, detection)
+			}
+		}
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		if alerts[i].SurveyDate != alerts[j].SurveyDate {
+			return alerts[i].SurveyDate > alerts[j].SurveyDate
+		}
+		if alerts[i].DOWNumber != alerts[j].DOWNumber {
+			return alerts[i].DOWNumber < alerts[j].DOWNumber
+		}
+		return alerts[i].Code < alerts[j].Code
+	})
+
+	total := len(alerts)
+	if len(alerts) > limit {
+		alerts = alerts[:limit]
+	}
+	return map[string]interface{}{
+		"data":  alerts,
+		"total": total,
+		"limit": limit,
+	}, nil
+}
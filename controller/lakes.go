@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"context"
+	"sort"
+
+	"fishreports/model"
+)
+
+// GetLakeContext returns the survey data for the lake with the given DOW
+// number, or nil when no lake matches. Surveys from every file recorded for
+// the lake are combined.
+func (c *FishSurveyController) GetLakeContext(ctx context.Context, dow int) (*model.FishData, error) {
+	var lake *model.FishData
+	fishDataByCounty, _ := c.Model.Snapshot()
+	for _, fishDataList := range fishDataByCounty {
+		for _, data := range fishDataList {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if data.Result.DOWNumber != dow {
+				continue
+			}
+			if lake == nil {
+				copied := data
+				copied.Result.Surveys = append([]model.Survey(nil), data.Result.Surveys...)
+				lake = &copied
+				continue
+			}
+			lake.Result.Surveys = append(lake.Result.Surveys, data.Result.Surveys...)
+		}
+	}
+	return lake, nil
+}
+
+// LakeIndex maps lakes by DOW number and by county ID. Surveys recorded for
+// the same lake in several files are combined.
+type LakeIndex struct {
+	ByDOW      map[int]*model.FishData
+	ByCountyID map[string][]*model.FishData
+}
+
+// BuildLakeIndexContext indexes every lake in one pass over the survey data,
+// so callers resolving many lakes pay for a single scan.
+func (c *FishSurveyController) BuildLakeIndexContext(ctx context.Context) (*LakeIndex, error) {
+	index := &LakeIndex{
+		ByDOW:      make(map[int]*model.FishData),
+		ByCountyID: make(map[string][]*model.FishData),
+	}
+	fishDataByCounty, _ := c.Model.Snapshot()
+	for _, fishDataList := range fishDataByCounty {
+		for _, data := range fishDataList {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if lake, exists := index.ByDOW[data.Result.DOWNumber]; exists {
+				lake.Result.Surveys = append(lake.Result.Surveys, data.Result.Surveys...)
+				continue
+			}
+			copied := data
+			copied.Result.Surveys = append([]model.Survey(nil), data.Result.Surveys...)
+			index.ByDOW[data.Result.DOWNumber] = &copied
+
+			// A lake spanning county lines is listed under each county.
+			countyIDs := c.Reconciler.lakeCountyIDs(data)
+			if len(countyIDs) == 0 {
+				countyIDs = []string{""}
+			}
+			for _, countyID := range countyIDs {
+				index.ByCountyID[countyID] = append(index.ByCountyID[countyID], &copied)
+			}
+		}
+	}
+	for _, lakes := range index.ByCountyID {
+		sort.Slice(lakes, func(i, j int) bool {
+			return lakes[i].Result.LakeName < lakes[j].Result.LakeName
+		})
+	}
+	return index, nil
+}
+
+// GetLakeHistogramContext returns a lake's length histogram for one species
+// (common name, code or ID) in every survey year from fromYear to toYear (0 for
+// no bound), plus the histogram of all those years combined. It returns nil
+// when the lake doesn't exist or the species wasn't measured there.
+func (c *FishSurveyController) GetLakeHistogramContext(ctx context.Context, dow int, speciesName string, fromYear, toYear int, opts HistogramOptions) (map[string]interface{}, error) {
+	code := c.ResolveSpecies(speciesName)
+	if code == "" {
+		return nil, nil
+	}
+	lake, err := c.GetLakeContext(ctx, dow)
+	if err != nil || lake == nil {
+		return nil, err
+	}
+
+	type yearCounts struct {
+		surveys int
+		counts  map[int]int
+	}
+	byYear := make(map[int]*yearCounts)
+	combined := make(map[int]int)
+	surveys := 0
+	for _, survey := range lake.Result.Surveys {
+		lengthData, exists := survey.Lengths[code]
+		if !exists || lengthData == nil {
+			continue
+		}
+		year := surveyYear(survey.SurveyDate)
+		if (fromYear > 0 && year < fromYear) || (toYear > 0 && year > toYear) {
+			continue
+		}
+		if byYear[year] == nil {
+			byYear[year] = &yearCounts{counts: make(map[int]int)}
+		}
+		byYear[year].surveys++
+		histogramCounts(byYear[year].counts, lengthData)
+		histogramCounts(combined, lengthData)
+		surveys++
+	}
+	if surveys == 0 {
+		return nil, nil
+	}
+
+	years := make([]int, 0, len(byYear))
+	for year := range byYear {
+		years = append(years, year)
+	}
+	sort.Ints(years)
+	yearHistograms := make([]map[string]interface{}, 0, len(years))
+	for _, year := range years {
+		yearHistograms = append(yearHistograms, map[string]interface{}{
+			"year":       year,
+			"surveys":    byYear[year].surveys,
+			"total_fish": sumCounts(byYear[year].counts),
+			"data":       BuildHistogram(byYear[year].counts, opts),
+		})
+	}
+
+	species := c.Model.Species()[code]
+	return map[string]interface{}{
+		"dow_number":  lake.Result.DOWNumber,
+		"lake_name":   lake.Result.LakeName,
+		"county_name": lake.Result.CountyName,
+		"species":     species.CommonName,
+		"species_id":  species.ID,
+		"histogram":   opts,
+		"years":       yearHistograms,
+		"all_years": map[string]interface{}{
+			"surveys":    surveys,
+			"total_fish": sumCounts(combined),
+			"data":       BuildHistogram(combined, opts),
+		},
+	}, nil
+}
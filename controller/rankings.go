@@ -0,0 +1,410 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"fishreports/model"
+)
+
+// Ranking metrics: the combined score or one of its components.
+const (
+	RankByScore   = "score"
+	RankByCatch   = "catch"
+	RankBySize    = "size"
+	RankByRecency = "recency"
+	RankByTrend   = "trend"
+)
+
+// Limits of one ranking page.
+const (
+	defaultRankingLimit = 20
+	maxRankingLimit     = 100
+)
+
+// ErrInvalidRanking is returned for rankings that can't be computed.
+var ErrInvalidRanking = errors.New("invalid ranking")
+
+// RankingSettings controls how lakes are scored for a species.
+type RankingSettings struct {
+	Weights              model.RankingWeights `json:"weights"`
+	RecentSurveys        int                  `json:"recent_surveys"`          // latest surveys of the species used for catch, size and trend
+	RecencyHalfLifeYears float64              `json:"recency_half_life_years"` // the recency value halves every this many years
+}
+
+// DefaultRankingSettings favors catch, then size structure.
+func DefaultRankingSettings() RankingSettings {
+	return RankingSettings{
+		Weights:              model.RankingWeights{Catch: 0.4, Size: 0.3, Recency: 0.15, Trend: 0.15},
+		RecentSurveys:        3,
+		RecencyHalfLifeYears: 5,
+	}
+}
+
+// Validate checks the weights are non-negative and not all zero.
+func (s RankingSettings) Validate() error {
+	w := s.Weights
+	if w.Catch < 0 || w.Size < 0 || w.Recency < 0 || w.Trend < 0 {
+		return fmt.Errorf("%w: weights can't be negative", ErrInvalidRanking)
+	}
+	if w.Catch+w.Size+w.Recency+w.Trend == 0 {
+		return fmt.Errorf("%w: at least one weight must be positive", ErrInvalidRanking)
+	}
+	if s.RecentSurveys < 1 {
+		return fmt.Errorf("%w: recent_surveys must be at least 1", ErrInvalidRanking)
+	}
+	if s.RecencyHalfLifeYears <= 0 {
+		return fmt.Errorf("%w: recency_half_life_years must be positive", ErrInvalidRanking)
+	}
+	return nil
+}
+
+// RankingComponent explains one part of a lake's score. Value is the lake's
+// 0-1 rating on the component, Weight the component's share of the score and
+// Points its contribution to the 0-100 score.
+type RankingComponent struct {
+	Value  float64 `json:"value"`
+	Weight float64 `json:"weight"`
+	Points float64 `json:"points"`
+	Detail string  `json:"detail"`
+}
+
+// RankingBreakdown holds the components of a lake's score.
+type RankingBreakdown struct {
+	Catch   RankingComponent `json:"catch"`
+	Size    RankingComponent `json:"size"`
+	Recency RankingComponent `json:"recency"`
+	Trend   RankingComponent `json:"trend"`
+}
+
+// LakeRanking is one ranked lake with the numbers behind its score.
+type LakeRanking struct {
+	Rank           int              `json:"rank"`
+	DOWNumber      int              `json:"dow_number"`
+	LakeName       string           `json:"lake_name"`
+	CountyName     string           `json:"county_name"`
+	State          string           `json:"state"`
+	Score          float64          `json:"score"`
+	Breakdown      RankingBreakdown `json:"breakdown"`
+	RecentSurveys  []string         `json:"recent_surveys"`   // dates of the surveys scored, newest first
+	CatchPerSurvey float64          `json:"catch_per_survey"` // raw catch, not adjusted for effort
+	FishMeasured   int              `json:"fish_measured"`
+	LargeFishShare *float64         `json:"large_fish_share"` // nil when no fish were measured
+	LastSurveyed   string           `json:"last_surveyed"`
+}
+
+// rankingCandidate holds a lake's raw numbers before they are rated.
+type rankingCandidate struct {
+	lake           *model.FishData
+	recent         []model.Survey // newest first
+	catches        []int          // species catch of each recent survey
+	catchPerSurvey float64
+	counts         map[int]int // fish measured in the recent surveys, by length
+}
+
+// RankLakesContext ranks the lakes where a species (common name, code or ID)
+// was surveyed, optionally only those in one county (ID or name, matched in
+// state or the default state). Each lake gets a 0-100 score combining:
+//
+//   - catch: the species' average catch per survey in the lake's recent
+//     surveys, on a log scale relative to the best lake ranked. The surveys
+//     record no effort (net or trap nights), so this is raw catch, not CPUE.
+//   - size: the share of large fish among those measured in the recent
+//     surveys; large means preferred length for species with published length
+//     categories, otherwise the species' 75th percentile length across the lakes
+//   - recency: how long ago the species was last surveyed, halving every
+//     RecencyHalfLifeYears
+//   - trend: the latest catch against the average of the earlier recent
+//     surveys, from 0 (vanished) through 0.5 (steady) to 1
+//
+// metric ranks by the score or by one component. A page of limit lakes is
+// returned with the total number ranked.
+func (c *FishSurveyController) RankLakesContext(ctx context.Context, speciesName, county, state, metric string, limit int) (map[string]interface{}, error) {
+	settings := c.Rankings
+	code := c.ResolveSpecies(speciesName)
+	if code == "" {
+		return nil, fmt.Errorf("%w: unknown species %q", ErrInvalidRanking, speciesName)
+	}
+	if metric == "" {
+		metric = RankByScore
+	}
+	switch metric {
+	case RankByScore, RankByCatch, RankBySize, RankByRecency, RankByTrend:
+	default:
+		return nil, fmt.Errorf("%w: metric must be score, catch, size, recency or trend", ErrInvalidRanking)
+	}
+	if limit <= 0 {
+		limit = defaultRankingLimit
+	}
+	if limit > maxRankingLimit {
+		limit = maxRankingLimit
+	}
+	countyID := ""
+	countySet := make(map[string]bool)
+	if county != "" {
+		countyID = c.resolveCountyID(county, state)
+		if countyID == "" {
+			return nil, fmt.Errorf("%w: unknown county %q", ErrInvalidRanking, county)
+		}
+		countySet[strings.ToLower(countyID)] = true
+	}
+
+	index, err := c.BuildLakeIndexContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The large fish threshold: preferred length, or the 75th percentile of
+	// every fish measured in the lakes being ranked.
+	thresholds, hasThresholds := SpeciesSizeThresholds(code)
+	allCounts := make(map[int]int)
+	var candidates []*rankingCandidate
+	for _, lake := range index.ByDOW {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if countyID != "" && !anyInSet(c.Reconciler.lakeCountyIDs(*lake), countySet) {
+			continue
+		}
+		var speciesSurveys []model.Survey
+		for _, survey := range lake.Result.Surveys {
+			if surveyHasSpecies(survey, []string{code}) {
+				speciesSurveys = append(speciesSurveys, survey)
+				histogramCounts(allCounts, survey.Lengths[code])
+			}
+		}
+		if len(speciesSurveys) == 0 {
+			continue
+		}
+		sort.Slice(speciesSurveys, func(i, j int) bool {
+			return speciesSurveys[i].SurveyDate > speciesSurveys[j].SurveyDate
+		})
+		if len(speciesSurveys) > settings.RecentSurveys {
+			speciesSurveys = speciesSurveys[:settings.RecentSurveys]
+		}
+
+		candidate := &rankingCandidate{lake: lake, recent: speciesSurveys, counts: make(map[int]int)}
+		total := 0
+		for _, survey := range speciesSurveys {
+			counts, catch := lengthCountsOf(survey, code)
+			if catch == 0 {
+				// Without a catch summary the fish measured stand in for the catch.
+				catch = sumCounts(counts)
+			}
+			for length, quantity := range counts {
+				candidate.counts[length] += quantity
+			}
+			candidate.catches = append(candidate.catches, catch)
+			total += catch
+		}
+		candidate.catchPerSurvey = float64(total) / float64(len(speciesSurveys))
+		candidates = append(candidates, candidate)
+	}
+
+	largeLength, largeLabel := 0, ""
+	if hasThresholds {
+		largeLength = thresholds.Preferred
+		largeLabel = fmt.Sprintf("preferred length, %d in", thresholds.Preferred)
+	} else {
+		largeLength = lengthPercentile(allCounts, 0.75)
+		largeLabel = fmt.Sprintf("75th percentile length in these lakes, %d in", largeLength)
+	}
+
+	maxCatch := 0.0
+	for _, candidate := range candidates {
+		maxCatch = math.Max(maxCatch, candidate.catchPerSurvey)
+	}
+	weights := normalizeWeights(settings.Weights)
+	currentYear := time.Now().Year()
+
+	rankings := make([]LakeRanking, 0, len(candidates))
+	for _, candidate := range candidates {
+		ranking := LakeRanking{
+			DOWNumber:      candidate.lake.Result.DOWNumber,
+			LakeName:       candidate.lake.Result.LakeName,
+			CountyName:     candidate.lake.Result.CountyName,
+			State:          model.LakeState(*candidate.lake),
+			CatchPerSurvey: roundTo(candidate.catchPerSurvey, 1),
+			FishMeasured:   sumCounts(candidate.counts),
+			LastSurveyed:   candidate.recent[0].SurveyDate,
+		}
+		for _, survey := range candidate.recent {
+			ranking.RecentSurveys = append(ranking.RecentSurveys, survey.SurveyDate)
+		}
+
+		// Catch.
+		catchValue := 0.0
+		if maxCatch > 0 {
+			catchValue = math.Log1p(candidate.catchPerSurvey) / math.Log1p(maxCatch)
+		}
+		ranking.Breakdown.Catch = rankingComponent(catchValue, weights.Catch,
+			fmt.Sprintf("%.1f caught per survey over the last %s, not adjusted for effort", candidate.catchPerSurvey, pluralSurveys(len(candidate.recent))))
+
+		// Size structure.
+		sizeValue, sizeDetail := 0.0, "no fish measured in the recent surveys"
+		measured, large := 0, 0
+		for length, quantity := range candidate.counts {
+			if hasThresholds && length < thresholds.Stock {
+				continue
+			}
+			measured += quantity
+			if length >= largeLength {
+				large += quantity
+			}
+		}
+		if measured > 0 {
+			sizeValue = float64(large) / float64(measured)
+			share := roundTo(sizeValue, 3)
+			ranking.LargeFishShare = &share
+			population := "fish measured"
+			if hasThresholds {
+				population = "stock-length fish"
+			}
+			sizeDetail = fmt.Sprintf("%.0f%% of %d %s reached %s", sizeValue*100, measured, population, largeLabel)
+		}
+		ranking.Breakdown.Size = rankingComponent(sizeValue, weights.Size, sizeDetail)
+
+		// Recency.
+		lastYear := surveyYear(candidate.recent[0].SurveyDate)
+		age := currentYear - lastYear
+		if age < 0 {
+			age = 0
+		}
+		recencyValue := math.Pow(0.5, float64(age)/settings.RecencyHalfLifeYears)
+		ranking.Breakdown.Recency = rankingComponent(recencyValue, weights.Recency,
+			fmt.Sprintf("last surveyed in %d, %d years ago", lastYear, age))
+
+		// Trend.
+		trendValue, trendDetail := 0.5, "only one recent survey, no trend"
+		if len(candidate.catches) > 1 {
+			latest := float64(candidate.catches[0])
+			earlier := 0.0
+			for _, catch := range candidate.catches[1:] {
+				earlier += float64(catch)
+			}
+			earlier /= float64(len(candidate.catches) - 1)
+			change := 0.0
+			if larger := math.Max(latest, earlier); larger > 0 {
+				change = (latest - earlier) / larger
+			}
+			trendValue = (change + 1) / 2
+			trendDetail = fmt.Sprintf("latest catch %.0f against an average of %.1f in the %s before", latest, earlier, pluralSurveys(len(candidate.catches)-1))
+		}
+		ranking.Breakdown.Trend = rankingComponent(trendValue, weights.Trend, trendDetail)
+
+		b := ranking.Breakdown
+		ranking.Score = roundTo(b.Catch.Points+b.Size.Points+b.Recency.Points+b.Trend.Points, 1)
+		rankings = append(rankings, ranking)
+	}
+
+	sortValue := func(r LakeRanking) float64 {
+		switch metric {
+		case RankByCatch:
+			return r.Breakdown.Catch.Value
+		case RankBySize:
+			return r.Breakdown.Size.Value
+		case RankByRecency:
+			return r.Breakdown.Recency.Value
+		case RankByTrend:
+			return r.Breakdown.Trend.Value
+		default:
+			return r.Score
+		}
+	}
+	sort.Slice(rankings, func(i, j int) bool {
+		vi, vj := sortValue(rankings[i]), sortValue(rankings[j])
+		if vi != vj {
+			return vi > vj
+		}
+		if rankings[i].Score != rankings[j].Score {
+			return rankings[i].Score > rankings[j].Score
+		}
+		return rankings[i].DOWNumber < rankings[j].DOWNumber
+	})
+	for i := range rankings {
+		rankings[i].Rank = i + 1
+	}
+	total := len(rankings)
+	if len(rankings) > limit {
+		rankings = rankings[:limit]
+	}
+
+	species := c.Model.Species()[code]
+	return map[string]interface{}{
+		"species":    species.CommonName,
+		"species_id": species.ID,
+		"county_id":  countyID,
+		"metric":     metric,
+		"weights":    weights,
+		"large_fish": largeLabel,
+		"total":      total,
+		"data":       rankings,
+	}, nil
+}
+
+// rankingComponent rates one component of a score.
+func rankingComponent(value, weight float64, detail string) RankingComponent {
+	return RankingComponent{
+		Value:  roundTo(value, 3),
+		Weight: roundTo(weight, 3),
+		Points: roundTo(value*weight*100, 1),
+		Detail: detail,
+	}
+}
+
+// normalizeWeights scales the weights to sum to 1.
+func normalizeWeights(w model.RankingWeights) model.RankingWeights {
+	sum := w.Catch + w.Size + w.Recency + w.Trend
+	if sum <= 0 {
+		return DefaultRankingSettings().Weights
+	}
+	return model.RankingWeights{Catch: w.Catch / sum, Size: w.Size / sum, Recency: w.Recency / sum, Trend: w.Trend / sum}
+}
+
+// lengthPercentile returns the length below which the given fraction of the
+// fish fall, from fish counts keyed by length.
+func lengthPercentile(counts map[int]int, fraction float64) int {
+	lengths := make([]int, 0, len(counts))
+	total := 0
+	for length, quantity := range counts {
+		lengths = append(lengths, length)
+		total += quantity
+	}
+	sort.Ints(lengths)
+	seen := 0
+	for _, length := range lengths {
+		seen += counts[length]
+		if float64(seen) >= fraction*float64(total) {
+			return length
+		}
+	}
+	return 0
+}
+
+// pluralSurveys formats a survey count: "survey" or "3 surveys".
+func pluralSurveys(n int) string {
+	if n == 1 {
+		return "survey"
+	}
+	return fmt.Sprintf("%d surveys", n)
+}
+
+// resolveCountyID returns the ID of a county given by ID or by name, matched
+// like survey county names in state (the default state when empty), or "".
+func (c *FishSurveyController) resolveCountyID(county, state string) string {
+	if known, ok := c.Reconciler.CountyByID(county); ok {
+		return known.ID
+	}
+	if state == "" {
+		state = model.DefaultState
+	}
+	if match := c.Reconciler.MatchInState(state, county); match.Matched() {
+		return match.CountyID
+	}
+	return ""
+}
@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"fishreports/model"
+)
+
+func newRankingController(t *testing.T) *FishSurveyController {
+	t.Helper()
+	c := NewFishSurveyController(newFixtureModel(t))
+	c.Reconciler = newFixtureReconciler()
+	return c
+}
+
+// rankLakes ranks walleye lakes and returns them by lake name.
+func rankLakes(t *testing.T, c *FishSurveyController, county, metric string) ([]LakeRanking, map[string]LakeRanking) {
+	t.Helper()
+	result, err := c.RankLakesContext(context.Background(), "walleye", county, "", metric, 0)
+	if err != nil {
+		t.Fatalf("RankLakesContext: %v", err)
+	}
+	rankings := result["data"].([]LakeRanking)
+	byName := make(map[string]LakeRanking)
+	for _, ranking := range rankings {
+		byName[ranking.LakeName] = ranking
+	}
+	return rankings, byName
+}
+
+func TestRankLakesComponents(t *testing.T) {
+	rankings, byName := rankLakes(t, newRankingController(t), "", "")
+	if len(rankings) != 2 {
+		t.Fatalf("ranked %d lakes, want Big Lake and Long Lake", len(rankings))
+	}
+	big, long := byName["Big Lake"], byName["Long Lake"]
+
+	// Big Lake: 8 then 12 walleyes, 2 of 11 stock-length fish at 20 inches or more.
+	if big.CatchPerSurvey != 10 || big.Breakdown.Catch.Value != 1 {
+		t.Errorf("Big Lake catch = %v per survey, value %v; want 10, 1", big.CatchPerSurvey, big.Breakdown.Catch.Value)
+	}
+	if big.LargeFishShare == nil || *big.LargeFishShare != roundTo(2.0/11, 3) || big.FishMeasured != 11 {
+		t.Errorf("Big Lake large share = %v of %d, want %.3f of 11", big.LargeFishShare, big.FishMeasured, 2.0/11)
+	}
+	if big.Breakdown.Trend.Value != roundTo(1.0/3, 3) {
+		t.Errorf("Big Lake trend = %v, want 0.333", big.Breakdown.Trend.Value)
+	}
+	if len(big.RecentSurveys) != 2 || big.RecentSurveys[0] != "2021-07-02" || big.LastSurveyed != "2021-07-02" {
+		t.Errorf("Big Lake recent surveys = %v, last %s", big.RecentSurveys, big.LastSurveyed)
+	}
+
+	// Long Lake: one survey of 5 walleyes, both measured fish 20 inches.
+	if want := roundTo(math.Log1p(5)/math.Log1p(10), 3); long.Breakdown.Catch.Value != want {
+		t.Errorf("Long Lake catch value = %v, want %v", long.Breakdown.Catch.Value, want)
+	}
+	if long.Breakdown.Size.Value != 1 || long.Breakdown.Trend.Value != 0.5 {
+		t.Errorf("Long Lake size %v, trend %v; want 1, 0.5", long.Breakdown.Size.Value, long.Breakdown.Trend.Value)
+	}
+
+	age := float64(time.Now().Year() - 2021)
+	if want := roundTo(math.Pow(0.5, age/5), 3); big.Breakdown.Recency.Value != want {
+		t.Errorf("Big Lake recency = %v, want %v", big.Breakdown.Recency.Value, want)
+	}
+	for _, ranking := range rankings {
+		b := ranking.Breakdown
+		if sum := roundTo(b.Catch.Points+b.Size.Points+b.Recency.Points+b.Trend.Points, 1); ranking.Score != sum {
+			t.Errorf("%s score %v, want the sum of its points %v", ranking.LakeName, ranking.Score, sum)
+		}
+		if weights := b.Catch.Weight + b.Size.Weight + b.Recency.Weight + b.Trend.Weight; math.Abs(weights-1) > 0.002 {
+			t.Errorf("%s weights sum to %v", ranking.LakeName, weights)
+		}
+	}
+	if rankings[0].Rank != 1 || rankings[1].Rank != 2 || rankings[0].Score < rankings[1].Score {
+		t.Errorf("rankings out of order: %+v", rankings)
+	}
+}
+
+func TestRankLakesByMetric(t *testing.T) {
+	c := newRankingController(t)
+	tests := []struct {
+		metric string
+		first  string
+	}{
+		{RankByCatch, "Big Lake"},
+		{RankBySize, "Long Lake"},
+		{RankByTrend, "Long Lake"},
+		{RankByRecency, "Big Lake"},
+	}
+	for _, tt := range tests {
+		if rankings, _ := rankLakes(t, c, "", tt.metric); rankings[0].LakeName != tt.first {
+			t.Errorf("by %s: %s ranked first, want %s", tt.metric, rankings[0].LakeName, tt.first)
+		}
+	}
+}
+
+func TestRankLakesSettings(t *testing.T) {
+	c := newRankingController(t)
+	c.Rankings = RankingSettings{Weights: model.RankingWeights{Size: 2}, RecentSurveys: 1, RecencyHalfLifeYears: 5}
+	rankings, byName := rankLakes(t, c, "", "")
+	if rankings[0].LakeName != "Long Lake" || byName["Long Lake"].Score != 100 {
+		t.Errorf("size-only scores = %+v, want Long Lake at 100", rankings)
+	}
+	big := byName["Big Lake"]
+	if big.CatchPerSurvey != 8 || len(big.RecentSurveys) != 1 || big.Breakdown.Trend.Value != 0.5 {
+		t.Errorf("one recent survey: catch %v over %v, trend %v", big.CatchPerSurvey, big.RecentSurveys, big.Breakdown.Trend.Value)
+	}
+	// The latest survey's 4 walleyes at 16 inches and 1 at 25.
+	if big.Score != 20 {
+		t.Errorf("Big Lake score = %v, want 20", big.Score)
+	}
+
+	// A controller limited to one state keeps the settings.
+	if view := c.ForState(model.DefaultState); view.Rankings != c.Rankings {
+		t.Errorf("state view settings = %+v, want %+v", view.Rankings, c.Rankings)
+	}
+}
+
+func TestRankLakesInCounty(t *testing.T) {
+	rankings, _ := rankLakes(t, newRankingController(t), "Crow Wing", "")
+	if len(rankings) != 1 || rankings[0].LakeName != "Long Lake" {
+		t.Errorf("Crow Wing rankings = %+v, want only Long Lake, which spans into it", rankings)
+	}
+}
+
+func TestRankLakesErrors(t *testing.T) {
+	c := newRankingController(t)
+	tests := []struct{ species, county, metric string }{
+		{"unknown fish", "", ""},
+		{"walleye", "", "weight"},
+		{"walleye", "Atlantis", ""},
+	}
+	for _, tt := range tests {
+		if _, err := c.RankLakesContext(context.Background(), tt.species, tt.county, "", tt.metric, 0); !errors.Is(err, ErrInvalidRanking) {
+			t.Errorf("%+v: err = %v, want ErrInvalidRanking", tt, err)
+		}
+	}
+}
+
+func TestRankingSettingsValidate(t *testing.T) {
+	if err := DefaultRankingSettings().Validate(); err != nil {
+		t.Errorf("defaults: %v", err)
+	}
+	tests := []RankingSettings{
+		{Weights: model.RankingWeights{Catch: -1, Size: 2}, RecentSurveys: 3, RecencyHalfLifeYears: 5},
+		{Weights: model.RankingWeights{}, RecentSurveys: 3, RecencyHalfLifeYears: 5},
+		{Weights: model.RankingWeights{Catch: 1}, RecentSurveys: 0, RecencyHalfLifeYears: 5},
+		{Weights: model.RankingWeights{Catch: 1}, RecentSurveys: 3, RecencyHalfLifeYears: 0},
+	}
+	for _, settings := range tests {
+		if err := settings.Validate(); !errors.Is(err, ErrInvalidRanking) {
+			t.Errorf("%+v: err = %v, want ErrInvalidRanking", settings, err)
+		}
+	}
+}
+
+func TestNormalizeWeights(t *testing.T) {
+	got := normalizeWeights(model.RankingWeights{Catch: 2, Size: 1, Recency: 1})
+	if want := (model.RankingWeights{Catch: 0.5, Size: 0.25, Recency: 0.25}); got != want {
+		t.Errorf("normalizeWeights = %+v, want %+v", got, want)
+	}
+	if got := normalizeWeights(model.RankingWeights{}); got != DefaultRankingSettings().Weights {
+		t.Errorf("zero weights = %+v, want the defaults", got)
+	}
+}
+
+func TestLengthPercentile(t *testing.T) {
+	counts := map[int]int{10: 2, 12: 1, 15: 1}
+	for _, tt := range []struct {
+		fraction float64
+		want     int
+	}{{0.25, 10}, {0.5, 10}, {0.75, 12}, {1, 15}} {
+		if got := lengthPercentile(counts, tt.fraction); got != tt.want {
+			t.Errorf("lengthPercentile(%v) = %d, want %d", tt.fraction, got, tt.want)
+		}
+	}
+	if got := lengthPercentile(nil, 0.75); got != 0 {
+		t.Errorf("empty = %d, want 0", got)
+	}
+}
@@ -0,0 +1,314 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"fishreports/model"
+)
+
+// ReloadResult summarizes one data load.
+type ReloadResult struct {
+	LoadedAt       time.Time               `json:"loaded_at"`
+	TotalLakes     int                     `json:"total_lakes"`
+	TotalSurveys   int                     `json:"total_surveys"`
+	NewSurveys     int                     `json:"new_surveys"`
+	ChangedSurveys int                     `json:"changed_surveys"`
+	RemovedSurveys int                     `json:"removed_surveys"`
+	Validation     *model.ValidationReport `json:"validation,omitempty"`
+	Events         []model.SurveyEvent     `json:"events,omitempty"`
+}
+
+// DataReloader reloads survey data from disk, diffs it against the live data
+// by survey key and swaps the fresh data into the live model. The fingerprints
+// of the last load are persisted so surveys added, edited or removed while the
+// server was down are reported on the next start.
+type DataReloader struct {
+	Model            *model.FishSurveyModel
+	CountyController *CountyController
+	SurveyDir        string
+	States           []StateDataset    // every state's survey data; empty loads SurveyDir as the default state
+	Validation       *Validator        // rules reloaded and ingested surveys are run through
+	Reconciler       *CountyReconciler // groups lakes under their counties; LakeCountiesFile reloads into it
+	SurveyKeysFile   string
+	LakeCountiesFile string // reread on every reload when set
+	Events           *SurveyEventLog
+	lakeFiles        map[int][]string // files each lake was loaded from, for the ingest API
+	readOnlyLakes    map[int]string   // lakes from archive or CSV sources, which ingest can't rewrite
+	mu               sync.Mutex       // serializes reloads and ingests
+	latestDiff       *model.DatasetDiff
+	diffMu           sync.RWMutex // guards latestDiff, which is read while a reload runs
+}
+
+// NewDataReloader creates a reloader for the survey controller's live model,
+// loading its states, validating with its rules and matching counties with
+// its reconciler.
+func NewDataReloader(fish *FishSurveyController, countyController *CountyController, surveyDir, surveyKeysFile string, events *SurveyEventLog) *DataReloader {
+	return &DataReloader{
+		Model:            fish.Model,
+		CountyController: countyController,
+		SurveyDir:        surveyDir,
+		States:           fish.States,
+		Validation:       fish.Validation,
+		Reconciler:       fish.Reconciler,
+		SurveyKeysFile:   surveyKeysFile,
+		Events:           events,
+	}
+}
+
+// Baseline records the data loaded at startup. Surveys missing from or
+// changed since the persisted fingerprints of the previous run are published.
+// On the very first run there are no previous keys and nothing is reported.
+func (r *DataReloader) Baseline() (*ReloadResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fishDataByCounty, _ := r.Model.Snapshot()
+	r.lakeFiles = r.Model.LakeFiles
+	r.readOnlyLakes = r.Model.ReadOnlyLakes
+	previous, err := loadSurveyKeys(r.SurveyKeysFile)
+	if err != nil {
+		return nil, err
+	}
+	current := surveyFingerprints(fishDataByCounty)
+	var diff *model.DatasetDiff
+	if previous != nil {
+		diff = fingerprintDiff(previous, current, fishDataByCounty, r.SurveyKeysFile, "startup")
+	}
+	result, err := r.finish(nil, fishDataByCounty, current, diff)
+	if err != nil {
+		return nil, err
+	}
+	result.Validation = r.Model.Validation
+	return result, nil
+}
+
+// Reload loads the survey directory into a fresh model, swaps it in and
+// publishes events for surveys that are new or changed since the previous load.
+func (r *DataReloader) Reload(ctx context.Context) (*ReloadResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fresh := &model.FishSurveyModel{SpeciesMap: r.Model.Species()}
+	var err error
+	if len(r.States) > 0 {
+		err = LoadStateData(ctx, fresh, r.States, r.Validation)
+	} else {
+		err = LoadFishData(ctx, fresh, r.SurveyDir, r.States, r.Validation)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload fish survey data: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if r.LakeCountiesFile != "" {
+		lakeCounties, err := LoadLakeCounties(r.LakeCountiesFile)
+		if err != nil {
+			return nil, err
+		}
+		r.Reconciler.SetLakeCounties(lakeCounties)
+	}
+	MaterializeAggregates(fresh, r.Reconciler)
+
+	previousData, _ := r.Model.Snapshot()
+	current := surveyFingerprints(fresh.FishDataByCounty)
+	diff := DiffDatasets(previousData, fresh.FishDataByCounty, "previous load", "reload")
+
+	r.Model.Replace(fresh.FishDataByCounty, fresh.Aggregates)
+	r.lakeFiles = fresh.LakeFiles
+	r.readOnlyLakes = fresh.ReadOnlyLakes
+	if r.CountyController != nil {
+		counties := append([]model.County(nil), r.CountyController.GetCounties()...)
+		r.CountyController.SetCounties(EnhanceCountiesWithLakes(fresh, counties, r.Reconciler))
+	}
+	result, err := r.finish(previousData, fresh.FishDataByCounty, current, diff)
+	if err != nil {
+		return nil, err
+	}
+	result.Validation = fresh.Validation
+	return result, nil
+}
+
+// LatestDiff returns the diff computed by the most recent data load, or nil
+// before the first reload or ingest when there was nothing to compare against.
+func (r *DataReloader) LatestDiff() *model.DatasetDiff {
+	r.diffMu.RLock()
+	defer r.diffMu.RUnlock()
+	return r.latestDiff
+}
+
+// finish records the diff from the previous data, publishes its events,
+// persists the fingerprints and builds the result. A nil diff means there was
+// nothing to compare against.
+func (r *DataReloader) finish(previous, fishDataByCounty map[string][]model.FishData, fingerprints map[string]string, diff *model.DatasetDiff) (*ReloadResult, error) {
+	var events []model.SurveyEvent
+	if diff != nil {
+		events = diffEvents(diff, previous, fishDataByCounty, r.Model.Species(), r.Reconciler)
+		r.diffMu.Lock()
+		r.latestDiff = diff
+		r.diffMu.Unlock()
+	}
+	if r.Events != nil && len(events) > 0 {
+		events = r.Events.Publish(events)
+	}
+	if err := saveSurveyKeys(r.SurveyKeysFile, fingerprints); err != nil {
+		return nil, err
+	}
+
+	result := &ReloadResult{
+		LoadedAt:     time.Now().UTC(),
+		TotalSurveys: len(fingerprints),
+		Events:       events,
+	}
+	for _, fishDataList := range fishDataByCounty {
+		result.TotalLakes += len(fishDataList)
+	}
+	for _, event := range events {
+		switch event.Type {
+		case model.EventSurveyAdded:
+			result.NewSurveys++
+		case model.EventSurveyUpdated:
+			result.ChangedSurveys++
+		case model.EventSurveyRemoved:
+			result.RemovedSurveys++
+		}
+	}
+	log.Printf("✅ Data load: %d lakes, %d surveys, %d new, %d changed, %d removed",
+		result.TotalLakes, result.TotalSurveys, result.NewSurveys, result.ChangedSurveys, result.RemovedSurveys)
+	return result, nil
+}
+
+// surveyFingerprints maps every survey key in the data to a hash of the
+// survey's contents. The survey ID is left out because it may be assigned at
+// load time.
+func surveyFingerprints(fishDataByCounty map[string][]model.FishData) map[string]string {
+	fingerprints := make(map[string]string)
+	for _, fishDataList := range fishDataByCounty {
+		for _, data := range fishDataList {
+			for _, survey := range data.Result.Surveys {
+				fingerprints[model.SurveyKey(data.Result.DOWNumber, survey)] = surveyFingerprint(survey)
+			}
+		}
+	}
+	return fingerprints
+}
+
+// surveyFingerprint hashes a survey's JSON encoding without its ID and
+// quality flags.
+func surveyFingerprint(survey model.Survey) string {
+	survey.SurveyID = ""
+	survey.QualityFlags = nil
+	data, err := json.Marshal(survey)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16])
+}
+
+// newSurveyEvent describes one survey for the event stream.
+func newSurveyEvent(eventType, key string, data model.FishData, survey model.Survey, speciesMap map[string]model.Species, reconciler *CountyReconciler) model.SurveyEvent {
+	event := model.SurveyEvent{
+		Type:       eventType,
+		SurveyKey:  key,
+		SurveyID:   survey.SurveyID,
+		SurveyDate: survey.SurveyDate,
+		SurveyType: survey.SurveyType,
+		DOWNumber:  data.Result.DOWNumber,
+		State:      model.LakeState(data),
+		LakeName:   data.Result.LakeName,
+		CountyName: data.Result.CountyName,
+		CountyIDs:  reconciler.lakeCountyIDs(data),
+	}
+	if event.CountyIDs == nil {
+		event.CountyIDs = []string{}
+	}
+	for code := range survey.Lengths {
+		event.SpeciesCodes = append(event.SpeciesCodes, code)
+	}
+	sort.Strings(event.SpeciesCodes)
+	for _, code := range event.SpeciesCodes {
+		if species, exists := speciesMap[code]; exists {
+			event.SpeciesIDs = append(event.SpeciesIDs, species.ID)
+			if species.GameFish {
+				event.GameFish = true
+			}
+		}
+	}
+	return event
+}
+
+// loadSurveyKeys reads the persisted survey fingerprints keyed by survey key.
+// A plain list of keys is accepted with unknown fingerprints. A missing file
+// returns nil.
+func loadSurveyKeys(path string) (map[string]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	file, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read survey keys: %w", err)
+	}
+	var fingerprints map[string]string
+	if err := json.Unmarshal(file, &fingerprints); err == nil {
+		if fingerprints == nil {
+			fingerprints = make(map[string]string)
+		}
+		return fingerprints, nil
+	}
+	var list []string
+	if err := json.Unmarshal(file, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse survey keys: %w", err)
+	}
+	fingerprints = make(map[string]string, len(list))
+	for _, key := range list {
+		fingerprints[key] = ""
+	}
+	return fingerprints, nil
+}
+
+// saveSurveyKeys persists the survey fingerprints atomically.
+func saveSurveyKeys(path string, fingerprints map[string]string) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.Marshal(fingerprints)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic writes data to a temp file next to path and renames it into place.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
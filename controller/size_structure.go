@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"sort"
+
+	"fishreports/model"
+)
+
+// SizeThresholds are a species' minimum stock, quality, preferred, memorable
+// and trophy lengths in inches, used for proportional size distribution (PSD).
+type SizeThresholds struct {
+	Stock     int
+	Quality   int
+	Preferred int
+	Memorable int
+	Trophy    int
+}
+
+// speciesSizeThresholds holds the standard length categories (Gabelhouse
+// 1984 and later additions) for the species they are published for, keyed by
+// species code. Species without an entry get no PSD.
+var speciesSizeThresholds = map[string]SizeThresholds{
+	"BLC": {5, 8, 10, 12, 15},   // black crappie
+	"BLG": {3, 6, 8, 10, 12},    // bluegill
+	"CCF": {11, 16, 24, 28, 36}, // channel catfish
+	"CRP": {5, 8, 10, 12, 15},   // crappie
+	"FCF": {14, 20, 28, 34, 40}, // flathead catfish
+	"GSF": {3, 6, 8, 10, 12},    // green sunfish
+	"HCR": {5, 8, 10, 12, 15},   // hybrid crappie
+	"HSF": {3, 6, 8, 10, 12},    // hybrid sunfish
+	"LMB": {8, 12, 15, 20, 25},  // largemouth bass
+	"MUE": {20, 30, 38, 42, 50}, // muskellunge
+	"NOP": {14, 21, 28, 34, 44}, // northern pike
+	"RKB": {4, 7, 9, 11, 13},    // rock bass
+	"SAR": {8, 12, 15, 20, 25},  // sauger
+	"SMB": {7, 11, 14, 17, 20},  // smallmouth bass
+	"TME": {20, 30, 38, 42, 50}, // tiger muskellunge
+	"WAE": {10, 15, 20, 25, 30}, // walleye
+	"WAS": {10, 15, 20, 25, 30}, // walleye/sauger
+	"WHB": {6, 9, 12, 15, 18},   // white bass
+	"WHC": {5, 8, 10, 12, 15},   // white crappie
+	"YEP": {5, 8, 10, 12, 15},   // yellow perch
+	"YLB": {4, 7, 9, 11, 14},    // yellow bass
+}
+
+// SpeciesSizeThresholds returns the length categories of a species code.
+func SpeciesSizeThresholds(code string) (SizeThresholds, bool) {
+	thresholds, exists := speciesSizeThresholds[code]
+	return thresholds, exists
+}
+
+// SizeStructure is the proportional size distribution of a length sample:
+// the percentage of stock-length fish that reach each longer category.
+type SizeStructure struct {
+	StockFish int     `json:"stock_fish"` // fish at or above stock length
+	PSD       float64 `json:"psd"`        // quality length and longer
+	PSDP      float64 `json:"psd_p"`      // preferred
+	PSDM      float64 `json:"psd_m"`      // memorable
+	PSDT      float64 `json:"psd_t"`      // trophy
+}
+
+// sizeStructure computes the PSD values of fish counts keyed by length in
+// inches. It returns nil for species without length categories.
+func sizeStructure(code string, counts map[int]int) *SizeStructure {
+	thresholds, exists := speciesSizeThresholds[code]
+	if !exists {
+		return nil
+	}
+	var stock, quality, preferred, memorable, trophy int
+	for length, quantity := range counts {
+		if length < thresholds.Stock {
+			continue
+		}
+		stock += quantity
+		if length >= thresholds.Quality {
+			quality += quantity
+		}
+		if length >= thresholds.Preferred {
+			preferred += quantity
+		}
+		if length >= thresholds.Memorable {
+			memorable += quantity
+		}
+		if length >= thresholds.Trophy {
+			trophy += quantity
+		}
+	}
+	return &SizeStructure{
+		StockFish: stock,
+		PSD:       roundTo(percentOf(quality, stock), 1),
+		PSDP:      roundTo(percentOf(preferred, stock), 1),
+		PSDM:      roundTo(percentOf(memorable, stock), 1),
+		PSDT:      roundTo(percentOf(trophy, stock), 1),
+	}
+}
+
+// LengthStats summarizes a length sample in a histogram unit.
+type LengthStats struct {
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+}
+
+// lengthStats summarizes fish counts keyed by length in inches, converted to
+// unit. It returns nil for an empty sample.
+func lengthStats(counts map[int]int, unit string) *LengthStats {
+	lengths := make([]int, 0, len(counts))
+	total, sum := 0, 0
+	for length, quantity := range counts {
+		if quantity <= 0 {
+			continue
+		}
+		lengths = append(lengths, length)
+		total += quantity
+		sum += length * quantity
+	}
+	if total == 0 {
+		return nil
+	}
+	sort.Ints(lengths)
+
+	// The median is the length of the middle fish.
+	median, seen := 0, 0
+	for _, length := range lengths {
+		seen += counts[length]
+		if seen*2 >= total {
+			median = length
+			break
+		}
+	}
+	return &LengthStats{
+		Min:    roundTo(convertLength(float64(lengths[0]), unit), 2),
+		Max:    roundTo(convertLength(float64(lengths[len(lengths)-1]), unit), 2),
+		Mean:   roundTo(convertLength(float64(sum)/float64(total), unit), 2),
+		Median: roundTo(convertLength(float64(median), unit), 2),
+	}
+}
+
+// lengthCountsOf returns a survey's fish counts for one species keyed by
+// length, and its total catch from the catch summaries.
+func lengthCountsOf(survey model.Survey, code string) (map[int]int, int) {
+	counts := make(map[int]int)
+	histogramCounts(counts, survey.Lengths[code])
+	return counts, totalCatches(survey)[code]
+}
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	"fishreports/model"
+)
+
+func TestSizeStructure(t *testing.T) {
+	// Walleye: stock 10, quality 15, preferred 20, memorable 25, trophy 30.
+	walleyes := map[int]int{8: 5, 10: 10, 14: 10, 15: 5, 19: 5, 20: 4, 24: 4, 25: 1, 30: 1}
+	tests := []struct {
+		name   string
+		code   string
+		counts map[int]int
+		want   *SizeStructure
+	}{
+		{"walleye", "WAE", walleyes, &SizeStructure{StockFish: 40, PSD: 50, PSDP: 25, PSDM: 5, PSDT: 2.5}},
+		{"rounded", "WAE", map[int]int{10: 2, 16: 1}, &SizeStructure{StockFish: 3, PSD: 33.3}},
+		{"bounds are inclusive", "BLG", map[int]int{3: 1, 6: 1, 8: 1, 10: 1, 12: 1}, &SizeStructure{StockFish: 5, PSD: 80, PSDP: 60, PSDM: 40, PSDT: 20}},
+		{"no stock-length fish", "NOP", map[int]int{10: 4, 13: 2}, &SizeStructure{}},
+		{"empty sample", "YEP", map[int]int{}, &SizeStructure{}},
+		{"no length categories", "XYZ", walleyes, nil},
+	}
+	for _, tt := range tests {
+		if got := sizeStructure(tt.code, tt.counts); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: sizeStructure = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSpeciesSizeThresholds(t *testing.T) {
+	thresholds, ok := SpeciesSizeThresholds("NOP")
+	if !ok || thresholds != (SizeThresholds{14, 21, 28, 34, 44}) {
+		t.Errorf("NOP thresholds = %+v, %v", thresholds, ok)
+	}
+	if _, ok := SpeciesSizeThresholds("XYZ"); ok {
+		t.Error("XYZ has thresholds")
+	}
+	for code, s := range speciesSizeThresholds {
+		if !(s.Stock < s.Quality && s.Quality < s.Preferred && s.Preferred < s.Memorable && s.Memorable < s.Trophy) {
+			t.Errorf("%s thresholds %+v are not increasing", code, s)
+		}
+	}
+}
+
+func TestLengthStats(t *testing.T) {
+	counts := map[int]int{10: 1, 12: 2, 15: 0, 20: 1}
+	if got, want := lengthStats(counts, "in"), (&LengthStats{Min: 10, Max: 20, Mean: 13.5, Median: 12}); !reflect.DeepEqual(got, want) {
+		t.Errorf("inches = %+v, want %+v", got, want)
+	}
+	if got, want := lengthStats(counts, "cm"), (&LengthStats{Min: 25.4, Max: 50.8, Mean: 34.29, Median: 30.48}); !reflect.DeepEqual(got, want) {
+		t.Errorf("centimeters = %+v, want %+v", got, want)
+	}
+	if got := lengthStats(map[int]int{12: 0}, "in"); got != nil {
+		t.Errorf("empty sample = %+v, want nil", got)
+	}
+}
+
+func TestLengthCountsOf(t *testing.T) {
+	survey := fixtureSurvey("s1", "2020-06-01",
+		map[string]int{"WAE": 12},
+		map[string][]model.FishCount{"WAE": {{Length: 14, Quantity: 3}, {Length: 14, Quantity: 2}, {Length: 18, Quantity: 1}}, "NOP": nil})
+	counts, catch := lengthCountsOf(survey, "WAE")
+	if !reflect.DeepEqual(counts, map[int]int{14: 5, 18: 1}) || catch != 12 {
+		t.Errorf("WAE = %v, catch %d", counts, catch)
+	}
+	if counts, catch := lengthCountsOf(survey, "NOP"); len(counts) != 0 || catch != 0 {
+		t.Errorf("NOP = %v, catch %d; want nothing", counts, catch)
+	}
+}
@@ -0,0 +1,339 @@
+package controller
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"fishreports/model"
+)
+
+// SnapshotVersion is the version of the snapshot payload. Bump it whenever
+// the model types or the way surveys are parsed change, so snapshots built
+// by older binaries are rebuilt instead of loaded.
+const SnapshotVersion = 1
+
+// snapshotMagic starts every snapshot file. It is followed by the payload
+// version (uint32), the payload length (uint64) and the payload's SHA-256,
+// all big-endian, and then the gob-encoded payload.
+var snapshotMagic = [8]byte{'F', 'R', 'S', 'N', 'A', 'P', 0, 1}
+
+const snapshotHeaderSize = 8 + 4 + 8 + sha256.Size
+
+var (
+	// ErrSnapshotVersion is returned for snapshots built by another version.
+	ErrSnapshotVersion = errors.New("snapshot was built by an incompatible version")
+	// ErrSnapshotCorrupt is returned for truncated or damaged snapshots.
+	ErrSnapshotCorrupt = errors.New("snapshot is corrupt")
+	// ErrSnapshotStale is returned when the inputs changed since the build.
+	ErrSnapshotStale = errors.New("snapshot is stale")
+)
+
+// DatasetSnapshot is the parsed dataset written by build-snapshot: counties
+// and species with their resolved IDs and every lake already parsed,
+// validated and indexed by county. Aggregates are not stored; they are
+// rebuilt from the lakes in a fraction of the time parsing takes.
+type DatasetSnapshot struct {
+	Version          int
+	BuiltAt          time.Time
+	Fingerprint      string // InputFingerprint of the inputs it was built from
+	Counties         []model.County
+	Species          map[string]model.Species
+	FishDataByCounty map[string][]model.FishData
+	LakeFiles        map[int][]string
+	ReadOnlyLakes    map[int]string
+	Validation       *model.ValidationReport
+	NilLengths       []nilLengths // length entries set to nil, which gob can't encode
+}
+
+// nilLengths locates a survey's nil length data for a species.
+type nilLengths struct {
+	County  string
+	Lake    int // index in the county's lakes
+	Survey  int // index in the lake's surveys
+	Species string
+}
+
+// encodable returns a copy of the snapshot that gob can encode: nil length
+// entries are left out and recorded in NilLengths. Only the lakes, surveys
+// and maps holding them are copied.
+func (s *DatasetSnapshot) encodable() *DatasetSnapshot {
+	out := *s
+	out.NilLengths = nil
+	copied := false
+	for county, fishDataList := range s.FishDataByCounty {
+		for lake, data := range fishDataList {
+			for survey, surveyData := range data.Result.Surveys {
+				var lengths map[string]*model.LengthData
+				for code, lengthData := range surveyData.Lengths {
+					if lengthData != nil {
+						continue
+					}
+					if lengths == nil {
+						lengths = make(map[string]*model.LengthData, len(surveyData.Lengths))
+						for code, lengthData := range surveyData.Lengths {
+							if lengthData != nil {
+								lengths[code] = lengthData
+							}
+						}
+					}
+					out.NilLengths = append(out.NilLengths, nilLengths{County: county, Lake: lake, Survey: survey, Species: code})
+				}
+				if lengths == nil {
+					continue
+				}
+				if !copied {
+					out.FishDataByCounty = make(map[string][]model.FishData, len(s.FishDataByCounty))
+					for county, fishDataList := range s.FishDataByCounty {
+						out.FishDataByCounty[county] = fishDataList
+					}
+					copied = true
+				}
+				// Copy the county's lakes and the lake's surveys once each,
+				// while they still share the snapshot's arrays.
+				lakes := out.FishDataByCounty[county]
+				if &lakes[0] == &fishDataList[0] {
+					lakes = append([]model.FishData(nil), fishDataList...)
+					out.FishDataByCounty[county] = lakes
+				}
+				surveys := lakes[lake].Result.Surveys
+				if &surveys[0] == &data.Result.Surveys[0] {
+					surveys = append([]model.Survey(nil), data.Result.Surveys...)
+					lakes[lake].Result.Surveys = surveys
+				}
+				surveys[survey].Lengths = lengths
+			}
+		}
+	}
+	return &out
+}
+
+// restoreNilLengths puts back the nil length entries left out by encodable.
+func (s *DatasetSnapshot) restoreNilLengths() error {
+	for _, entry := range s.NilLengths {
+		lakes := s.FishDataByCounty[entry.County]
+		if entry.Lake < 0 || entry.Lake >= len(lakes) {
+			return fmt.Errorf("%w: no lake %d in %s", ErrSnapshotCorrupt, entry.Lake, entry.County)
+		}
+		surveys := lakes[entry.Lake].Result.Surveys
+		if entry.Survey < 0 || entry.Survey >= len(surveys) {
+			return fmt.Errorf("%w: no survey %d in lake %d of %s", ErrSnapshotCorrupt, entry.Survey, entry.Lake, entry.County)
+		}
+		if surveys[entry.Survey].Lengths == nil {
+			surveys[entry.Survey].Lengths = make(map[string]*model.LengthData)
+		}
+		surveys[entry.Survey].Lengths[entry.Species] = nil
+	}
+	s.NilLengths = nil
+	return nil
+}
+
+// NewDatasetSnapshot captures a loaded model and its counties.
+func NewDatasetSnapshot(m *model.FishSurveyModel, counties []model.County, fingerprint string) *DatasetSnapshot {
+	fishDataByCounty, _ := m.Snapshot()
+	return &DatasetSnapshot{
+		Version:          SnapshotVersion,
+		BuiltAt:          time.Now().UTC(),
+		Fingerprint:      fingerprint,
+		Counties:         counties,
+		Species:          m.Species(),
+		FishDataByCounty: fishDataByCounty,
+		LakeFiles:        m.LakeFiles,
+		ReadOnlyLakes:    m.ReadOnlyLakes,
+		Validation:       m.Validation,
+	}
+}
+
+// Lakes counts the lakes in the snapshot.
+func (s *DatasetSnapshot) Lakes() int {
+	lakes := make(map[int]bool)
+	for _, fishDataList := range s.FishDataByCounty {
+		for _, data := range fishDataList {
+			lakes[data.Result.DOWNumber] = true
+		}
+	}
+	return len(lakes)
+}
+
+// Surveys counts the surveys in the snapshot.
+func (s *DatasetSnapshot) Surveys() int {
+	surveys := 0
+	for _, fishDataList := range s.FishDataByCounty {
+		for _, data := range fishDataList {
+			surveys += len(data.Result.Surveys)
+		}
+	}
+	return surveys
+}
+
+// Restore loads the snapshot's species and survey data into a model. Callers
+// run MaterializeAggregates afterwards.
+func (s *DatasetSnapshot) Restore(m *model.FishSurveyModel) {
+	m.SpeciesMap = s.Species
+	m.FishDataByCounty = s.FishDataByCounty
+	m.LakeFiles = s.LakeFiles
+	m.ReadOnlyLakes = s.ReadOnlyLakes
+	m.Validation = s.Validation
+	if m.Validation == nil {
+		m.Validation = model.NewValidationReport()
+	}
+}
+
+// WriteSnapshot encodes a snapshot and writes it atomically, returning its size.
+func WriteSnapshot(path string, snapshot *DatasetSnapshot) (int, error) {
+	var payload bytes.Buffer
+	if err := gob.NewEncoder(&payload).Encode(snapshot.encodable()); err != nil {
+		return 0, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	checksum := sha256.Sum256(payload.Bytes())
+
+	data := make([]byte, snapshotHeaderSize, snapshotHeaderSize+payload.Len())
+	copy(data, snapshotMagic[:])
+	binary.BigEndian.PutUint32(data[8:], SnapshotVersion)
+	binary.BigEndian.PutUint64(data[12:], uint64(payload.Len()))
+	copy(data[20:], checksum[:])
+	data = append(data, payload.Bytes()...)
+	if err := writeFileAtomic(path, data); err != nil {
+		return 0, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return len(data), nil
+}
+
+// ReadSnapshot reads and verifies a snapshot file.
+func ReadSnapshot(path string) (*DatasetSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < snapshotHeaderSize || !bytes.Equal(data[:8], snapshotMagic[:]) {
+		return nil, fmt.Errorf("%w: not a dataset snapshot", ErrSnapshotCorrupt)
+	}
+	if version := binary.BigEndian.Uint32(data[8:]); version != SnapshotVersion {
+		return nil, fmt.Errorf("%w: version %d, want %d", ErrSnapshotVersion, version, SnapshotVersion)
+	}
+	payload := data[snapshotHeaderSize:]
+	if length := binary.BigEndian.Uint64(data[12:]); length != uint64(len(payload)) {
+		return nil, fmt.Errorf("%w: payload is %d bytes, header says %d", ErrSnapshotCorrupt, len(payload), length)
+	}
+	if checksum := sha256.Sum256(payload); !bytes.Equal(checksum[:], data[20:snapshotHeaderSize]) {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrSnapshotCorrupt)
+	}
+
+	var snapshot DatasetSnapshot
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSnapshotCorrupt, err)
+	}
+	if snapshot.Version != SnapshotVersion {
+		return nil, fmt.Errorf("%w: version %d, want %d", ErrSnapshotVersion, snapshot.Version, SnapshotVersion)
+	}
+	if err := snapshot.restoreNilLengths(); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// LoadFreshSnapshot reads a snapshot and checks it was built from inputs
+// with the given fingerprint, returning ErrSnapshotStale when they changed.
+func LoadFreshSnapshot(path, fingerprint string) (*DatasetSnapshot, error) {
+	snapshot, err := ReadSnapshot(path)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.Fingerprint != fingerprint {
+		return nil, fmt.Errorf("%w: the data files changed after it was built at %s",
+			ErrSnapshotStale, snapshot.BuiltAt.Format(time.RFC3339))
+	}
+	return snapshot, nil
+}
+
+// PathSource is a SurveySource read from files. Snapshots fingerprint the
+// paths to notice changed input; data from other sources can't be snapshotted.
+type PathSource interface {
+	Paths() []string
+}
+
+// InputFingerprint hashes the settings and the name, size and modification
+// time of every input file, including each file under the states' survey
+// directories and sources. It stats files without reading them, so checking
+// a snapshot stays cheap.
+func InputFingerprint(files []string, states []StateDataset, settings interface{}) (string, error) {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "snapshot v%d\n", SnapshotVersion)
+	encoded, err := json.Marshal(settings)
+	if err != nil {
+		return "", err
+	}
+	hash.Write(encoded)
+	hash.Write([]byte{'\n'})
+
+	paths := append([]string(nil), files...)
+	for _, state := range states {
+		sourcePaths, ok := surveySourcePaths(state.Source())
+		if !ok {
+			return "", fmt.Errorf("the survey sources of %s can't be fingerprinted", state.Code)
+		}
+		paths = append(paths, sourcePaths...)
+	}
+
+	for _, path := range paths {
+		fmt.Fprintf(hash, "%s\n", path)
+		err := filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					fmt.Fprintf(hash, "  missing\n")
+					return nil
+				}
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(hash, "  %s %d %d\n", file, info.Size(), info.ModTime().UnixNano())
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// surveySourcePaths returns the paths a source reads, looking inside
+// MultiSource, and false when any of them isn't a PathSource.
+func surveySourcePaths(source SurveySource) ([]string, bool) {
+	switch s := source.(type) {
+	case MultiSource:
+		var paths []string
+		for _, member := range s {
+			memberPaths, ok := surveySourcePaths(member)
+			if !ok {
+				return nil, false
+			}
+			paths = append(paths, memberPaths...)
+		}
+		return paths, true
+	case PathSource:
+		return s.Paths(), true
+	default:
+		return nil, false
+	}
+}
+
+func (s JSONDirSource) Paths() []string  { return []string{s.Dir} }
+func (s GzipJSONSource) Paths() []string { return []string{s.Dir} }
+func (s ZipSource) Paths() []string      { return []string{s.Path} }
+func (s NDJSONSource) Paths() []string   { return []string{s.Path} }
+func (s CSVSource) Paths() []string      { return []string{s.Path} }
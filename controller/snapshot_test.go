@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"fishreports/model"
+)
+
+// writeTestSnapshot writes a snapshot of the fixture data and returns its
+// path and contents.
+func writeTestSnapshot(t *testing.T) (string, *DatasetSnapshot) {
+	t.Helper()
+	m := newFixtureModel(t)
+	m.LakeFiles = map[int][]string{1000100: {"data/surveys/1000100.json"}}
+	m.ReadOnlyLakes = map[int]string{11000200: "data/archive.zip"}
+	m.Validation = &model.ValidationReport{Surveys: 4, FlaggedSurveys: 1, Rules: map[string]*model.RuleReport{
+		"unknown_species": {Severity: model.SeverityFlag, Issues: 1, Surveys: 1, Flagged: 1},
+	}}
+	snapshot := NewDatasetSnapshot(m, fixtureCounties(), "fingerprint")
+	path := filepath.Join(t.TempDir(), "dataset.snap")
+	size, err := WriteSnapshot(path, snapshot)
+	if err != nil {
+		t.Fatalf("WriteSnapshot: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != int64(size) {
+		t.Fatalf("snapshot file %v, %v; want %d bytes", info, err, size)
+	}
+	return path, snapshot
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	path, written := writeTestSnapshot(t)
+	read, err := LoadFreshSnapshot(path, "fingerprint")
+	if err != nil {
+		t.Fatalf("LoadFreshSnapshot: %v", err)
+	}
+	if !read.BuiltAt.Equal(written.BuiltAt) || read.Version != SnapshotVersion {
+		t.Errorf("built at %v version %d, want %v version %d", read.BuiltAt, read.Version, written.BuiltAt, SnapshotVersion)
+	}
+	read.BuiltAt = written.BuiltAt
+	// Long Lake's nil length data survives the round trip.
+	if !reflect.DeepEqual(read, written) {
+		t.Errorf("read snapshot\n%+v\ndiffers from written\n%+v", read, written)
+	}
+	if lengths := written.FishDataByCounty["Cass"][0].Result.Surveys[0].Lengths; len(lengths) != 3 || lengths["NOP"] != nil {
+		t.Errorf("writing changed the snapshot's length data: %v", lengths)
+	}
+	if read.Lakes() != 3 || read.Surveys() != 4 {
+		t.Errorf("%d lakes, %d surveys; want 3, 4", read.Lakes(), read.Surveys())
+	}
+
+	m := &model.FishSurveyModel{}
+	read.Restore(m)
+	if !reflect.DeepEqual(m.FishDataByCounty, written.FishDataByCounty) || !reflect.DeepEqual(m.SpeciesMap, written.Species) {
+		t.Error("restored model differs from the snapshot")
+	}
+	if m.Validation.FlaggedSurveys != 1 || m.ReadOnlyLakes[11000200] != "data/archive.zip" {
+		t.Errorf("restored validation %+v, read-only lakes %v", m.Validation, m.ReadOnlyLakes)
+	}
+}
+
+func TestSnapshotDetectsDamage(t *testing.T) {
+	path, _ := writeTestSnapshot(t)
+	original, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	damaged := func(change func(data []byte) []byte) string {
+		data := change(append([]byte(nil), original...))
+		damagedPath := filepath.Join(t.TempDir(), "damaged.snap")
+		if err := os.WriteFile(damagedPath, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return damagedPath
+	}
+	tests := []struct {
+		name   string
+		change func(data []byte) []byte
+		want   error
+	}{
+		{"flipped payload byte", func(data []byte) []byte { data[len(data)-10] ^= 0xff; return data }, ErrSnapshotCorrupt},
+		{"flipped checksum byte", func(data []byte) []byte { data[25] ^= 0xff; return data }, ErrSnapshotCorrupt},
+		{"truncated payload", func(data []byte) []byte { return data[:len(data)-1] }, ErrSnapshotCorrupt},
+		{"appended bytes", func(data []byte) []byte { return append(data, 0) }, ErrSnapshotCorrupt},
+		{"truncated header", func(data []byte) []byte { return data[:snapshotHeaderSize-1] }, ErrSnapshotCorrupt},
+		{"wrong magic", func(data []byte) []byte { copy(data, "NOTSNAP!"); return data }, ErrSnapshotCorrupt},
+		{"other version", func(data []byte) []byte {
+			binary.BigEndian.PutUint32(data[8:], SnapshotVersion+1)
+			return data
+		}, ErrSnapshotVersion},
+	}
+	for _, tt := range tests {
+		if _, err := ReadSnapshot(damaged(tt.change)); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestLoadFreshSnapshotRejectsStaleInputs(t *testing.T) {
+	path, _ := writeTestSnapshot(t)
+	if _, err := LoadFreshSnapshot(path, "other fingerprint"); !errors.Is(err, ErrSnapshotStale) {
+		t.Errorf("err = %v, want ErrSnapshotStale", err)
+	}
+	if _, err := LoadFreshSnapshot(filepath.Join(t.TempDir(), "missing.snap"), "fingerprint"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing file err = %v, want not exist", err)
+	}
+}
+
+// documentSource is a survey source that isn't read from files.
+type documentSource struct{}
+
+func (documentSource) Name() string                                      { return "documents" }
+func (documentSource) Documents(fn func(doc SurveyDocument) error) error { return nil }
+
+func TestInputFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	surveyDir := filepath.Join(dir, "surveys")
+	if err := os.MkdirAll(surveyDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	species := filepath.Join(dir, "species.json")
+	write := func(path, contents string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(species, "{}")
+	write(filepath.Join(surveyDir, "1.json"), `{"a": 1}`)
+	states := []StateDataset{DefaultStateDataset("", surveyDir)}
+	settings := map[string]interface{}{"validation": map[string]string{}}
+	fingerprint := func(settings interface{}) string {
+		t.Helper()
+		got, err := InputFingerprint([]string{species, filepath.Join(dir, "missing.json")}, states, settings)
+		if err != nil {
+			t.Fatalf("InputFingerprint: %v", err)
+		}
+		return got
+	}
+
+	base := fingerprint(settings)
+	if again := fingerprint(settings); again != base {
+		t.Errorf("fingerprint changed without changes: %s, %s", base, again)
+	}
+	if other := fingerprint(map[string]interface{}{"validation": map[string]string{"zero_length": "off"}}); other == base {
+		t.Error("changed settings kept the fingerprint")
+	}
+
+	write(filepath.Join(surveyDir, "2.json"), `{}`)
+	added := fingerprint(settings)
+	if added == base {
+		t.Error("an added survey file kept the fingerprint")
+	}
+	write(filepath.Join(surveyDir, "2.json"), `{"b": 2}`)
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(surveyDir, "2.json"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if edited := fingerprint(settings); edited == added {
+		t.Error("an edited survey file kept the fingerprint")
+	}
+
+	states[0].Sources = []SurveySource{documentSource{}}
+	if _, err := InputFingerprint(nil, states, settings); err == nil {
+		t.Error("a source without paths was fingerprinted")
+	}
+}
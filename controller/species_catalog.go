@@ -0,0 +1,242 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"fishreports/model"
+)
+
+var (
+	// ErrInvalidSpecies is returned when a species entry fails validation.
+	ErrInvalidSpecies = errors.New("invalid species")
+	// ErrSpeciesExists is returned when creating a species whose code is taken.
+	ErrSpeciesExists = errors.New("species code already exists")
+)
+
+// SpeciesInput holds the editable fields of a species. On update, fields left
+// out of the request (nil) keep their current values.
+type SpeciesInput struct {
+	Code           string  `json:"code"`
+	CommonName     *string `json:"common_name"`
+	ScientificName *string `json:"scientific_name"`
+	GameFish       *bool   `json:"game_fish"`
+	SpeciesGroup   *string `json:"species_group"`
+	ImageURL       *string `json:"image_url"`
+	Description    *string `json:"description"`
+}
+
+// apply copies the given fields onto a species.
+func (in SpeciesInput) apply(species *model.Species) {
+	if in.CommonName != nil {
+		species.CommonName = capitalizeFirst(strings.TrimSpace(*in.CommonName))
+	}
+	if in.ScientificName != nil {
+		species.ScientificName = strings.TrimSpace(*in.ScientificName)
+	}
+	if in.GameFish != nil {
+		species.GameFish = *in.GameFish
+	}
+	if in.SpeciesGroup != nil {
+		species.SpeciesGroup = strings.TrimSpace(*in.SpeciesGroup)
+	}
+	if in.ImageURL != nil {
+		species.ImageURL = strings.TrimSpace(*in.ImageURL)
+	}
+	if in.Description != nil {
+		species.Description = strings.TrimSpace(*in.Description)
+	}
+}
+
+// UnknownSpeciesCode reports a species code found in survey data but missing
+// from the species catalog.
+type UnknownSpeciesCode struct {
+	Code         string `json:"code"`
+	Surveys      int    `json:"surveys"`
+	Lakes        int    `json:"lakes"`
+	FishMeasured int    `json:"fish_measured"`
+	TotalCatch   int    `json:"total_catch"`
+	FirstSeen    string `json:"first_seen"`
+	LastSeen     string `json:"last_seen"`
+	DOWNumbers   []int  `json:"dow_numbers"` // up to 20 lakes, lowest DOW first
+}
+
+// SpeciesCatalog manages the species catalog: edits are applied to a copy,
+// written back to the species file and swapped into the model together with
+// recomputed aggregates.
+type SpeciesCatalog struct {
+	Reloader *DataReloader // edits are serialized with data reloads
+	File     string
+	Statuses map[string][]string // status categories by species code, reapplied after each edit
+}
+
+// NewSpeciesCatalog creates a catalog manager persisting to file.
+func NewSpeciesCatalog(reloader *DataReloader, file string, statuses map[string][]string) *SpeciesCatalog {
+	return &SpeciesCatalog{Reloader: reloader, File: file, Statuses: statuses}
+}
+
+// ListSpecies returns every catalog entry sorted by code.
+func (sc *SpeciesCatalog) ListSpecies() []model.Species {
+	speciesMap := sc.Reloader.Model.Species()
+	list := make([]model.Species, 0, len(speciesMap))
+	for _, species := range speciesMap {
+		list = append(list, species)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
+
+// GetSpecies returns a catalog entry by code, or nil.
+func (sc *SpeciesCatalog) GetSpecies(code string) *model.Species {
+	species, exists := sc.Reloader.Model.Species()[strings.ToUpper(code)]
+	if !exists {
+		return nil
+	}
+	return &species
+}
+
+// CreateSpecies adds a species. The code and common name are required.
+func (sc *SpeciesCatalog) CreateSpecies(input SpeciesInput) (model.Species, error) {
+	code := strings.ToUpper(strings.TrimSpace(input.Code))
+	var created model.Species
+	if code == "" || strings.ContainsAny(code, " \t/") {
+		return created, fmt.Errorf("%w: code is required and may not contain spaces or slashes", ErrInvalidSpecies)
+	}
+	if input.CommonName == nil || strings.TrimSpace(*input.CommonName) == "" {
+		return created, fmt.Errorf("%w: common_name is required", ErrInvalidSpecies)
+	}
+
+	err := sc.edit(func(speciesMap map[string]model.Species) error {
+		if _, exists := speciesMap[code]; exists {
+			return fmt.Errorf("%w: %s", ErrSpeciesExists, code)
+		}
+		created = model.Species{ID: StableSpeciesID(code), Code: code}
+		input.apply(&created)
+		speciesMap[code] = created
+		return nil
+	})
+	return created, err
+}
+
+// UpdateSpecies edits a species, returning nil when the code isn't in the catalog.
+func (sc *SpeciesCatalog) UpdateSpecies(code string, input SpeciesInput) (*model.Species, error) {
+	code = strings.ToUpper(code)
+	if input.CommonName != nil && strings.TrimSpace(*input.CommonName) == "" {
+		return nil, fmt.Errorf("%w: common_name may not be empty", ErrInvalidSpecies)
+	}
+
+	var updated *model.Species
+	err := sc.edit(func(speciesMap map[string]model.Species) error {
+		species, exists := speciesMap[code]
+		if !exists {
+			return nil
+		}
+		input.apply(&species)
+		speciesMap[code] = species
+		updated = &species
+		return nil
+	})
+	return updated, err
+}
+
+// DeleteSpecies removes a species, reporting whether it existed. Survey data
+// using the code is kept and shows up as unknown.
+func (sc *SpeciesCatalog) DeleteSpecies(code string) (bool, error) {
+	code = strings.ToUpper(code)
+	deleted := false
+	err := sc.edit(func(speciesMap map[string]model.Species) error {
+		if _, exists := speciesMap[code]; exists {
+			delete(speciesMap, code)
+			deleted = true
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// edit applies a change to a copy of the catalog, persists it and swaps it in.
+func (sc *SpeciesCatalog) edit(change func(speciesMap map[string]model.Species) error) error {
+	r := sc.Reloader
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := r.Model.Species()
+	speciesMap := make(map[string]model.Species, len(current)+1)
+	for code, species := range current {
+		speciesMap[code] = species
+	}
+	if err := change(speciesMap); err != nil {
+		return err
+	}
+	ApplySpeciesStatuses(speciesMap, sc.Statuses)
+
+	// Statuses come from the species status list, so they aren't saved.
+	saved := make(map[string]model.Species, len(speciesMap))
+	for code, species := range speciesMap {
+		species.Status = nil
+		saved[code] = species
+	}
+	data, err := json.MarshalIndent(saved, "", "    ")
+	if err != nil {
+		return err
+	}
+	if sc.File != "" {
+		if err := writeFileAtomic(sc.File, data); err != nil {
+			return fmt.Errorf("failed to save species catalog: %w", err)
+		}
+	}
+
+	// Species IDs and unknown codes feed the aggregates, so rebuild them.
+	fishDataByCounty, _ := r.Model.Snapshot()
+	fresh := &model.FishSurveyModel{FishDataByCounty: fishDataByCounty, SpeciesMap: speciesMap}
+	MaterializeAggregates(fresh, r.Reconciler)
+	r.Model.ReplaceSpecies(speciesMap, fresh.Aggregates)
+	return nil
+}
+
+// UnknownSpecies lists the species codes in the survey data that are missing
+// from the catalog, most frequent first.
+func (sc *SpeciesCatalog) UnknownSpecies() []UnknownSpeciesCode {
+	_, agg := sc.Reloader.Model.Snapshot()
+	return UnknownSpeciesCodes(agg)
+}
+
+// UnknownSpeciesCodes lists the unknown species codes recorded in aggregates,
+// most frequent first.
+func UnknownSpeciesCodes(agg *model.Aggregates) []UnknownSpeciesCode {
+	list := []UnknownSpeciesCode{}
+	if agg == nil {
+		return list
+	}
+	for _, unknown := range agg.UnknownSpecies {
+		dows := make([]int, 0, len(unknown.Lakes))
+		for dow := range unknown.Lakes {
+			dows = append(dows, dow)
+		}
+		sort.Ints(dows)
+		entry := UnknownSpeciesCode{
+			Code:         unknown.Code,
+			Surveys:      unknown.Surveys,
+			Lakes:        len(dows),
+			FishMeasured: unknown.FishMeasured,
+			TotalCatch:   unknown.TotalCatch,
+			FirstSeen:    unknown.FirstSeen,
+			LastSeen:     unknown.LastSeen,
+			DOWNumbers:   dows,
+		}
+		if len(entry.DOWNumbers) > 20 {
+			entry.DOWNumbers = entry.DOWNumbers[:20]
+		}
+		list = append(list, entry)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Surveys != list[j].Surveys {
+			return list[i].Surveys > list[j].Surveys
+		}
+		return list[i].Code < list[j].Code
+	})
+	return list
+}
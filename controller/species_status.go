@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"fishreports/model"
+)
+
+// LoadSpeciesStatuses reads the species status list, a JSON object mapping
+// each status category (e.g. "invasive", "special_concern") to species codes,
+// and returns the categories of each code. A missing file returns no statuses.
+func LoadSpeciesStatuses(path string) (map[string][]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	file, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read species status list: %w", err)
+	}
+	var raw map[string][]string
+	if err := json.Unmarshal(file, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse species status list: %w", err)
+	}
+
+	statuses := make(map[string][]string)
+	for category, codes := range raw {
+		category = strings.ToLower(strings.TrimSpace(category))
+		if category == "" || strings.ContainsAny(category, " \t,") {
+			return nil, fmt.Errorf("invalid status category %q in species status list", category)
+		}
+		for _, code := range codes {
+			code = strings.ToUpper(strings.TrimSpace(code))
+			if code != "" && !containsString(statuses[code], category) {
+				statuses[code] = append(statuses[code], category)
+			}
+		}
+	}
+	for code := range statuses {
+		sort.Strings(statuses[code])
+	}
+	log.Printf("✅ Loaded statuses for %d species from %s", len(statuses), path)
+	return statuses, nil
+}
+
+// ApplySpeciesStatuses sets each species' status categories from statuses,
+// replacing any it had. Codes in the list that aren't in the catalog are
+// logged.
+func ApplySpeciesStatuses(speciesMap map[string]model.Species, statuses map[string][]string) {
+	for code, species := range speciesMap {
+		species.Status = statuses[code]
+		speciesMap[code] = species
+	}
+	for code := range statuses {
+		if _, exists := speciesMap[code]; !exists {
+			log.Printf("⚠️ Species status list names unknown species code %s", code)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"fishreports/model"
+
+	"github.com/google/uuid"
+)
+
+// SurveyAdapter converts one agency's raw survey documents into FishData.
+// Convert returns the lakes in a document with the agency's own lake IDs;
+// the loader applies the state's ID namespace afterwards. Fish lengths must
+// come out in whole inches, the unit the histograms assume.
+type SurveyAdapter interface {
+	Name() string
+	Convert(raw []byte) ([]model.FishData, error)
+}
+
+// SurveyEncoder is an adapter that can also write lakes back in its raw
+// format. The ingest API needs it to persist the lakes it updates.
+type SurveyEncoder interface {
+	SurveyAdapter
+	Encode(fishData model.FishData) ([]byte, error)
+}
+
+var (
+	adapters   = make(map[string]SurveyAdapter)
+	adaptersMu sync.RWMutex
+)
+
+func init() {
+	RegisterAdapter(mnDNRAdapter{})
+	RegisterAdapter(fishDataAdapter{})
+}
+
+// RegisterAdapter makes an agency format available to the state config by
+// its name, replacing any adapter with the same name.
+func RegisterAdapter(adapter SurveyAdapter) {
+	adaptersMu.Lock()
+	defer adaptersMu.Unlock()
+	adapters[adapter.Name()] = adapter
+}
+
+// GetAdapter returns the adapter registered under name.
+func GetAdapter(name string) (SurveyAdapter, bool) {
+	adaptersMu.RLock()
+	defer adaptersMu.RUnlock()
+	adapter, exists := adapters[name]
+	return adapter, exists
+}
+
+// mnDNRAdapter reads the Minnesota DNR scraper format: one lake per document
+// with fishCount entries as [length, quantity] pairs.
+type mnDNRAdapter struct{}
+
+func (mnDNRAdapter) Name() string { return "mn_dnr" }
+
+func (mnDNRAdapter) Convert(raw []byte) ([]model.FishData, error) {
+	var rawData map[string]interface{}
+	if err := json.Unmarshal(raw, &rawData); err != nil {
+		return nil, err
+	}
+	TransformFishCount(rawData, nil)
+
+	var fishData model.FishData
+	transformedJSON, _ := json.Marshal(rawData)
+	if err := json.Unmarshal(transformedJSON, &fishData); err != nil {
+		return nil, err
+	}
+	return []model.FishData{fishData}, nil
+}
+
+func (mnDNRAdapter) Encode(fishData model.FishData) ([]byte, error) {
+	return encodeFishData(fishData)
+}
+
+// fishDataAdapter reads FishData already in this server's format (fishCount
+// entries as objects), one lake or an array of lakes per document. Agencies
+// whose data is converted by an external tool can use it.
+type fishDataAdapter struct{}
+
+func (fishDataAdapter) Name() string { return "fishdata" }
+
+func (fishDataAdapter) Convert(raw []byte) ([]model.FishData, error) {
+	trimmed := strings.TrimSpace(string(raw))
+	if strings.HasPrefix(trimmed, "[") {
+		var lakes []model.FishData
+		if err := json.Unmarshal(raw, &lakes); err != nil {
+			return nil, err
+		}
+		return lakes, nil
+	}
+	var fishData model.FishData
+	if err := json.Unmarshal(raw, &fishData); err != nil {
+		return nil, err
+	}
+	return []model.FishData{fishData}, nil
+}
+
+func (fishDataAdapter) Encode(fishData model.FishData) ([]byte, error) {
+	fishData.Result.Surveys = append([]model.Survey(nil), fishData.Result.Surveys...)
+	for i := range fishData.Result.Surveys {
+		fishData.Result.Surveys[i].QualityFlags = nil
+	}
+	return json.Marshal(fishData)
+}
+
+// StateDataset is one state's survey data: where it lives, the agency format
+// it is in, and the offset that keeps its lake IDs apart from other states'.
+type StateDataset struct {
+	Code         string
+	Name         string
+	Agency       string
+	CountiesFile string
+	SurveyDir    string
+	Adapter      SurveyAdapter
+	LakeIDOffset int
+	Sources      []SurveySource // read after the JSON files in SurveyDir
+}
+
+// Source returns the state's survey sources as one: the JSON files in
+// SurveyDir, which the ingest API writes to, followed by Sources.
+func (s StateDataset) Source() SurveySource {
+	sources := MultiSource{}
+	if s.SurveyDir != "" {
+		sources = append(sources, JSONDirSource{Dir: s.SurveyDir})
+	}
+	for _, source := range s.Sources {
+		if dir, ok := source.(JSONDirSource); ok && filepath.Clean(dir.Dir) == filepath.Clean(s.SurveyDir) {
+			continue
+		}
+		sources = append(sources, source)
+	}
+	return sources
+}
+
+// DefaultStateDataset returns the Minnesota DNR dataset in the given files.
+func DefaultStateDataset(countiesFile, surveyDir string) StateDataset {
+	return StateDataset{
+		Code:         model.DefaultState,
+		Name:         "Minnesota",
+		Agency:       "Minnesota DNR",
+		CountiesFile: countiesFile,
+		SurveyDir:    surveyDir,
+		Adapter:      mnDNRAdapter{},
+	}
+}
+
+// FindState returns the configured dataset for a state code.
+func (c *FishSurveyController) FindState(code string) (StateDataset, bool) {
+	return findState(c.States, code)
+}
+
+// findState returns the dataset for a state code among states.
+func findState(states []StateDataset, code string) (StateDataset, bool) {
+	code = model.NormalizeState(code)
+	for _, state := range states {
+		if state.Code == code {
+			return state, true
+		}
+	}
+	return StateDataset{}, false
+}
+
+// LakeIDRange is the span of lake IDs each state's LakeIDOffset reserves,
+// room for Minnesota's eight-digit DOW numbers.
+const LakeIDRange = 100000000
+
+// CheckLakeIDOffsets rejects negative offsets and states whose lake ID
+// ranges overlap, which could give lakes of two states the same ID.
+func CheckLakeIDOffsets(states []StateDataset) error {
+	sorted := append([]StateDataset(nil), states...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].LakeIDOffset < sorted[j].LakeIDOffset })
+	for i, state := range sorted {
+		if state.LakeIDOffset < 0 {
+			return fmt.Errorf("state %s: lake_id_offset must not be negative", state.Code)
+		}
+		if i == 0 {
+			continue
+		}
+		previous := sorted[i-1]
+		if state.LakeIDOffset-previous.LakeIDOffset < LakeIDRange {
+			return fmt.Errorf("states %s and %s have overlapping lake IDs: lake_id_offset %d and %d must be at least %d apart",
+				previous.Code, state.Code, previous.LakeIDOffset, state.LakeIDOffset, LakeIDRange)
+		}
+	}
+	return nil
+}
+
+// LakeID returns the namespaced lake ID for one of the state's agency lake IDs.
+func (s StateDataset) LakeID(agencyLakeID int) int {
+	return agencyLakeID + s.LakeIDOffset
+}
+
+// AgencyLakeID reverses LakeID.
+func (s StateDataset) AgencyLakeID(lakeID int) int {
+	return lakeID - s.LakeIDOffset
+}
+
+// namespaceSurveyID prefixes survey IDs outside the default state with the
+// state code, since agencies number their surveys independently.
+func (s StateDataset) namespaceSurveyID(surveyID string) string {
+	if s.Code == model.DefaultState || strings.HasPrefix(surveyID, s.Code+"-") {
+		return surveyID
+	}
+	return s.Code + "-" + surveyID
+}
+
+// ParseStateData converts one raw document with the state's adapter and
+// prepares each lake like ParseFishData: the state and its ID namespace are
+// applied, missing survey IDs are assigned, and the surveys are run through
+// validator's rules. A lake that fails validation fails the document.
+func ParseStateData(raw []byte, m *model.FishSurveyModel, state StateDataset, validator *Validator) ([]model.FishData, *model.ValidationReport, error) {
+	report := model.NewValidationReport()
+	lakes, err := convertStateData(raw, state)
+	if err != nil {
+		return lakes, report, err
+	}
+	for i := range lakes {
+		assignSurveyIDs(&lakes[i], state, nil)
+		report.Merge(validateLake(&lakes[i], m.SpeciesMap, validator))
+	}
+	return lakes, report, nil
+}
+
+// convertStateData is ParseStateData without assigning survey IDs or running
+// the validation rules, giving the lakes as the agency published them.
+// Surveys published without an ID keep an empty one.
+func convertStateData(raw []byte, state StateDataset) ([]model.FishData, error) {
+	adapter := state.Adapter
+	if adapter == nil {
+		adapter = mnDNRAdapter{}
+	}
+	lakes, err := adapter.Convert(raw)
+	if err != nil {
+		return nil, err
+	}
+	for i := range lakes {
+		fishData := &lakes[i]
+		if err := validateFishData(*fishData); err != nil {
+			return lakes, err
+		}
+		fishData.Result.State = state.Code
+		fishData.Result.DOWNumber = state.LakeID(fishData.Result.DOWNumber)
+		for j, survey := range fishData.Result.Surveys {
+			if survey.SurveyID != "" {
+				fishData.Result.Surveys[j].SurveyID = state.namespaceSurveyID(survey.SurveyID)
+			}
+		}
+	}
+	return lakes, nil
+}
+
+// assignSurveyIDs gives the lake's surveys without an ID one: the ID in ids
+// of the survey with the same survey key, or else a new one in the state's
+// namespace.
+func assignSurveyIDs(lake *model.FishData, state StateDataset, ids map[string]string) {
+	for i, survey := range lake.Result.Surveys {
+		if survey.SurveyID != "" {
+			continue
+		}
+		if id, exists := ids[model.SurveyKey(lake.Result.DOWNumber, survey)]; exists {
+			lake.Result.Surveys[i].SurveyID = id
+			continue
+		}
+		lake.Result.Surveys[i].SurveyID = state.namespaceSurveyID(uuid.New().String())
+	}
+}
+
+// validateLake runs a lake's surveys through the validator's rules, replacing
+// them with the surveys kept. A nil validator keeps every survey.
+func validateLake(fishData *model.FishData, speciesMap map[string]model.Species, validator *Validator) *model.ValidationReport {
+	if validator == nil {
+		return model.NewValidationReport()
+	}
+	return validator.Validate(fishData, speciesMap)
+}
+
+// encodeStateLake writes a lake back in its state's raw format, undoing the
+// ID namespace.
+func encodeStateLake(lake model.FishData, state StateDataset) ([]byte, error) {
+	encoder, ok := state.Adapter.(SurveyEncoder)
+	if !ok {
+		return nil, fmt.Errorf("the %s adapter for %s can't write surveys back", state.Adapter.Name(), state.Code)
+	}
+	lake.Result.State = ""
+	lake.Result.DOWNumber = state.AgencyLakeID(lake.Result.DOWNumber)
+	lake.Result.Surveys = append([]model.Survey(nil), lake.Result.Surveys...)
+	for i, survey := range lake.Result.Surveys {
+		lake.Result.Surveys[i].SurveyID = strings.TrimPrefix(survey.SurveyID, state.namespaceSurveyID(""))
+	}
+	return encoder.Encode(lake)
+}
+
+// fishDataKey returns the FishDataByCounty key for a lake: its county name,
+// qualified with the state outside the default state so same-named counties
+// in different states stay apart.
+func fishDataKey(data model.FishData) string {
+	if state := model.LakeState(data); state != model.DefaultState {
+		return state + "/" + data.Result.CountyName
+	}
+	return data.Result.CountyName
+}
+
+// StateSummaries describes the configured states with their county and lake
+// counts in the current data.
+func (c *FishSurveyController) StateSummaries(counties []model.County) []model.State {
+	countyCounts := make(map[string]int)
+	for _, county := range counties {
+		countyCounts[model.CountyState(county)]++
+	}
+	lakeCounts := make(map[string]int)
+	if c.Model != nil {
+		if _, agg := c.Model.Snapshot(); agg != nil {
+			for _, lake := range agg.Lakes {
+				lakeCounts[model.NormalizeState(lake.State)]++
+			}
+		}
+	}
+
+	summaries := make([]model.State, 0, len(c.States))
+	for _, state := range c.States {
+		agency := state.Agency
+		if agency == "" && state.Adapter != nil {
+			agency = state.Adapter.Name()
+		}
+		summaries = append(summaries, model.State{
+			Code:         state.Code,
+			Name:         state.Name,
+			Agency:       agency,
+			LakeIDOffset: state.LakeIDOffset,
+			Counties:     countyCounts[state.Code],
+			Lakes:        lakeCounts[state.Code],
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Code < summaries[j].Code })
+	return summaries
+}
+
+// ForState returns a controller serving only one state's lakes, or c itself
+// when state is empty.
+func (c *FishSurveyController) ForState(state string) *FishSurveyController {
+	if state == "" {
+		return c
+	}
+	view := *c
+	view.Model = c.Model.InState(state)
+	return &view
+}
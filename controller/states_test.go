@@ -0,0 +1,27 @@
+package controller
+
+import "testing"
+
+func TestCheckLakeIDOffsets(t *testing.T) {
+	tests := []struct {
+		name    string
+		offsets map[string]int
+		wantErr bool
+	}{
+		{"single state", map[string]int{"MN": 0}, false},
+		{"apart", map[string]int{"MN": 0, "WI": LakeIDRange, "IA": 3 * LakeIDRange}, false},
+		{"duplicate", map[string]int{"MN": 0, "WI": 0}, true},
+		{"overlapping", map[string]int{"MN": 0, "WI": LakeIDRange - 1}, true},
+		{"overlapping out of order", map[string]int{"IA": 2 * LakeIDRange, "MN": 0, "WI": 2*LakeIDRange + 5}, true},
+		{"negative", map[string]int{"MN": -1}, true},
+	}
+	for _, tt := range tests {
+		var states []StateDataset
+		for code, offset := range tt.offsets {
+			states = append(states, StateDataset{Code: code, LakeIDOffset: offset})
+		}
+		if err := CheckLakeIDOffsets(states); (err != nil) != tt.wantErr {
+			t.Errorf("%s: CheckLakeIDOffsets = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
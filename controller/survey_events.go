@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"fishreports/model"
+)
+
+// SurveyEventLog is a bounded in-memory log of survey events with live
+// subscribers. Event IDs increase monotonically and start from the process
+// start time in milliseconds, so IDs from before a restart sort below new ones.
+type SurveyEventLog struct {
+	events      []model.SurveyEvent // oldest first, at most capacity entries
+	capacity    int
+	nextID      int64
+	evictedTo   int64 // highest ID no longer retained; starts at the first ID minus one
+	subscribers map[chan model.SurveyEvent]struct{}
+	mu          sync.Mutex
+}
+
+// NewSurveyEventLog creates a log keeping the most recent capacity events.
+func NewSurveyEventLog(capacity int) *SurveyEventLog {
+	if capacity <= 0 {
+		capacity = 1000
+	}
+	start := time.Now().UnixMilli()
+	return &SurveyEventLog{
+		capacity:    capacity,
+		nextID:      start,
+		evictedTo:   start,
+		subscribers: make(map[chan model.SurveyEvent]struct{}),
+	}
+}
+
+// Publish assigns IDs to the events, appends them to the log and fans them
+// out to subscribers. A subscriber that can't keep up is disconnected; it can
+// resume from the log with its last event ID.
+func (l *SurveyEventLog) Publish(events []model.SurveyEvent) []model.SurveyEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	published := make([]model.SurveyEvent, 0, len(events))
+	for _, event := range events {
+		l.nextID++
+		event.ID = l.nextID
+		if event.CreatedAt.IsZero() {
+			event.CreatedAt = time.Now().UTC()
+		}
+		published = append(published, event)
+	}
+
+	l.events = append(l.events, published...)
+	if overflow := len(l.events) - l.capacity; overflow > 0 {
+		l.evictedTo = l.events[overflow-1].ID
+		l.events = append([]model.SurveyEvent(nil), l.events[overflow:]...)
+	}
+
+	for ch := range l.subscribers {
+		if !deliver(ch, published) {
+			delete(l.subscribers, ch)
+			close(ch)
+		}
+	}
+	return published
+}
+
+// deliver sends events without blocking, reporting whether they all fit.
+func deliver(ch chan model.SurveyEvent, events []model.SurveyEvent) bool {
+	for _, event := range events {
+		select {
+		case ch <- event:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Since returns the retained events after lastID. complete is false when
+// events after lastID have already been evicted, or lastID predates this
+// process, so the caller may have missed some.
+func (l *SurveyEventLog) Since(lastID int64) (events []model.SurveyEvent, complete bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	index := sort.Search(len(l.events), func(i int) bool {
+		return l.events[i].ID > lastID
+	})
+	events = append(events, l.events[index:]...)
+	complete = lastID >= l.evictedTo
+	return events, complete
+}
+
+// Subscribe registers a live subscriber. The channel is closed when the
+// subscriber falls behind or cancel is called.
+func (l *SurveyEventLog) Subscribe(buffer int) (<-chan model.SurveyEvent, func()) {
+	ch := make(chan model.SurveyEvent, buffer)
+	l.mu.Lock()
+	l.subscribers[ch] = struct{}{}
+	l.mu.Unlock()
+
+	cancel := func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if _, exists := l.subscribers[ch]; exists {
+			delete(l.subscribers, ch)
+			close(ch)
+		}
+	}
+	return ch, cancel
+}
@@ -0,0 +1,509 @@
+package controller
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"fishreports/model"
+)
+
+// SurveyDocument is one raw survey document read by a SurveySource.
+type SurveyDocument struct {
+	Path     string        // file it came from; archive members are "<archive>!<member>"
+	Data     []byte        // the raw document
+	Adapter  SurveyAdapter // format of Data; nil means the state's adapter
+	Writable bool          // Path is a plain file the ingest API may rewrite
+}
+
+// SurveySource reads survey documents from one kind of input. Documents
+// calls fn for each document; a document that can't be read is logged and
+// skipped, and only an error returned by fn stops the walk.
+type SurveySource interface {
+	Name() string
+	Documents(fn func(doc SurveyDocument) error) error
+}
+
+// SourceFactory creates a source of one type for a path.
+type SourceFactory func(path string) SurveySource
+
+var (
+	sourceTypes   = make(map[string]SourceFactory)
+	sourceTypesMu sync.RWMutex
+)
+
+func init() {
+	RegisterSourceType("json_dir", func(path string) SurveySource { return JSONDirSource{Dir: path} })
+	RegisterSourceType("json_gzip", func(path string) SurveySource { return GzipJSONSource{Dir: path} })
+	RegisterSourceType("zip", func(path string) SurveySource { return ZipSource{Path: path} })
+	RegisterSourceType("ndjson", func(path string) SurveySource { return NDJSONSource{Path: path} })
+	RegisterSourceType("csv", func(path string) SurveySource { return CSVSource{Path: path} })
+}
+
+// RegisterSourceType makes a source type available to the config by name,
+// replacing any type with the same name.
+func RegisterSourceType(name string, factory SourceFactory) {
+	sourceTypesMu.Lock()
+	defer sourceTypesMu.Unlock()
+	sourceTypes[name] = factory
+}
+
+// NewSurveySource creates a source of a registered type.
+func NewSurveySource(sourceType, path string) (SurveySource, error) {
+	sourceTypesMu.RLock()
+	factory, exists := sourceTypes[sourceType]
+	sourceTypesMu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("unknown survey source type %q", sourceType)
+	}
+	if path == "" {
+		return nil, fmt.Errorf("survey source %q needs a path", sourceType)
+	}
+	return factory(path), nil
+}
+
+// MultiSource reads several sources in order as one.
+type MultiSource []SurveySource
+
+func (ms MultiSource) Name() string {
+	names := make([]string, len(ms))
+	for i, source := range ms {
+		names[i] = source.Name()
+	}
+	return strings.Join(names, ", ")
+}
+
+func (ms MultiSource) Documents(fn func(doc SurveyDocument) error) error {
+	for _, source := range ms {
+		if err := source.Documents(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sourceFiles returns the files under path with one of the suffixes, sorted.
+// path may also name a single file, which is returned whatever its suffix.
+// A missing path is logged and yields no files.
+func sourceFiles(path string, suffixes ...string) []string {
+	info, err := os.Stat(path)
+	if err != nil {
+		log.Printf("❌ Survey source %s: %v", path, err)
+		return nil
+	}
+	if !info.IsDir() {
+		return []string{path}
+	}
+	var files []string
+	_ = filepath.WalkDir(path, func(file string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		for _, suffix := range suffixes {
+			if strings.HasSuffix(strings.ToLower(file), suffix) {
+				files = append(files, file)
+				break
+			}
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files
+}
+
+// JSONDirSource reads every .json file under a directory, one document per
+// file. It is the default source and the only one the ingest API writes to.
+type JSONDirSource struct {
+	Dir string
+}
+
+func (s JSONDirSource) Name() string { return "json_dir:" + s.Dir }
+
+func (s JSONDirSource) Documents(fn func(doc SurveyDocument) error) error {
+	if _, err := os.Stat(s.Dir); errors.Is(err, os.ErrNotExist) {
+		return nil // a state may start with an empty survey directory
+	}
+	for _, file := range sourceFiles(s.Dir, ".json") {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			log.Printf("❌ Error reading survey file %s: %v", file, err)
+			continue
+		}
+		if err := fn(SurveyDocument{Path: file, Data: data, Writable: true}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GzipJSONSource reads every .json.gz file under a directory (or a single
+// file), one document per file.
+type GzipJSONSource struct {
+	Dir string
+}
+
+func (s GzipJSONSource) Name() string { return "json_gzip:" + s.Dir }
+
+func (s GzipJSONSource) Documents(fn func(doc SurveyDocument) error) error {
+	for _, file := range sourceFiles(s.Dir, ".json.gz") {
+		data, err := readGzipFile(file)
+		if err != nil {
+			log.Printf("❌ Error reading survey file %s: %v", file, err)
+			continue
+		}
+		if err := fn(SurveyDocument{Path: file, Data: data}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readGzipFile(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// ZipSource reads a zip archive, or every .zip file under a directory.
+// Members ending in .json are one document each, .json.gz members are
+// decompressed, .ndjson and .jsonl members hold one document per line and
+// .csv members are read like CSVSource.
+type ZipSource struct {
+	Path string
+}
+
+func (s ZipSource) Name() string { return "zip:" + s.Path }
+
+func (s ZipSource) Documents(fn func(doc SurveyDocument) error) error {
+	for _, file := range sourceFiles(s.Path, ".zip") {
+		if err := zipDocuments(file, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func zipDocuments(path string, fn func(doc SurveyDocument) error) error {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		log.Printf("❌ Error opening survey archive %s: %v", path, err)
+		return nil
+	}
+	defer archive.Close()
+
+	for _, member := range archive.File {
+		name := strings.ToLower(member.Name)
+		if member.FileInfo().IsDir() {
+			continue
+		}
+		memberPath := path + "!" + member.Name
+		data, err := readZipMember(member)
+		if err != nil {
+			log.Printf("❌ Error reading survey file %s: %v", memberPath, err)
+			continue
+		}
+		switch {
+		case strings.HasSuffix(name, ".json"):
+			err = fn(SurveyDocument{Path: memberPath, Data: data})
+		case strings.HasSuffix(name, ".json.gz"):
+			var reader *gzip.Reader
+			if reader, err = gzip.NewReader(bytes.NewReader(data)); err == nil {
+				data, err = io.ReadAll(reader)
+			}
+			if err != nil {
+				log.Printf("❌ Error reading survey file %s: %v", memberPath, err)
+				continue
+			}
+			err = fn(SurveyDocument{Path: memberPath, Data: data})
+		case strings.HasSuffix(name, ".ndjson"), strings.HasSuffix(name, ".jsonl"):
+			err = ndjsonDocuments(memberPath, bytes.NewReader(data), fn)
+		case strings.HasSuffix(name, ".csv"):
+			err = csvDocuments(memberPath, bytes.NewReader(data), fn)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readZipMember(member *zip.File) ([]byte, error) {
+	reader, err := member.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// NDJSONSource reads newline-delimited JSON, one document per line, from a
+// file or every .ndjson and .jsonl file under a directory. Files ending in
+// .gz are decompressed first.
+type NDJSONSource struct {
+	Path string
+}
+
+func (s NDJSONSource) Name() string { return "ndjson:" + s.Path }
+
+func (s NDJSONSource) Documents(fn func(doc SurveyDocument) error) error {
+	for _, file := range sourceFiles(s.Path, ".ndjson", ".jsonl", ".ndjson.gz", ".jsonl.gz") {
+		var data []byte
+		var err error
+		if strings.HasSuffix(strings.ToLower(file), ".gz") {
+			data, err = readGzipFile(file)
+		} else {
+			data, err = os.ReadFile(file)
+		}
+		if err != nil {
+			log.Printf("❌ Error reading survey file %s: %v", file, err)
+			continue
+		}
+		if err := ndjsonDocuments(file, bytes.NewReader(data), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ndjsonDocuments calls fn for each non-blank line, tagging the document
+// path with its line number.
+func ndjsonDocuments(path string, r io.Reader, fn func(doc SurveyDocument) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64<<20)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		doc := SurveyDocument{Path: path + "#" + strconv.Itoa(line), Data: append([]byte(nil), text...)}
+		if err := fn(doc); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Printf("❌ Error reading survey file %s: %v", path, err)
+	}
+	return nil
+}
+
+// CSVSource reads length-frequency exports: one row per species length
+// class, from a file or every .csv file under a directory. The header names
+// the columns, in any order:
+//
+//	dow_number, county, survey_date, species, length, quantity (required)
+//	lake_name, county_fips, survey_id, survey_type, survey_sub_type, total_catch
+//
+// Rows are grouped into one lake per DOW number and one survey per survey ID
+// (or date and type). total_catch, when given, is the survey's catch of the
+// species and is taken from the species' first row; otherwise the measured
+// quantities are summed.
+type CSVSource struct {
+	Path string
+}
+
+func (s CSVSource) Name() string { return "csv:" + s.Path }
+
+func (s CSVSource) Documents(fn func(doc SurveyDocument) error) error {
+	for _, file := range sourceFiles(s.Path, ".csv") {
+		f, err := os.Open(file)
+		if err != nil {
+			log.Printf("❌ Error reading survey file %s: %v", file, err)
+			continue
+		}
+		err = csvDocuments(file, f, fn)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var csvRequiredColumns = []string{"dow_number", "county", "survey_date", "species", "length", "quantity"}
+
+// csvDocuments groups a CSV export into lakes and calls fn with each lake as
+// a FishData document in the fishdata format.
+func csvDocuments(path string, r io.Reader, fn func(doc SurveyDocument) error) error {
+	lakes, err := parseLengthFrequencyCSV(r)
+	if err != nil {
+		log.Printf("❌ Error reading survey file %s: %v", path, err)
+		return nil
+	}
+	for _, lake := range lakes {
+		data, err := json.Marshal(lake)
+		if err != nil {
+			return err
+		}
+		doc := SurveyDocument{
+			Path:    path + "#" + strconv.Itoa(lake.Result.DOWNumber),
+			Data:    data,
+			Adapter: fishDataAdapter{},
+		}
+		if err := fn(doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseLengthFrequencyCSV(r io.Reader) ([]model.FishData, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("missing header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range csvRequiredColumns {
+		if _, exists := columns[name]; !exists {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	type surveyEntry struct {
+		survey  *model.Survey
+		catches map[string]int
+		catchOK map[string]bool // species whose catch came from total_catch
+	}
+	lakes := make(map[int]*model.FishData)
+	surveys := make(map[int]map[string]*surveyEntry)
+	var lakeOrder []int
+	var surveyOrder = make(map[int][]string)
+
+	row := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		row++
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+		field := func(name string) string {
+			if i, exists := columns[name]; exists && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		dow, err := strconv.Atoi(field("dow_number"))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid dow_number %q", row, field("dow_number"))
+		}
+		length, lengthErr := strconv.Atoi(field("length"))
+		quantity, quantityErr := strconv.Atoi(field("quantity"))
+		if lengthErr != nil || quantityErr != nil {
+			return nil, fmt.Errorf("row %d: invalid length or quantity", row)
+		}
+		code := strings.ToUpper(field("species"))
+		if code == "" {
+			return nil, fmt.Errorf("row %d: missing species", row)
+		}
+
+		lake, exists := lakes[dow]
+		if !exists {
+			lake = &model.FishData{}
+			lake.Result.DOWNumber = dow
+			lakes[dow] = lake
+			surveys[dow] = make(map[string]*surveyEntry)
+			lakeOrder = append(lakeOrder, dow)
+		}
+		lake.Result.CountyName = field("county")
+		if name := field("lake_name"); name != "" {
+			lake.Result.LakeName = name
+		}
+		if fips := field("county_fips"); fips != "" {
+			lake.Result.CountyFIPS = fips
+		}
+
+		surveyID := field("survey_id")
+		key := surveyID
+		if key == "" {
+			key = field("survey_date") + "|" + field("survey_type")
+		}
+		entry, exists := surveys[dow][key]
+		if !exists {
+			entry = &surveyEntry{
+				survey: &model.Survey{
+					SurveyID:      surveyID,
+					SurveyDate:    field("survey_date"),
+					SurveyType:    field("survey_type"),
+					SurveySubType: field("survey_sub_type"),
+					Lengths:       make(map[string]*model.LengthData),
+				},
+				catches: make(map[string]int),
+				catchOK: make(map[string]bool),
+			}
+			surveys[dow][key] = entry
+			surveyOrder[dow] = append(surveyOrder[dow], key)
+		}
+
+		lengths, exists := entry.survey.Lengths[code]
+		if !exists {
+			lengths = &model.LengthData{MinimumLength: length, MaximumLength: length}
+			entry.survey.Lengths[code] = lengths
+			if total, err := strconv.Atoi(field("total_catch")); err == nil {
+				entry.catches[code] = total
+				entry.catchOK[code] = true
+			}
+		}
+		if length < lengths.MinimumLength {
+			lengths.MinimumLength = length
+		}
+		if length > lengths.MaximumLength {
+			lengths.MaximumLength = length
+		}
+		lengths.FishCount = append(lengths.FishCount, model.FishCount{Length: length, Quantity: quantity})
+		if !entry.catchOK[code] {
+			entry.catches[code] += quantity
+		}
+	}
+
+	result := make([]model.FishData, 0, len(lakeOrder))
+	for _, dow := range lakeOrder {
+		lake := lakes[dow]
+		for _, key := range surveyOrder[dow] {
+			entry := surveys[dow][key]
+			codes := make([]string, 0, len(entry.catches))
+			for code := range entry.catches {
+				codes = append(codes, code)
+			}
+			sort.Strings(codes)
+			for _, code := range codes {
+				species, total := code, entry.catches[code]
+				entry.survey.FishCatchSummaries = append(entry.survey.FishCatchSummaries,
+					model.FishCatchSummary{Species: &species, TotalCatch: &total})
+			}
+			lake.Result.Surveys = append(lake.Result.Surveys, *entry.survey)
+		}
+		result = append(result, *lake)
+	}
+	return result, nil
+}
@@ -0,0 +1,283 @@
+package controller
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeSourceFile writes a test input file, creating its directory.
+func writeSourceFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func gzipped(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// readDocuments returns the documents of a source as path: data pairs.
+func readDocuments(t *testing.T, source SurveySource) (map[string]string, []SurveyDocument) {
+	t.Helper()
+	var docs []SurveyDocument
+	if err := source.Documents(func(doc SurveyDocument) error {
+		docs = append(docs, doc)
+		return nil
+	}); err != nil {
+		t.Fatalf("%s: %v", source.Name(), err)
+	}
+	byPath := make(map[string]string, len(docs))
+	for _, doc := range docs {
+		byPath[doc.Path] = string(doc.Data)
+	}
+	return byPath, docs
+}
+
+func TestNewSurveySource(t *testing.T) {
+	if source, err := NewSurveySource("zip", "data/archive.zip"); err != nil || source != (ZipSource{Path: "data/archive.zip"}) {
+		t.Errorf("zip source = %#v, %v", source, err)
+	}
+	if _, err := NewSurveySource("parquet", "data/x"); err == nil {
+		t.Error("unknown source type accepted")
+	}
+	if _, err := NewSurveySource("csv", ""); err == nil {
+		t.Error("source without a path accepted")
+	}
+
+	RegisterSourceType("test_documents", func(path string) SurveySource { return documentSource{} })
+	if source, err := NewSurveySource("test_documents", "anything"); err != nil || source.Name() != "documents" {
+		t.Errorf("registered source = %#v, %v", source, err)
+	}
+}
+
+func TestJSONDirSource(t *testing.T) {
+	dir := t.TempDir()
+	writeSourceFile(t, filepath.Join(dir, "b.json"), []byte(`{"b": 1}`))
+	writeSourceFile(t, filepath.Join(dir, "lakes", "a.JSON"), []byte(`{"a": 1}`))
+	writeSourceFile(t, filepath.Join(dir, "notes.txt"), []byte("not a survey"))
+
+	byPath, docs := readDocuments(t, JSONDirSource{Dir: dir})
+	want := map[string]string{
+		filepath.Join(dir, "b.json"):          `{"b": 1}`,
+		filepath.Join(dir, "lakes", "a.JSON"): `{"a": 1}`,
+	}
+	if !reflect.DeepEqual(byPath, want) {
+		t.Errorf("documents = %v, want %v", byPath, want)
+	}
+	if docs[0].Path != filepath.Join(dir, "b.json") || !docs[0].Writable || docs[0].Adapter != nil {
+		t.Errorf("first document = %+v, want b.json, writable with the state's adapter", docs[0])
+	}
+
+	if byPath, _ := readDocuments(t, JSONDirSource{Dir: filepath.Join(dir, "missing")}); len(byPath) != 0 {
+		t.Errorf("missing directory documents = %v", byPath)
+	}
+}
+
+func TestGzipJSONSource(t *testing.T) {
+	dir := t.TempDir()
+	writeSourceFile(t, filepath.Join(dir, "lake.json.gz"), gzipped(t, `{"lake": 1}`))
+	writeSourceFile(t, filepath.Join(dir, "broken.json.gz"), []byte("not gzip"))
+	writeSourceFile(t, filepath.Join(dir, "plain.json"), []byte(`{}`))
+
+	byPath, docs := readDocuments(t, GzipJSONSource{Dir: dir})
+	if want := map[string]string{filepath.Join(dir, "lake.json.gz"): `{"lake": 1}`}; !reflect.DeepEqual(byPath, want) {
+		t.Errorf("documents = %v, want %v", byPath, want)
+	}
+	if docs[0].Writable {
+		t.Error("a compressed file is writable")
+	}
+
+	// A single file is read whatever its name.
+	single := filepath.Join(dir, "export.gz")
+	writeSourceFile(t, single, gzipped(t, `{"single": 1}`))
+	if byPath, _ := readDocuments(t, GzipJSONSource{Dir: single}); byPath[single] != `{"single": 1}` {
+		t.Errorf("single file documents = %v", byPath)
+	}
+}
+
+func TestNDJSONSource(t *testing.T) {
+	dir := t.TempDir()
+	writeSourceFile(t, filepath.Join(dir, "a.ndjson"), []byte("{\"n\": 1}\n\n  {\"n\": 2}  \n"))
+	writeSourceFile(t, filepath.Join(dir, "b.jsonl.gz"), gzipped(t, "{\"n\": 3}"))
+
+	byPath, _ := readDocuments(t, NDJSONSource{Path: dir})
+	want := map[string]string{
+		filepath.Join(dir, "a.ndjson") + "#1":   `{"n": 1}`,
+		filepath.Join(dir, "a.ndjson") + "#3":   `{"n": 2}`,
+		filepath.Join(dir, "b.jsonl.gz") + "#1": `{"n": 3}`,
+	}
+	if !reflect.DeepEqual(byPath, want) {
+		t.Errorf("documents = %v, want %v", byPath, want)
+	}
+}
+
+// lengthFrequencyCSV is an export with a byte order mark and the columns
+// in another order.
+const lengthFrequencyCSV = "\ufeffSpecies,DOW_Number,County,Lake_Name,Survey_Date,Survey_Type,Length,Quantity,Total_Catch\n" +
+	"wae,1000100,Aitkin,Big Lake,2020-06-01,Standard Survey,14,3,10\n" +
+	"WAE,1000100,Aitkin,Big Lake,2020-06-01,Standard Survey,18,2,\n" +
+	"NOP,1000100,Aitkin,Big Lake,2020-06-01,Standard Survey,24,1,\n" +
+	"NOP,1000100,Aitkin,Big Lake,2020-06-01,Standard Survey,20,2,\n" +
+	"YEP,1000100,Aitkin,Big Lake,2021-06-01,Standard Survey,7,5,\n" +
+	"BLG,11000200,Cass,Long Lake,2019-08-15,Special Assessment,6,4,\n"
+
+func TestParseLengthFrequencyCSV(t *testing.T) {
+	lakes, err := parseLengthFrequencyCSV(strings.NewReader(lengthFrequencyCSV))
+	if err != nil {
+		t.Fatalf("parseLengthFrequencyCSV: %v", err)
+	}
+	if len(lakes) != 2 || lakes[0].Result.DOWNumber != 1000100 || lakes[1].Result.LakeName != "Long Lake" {
+		t.Fatalf("lakes = %+v, want Big Lake then Long Lake", lakes)
+	}
+	big := lakes[0].Result
+	if big.CountyName != "Aitkin" || len(big.Surveys) != 2 {
+		t.Fatalf("Big Lake = %+v, want 2 surveys in Aitkin", big)
+	}
+	survey := big.Surveys[0]
+	if survey.SurveyDate != "2020-06-01" || survey.SurveyType != "Standard Survey" {
+		t.Errorf("first survey = %s %s", survey.SurveyDate, survey.SurveyType)
+	}
+	// WAE's total comes from its first row, NOP's is the measured fish.
+	if got := totalCatches(survey); !reflect.DeepEqual(got, map[string]int{"WAE": 10, "NOP": 3}) {
+		t.Errorf("catches = %v", got)
+	}
+	nop := survey.Lengths["NOP"]
+	if nop.MinimumLength != 20 || nop.MaximumLength != 24 || len(nop.FishCount) != 2 {
+		t.Errorf("NOP lengths = %+v", nop)
+	}
+	if got := lengthCounts(survey)["WAE"]; !reflect.DeepEqual(got, map[int]int{14: 3, 18: 2}) {
+		t.Errorf("WAE lengths = %v", got)
+	}
+}
+
+func TestParseLengthFrequencyCSVErrors(t *testing.T) {
+	const header = "dow_number,county,survey_date,species,length,quantity\n"
+	for _, input := range []string{
+		"",
+		"dow_number,county,survey_date,species,length\n",
+		header + "big,Aitkin,2020-06-01,WAE,14,3\n",
+		header + "1000100,Aitkin,2020-06-01,WAE,14.5,3\n",
+		header + "1000100,Aitkin,2020-06-01,,14,3\n",
+	} {
+		if _, err := parseLengthFrequencyCSV(strings.NewReader(input)); err == nil {
+			t.Errorf("%q: want an error", input)
+		}
+	}
+}
+
+func TestCSVSource(t *testing.T) {
+	dir := t.TempDir()
+	writeSourceFile(t, filepath.Join(dir, "lakes.csv"), []byte(lengthFrequencyCSV))
+	writeSourceFile(t, filepath.Join(dir, "broken.csv"), []byte("dow_number\n1\n"))
+
+	byPath, docs := readDocuments(t, CSVSource{Path: dir})
+	if len(docs) != 2 || docs[0].Path != filepath.Join(dir, "lakes.csv")+"#1000100" {
+		t.Fatalf("documents = %v, want the two lakes of lakes.csv", byPath)
+	}
+	if _, ok := docs[1].Adapter.(fishDataAdapter); !ok {
+		t.Errorf("adapter = %#v, want the fishdata adapter", docs[1].Adapter)
+	}
+	if !strings.Contains(string(docs[1].Data), `"Long Lake"`) {
+		t.Errorf("Long Lake document = %s", docs[1].Data)
+	}
+}
+
+func TestZipSource(t *testing.T) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	members := []struct {
+		name string
+		data []byte
+	}{
+		{"surveys/", nil},
+		{"surveys/a.json", []byte(`{"a": 1}`)},
+		{"surveys/b.json.gz", gzipped(t, `{"b": 1}`)},
+		{"surveys/c.jsonl", []byte("{\"c\": 1}\n{\"c\": 2}\n")},
+		{"surveys/d.csv", []byte(lengthFrequencyCSV)},
+		{"README.txt", []byte("not a survey")},
+	}
+	for _, member := range members {
+		f, err := w.Create(member.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write(member.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "surveys.zip")
+	writeSourceFile(t, archive, buf.Bytes())
+	writeSourceFile(t, filepath.Join(dir, "damaged.zip"), []byte("not a zip"))
+
+	byPath, docs := readDocuments(t, ZipSource{Path: dir})
+	var paths []string
+	for _, doc := range docs {
+		paths = append(paths, strings.TrimPrefix(doc.Path, archive+"!"))
+	}
+	want := []string{"surveys/a.json", "surveys/b.json.gz", "surveys/c.jsonl#1", "surveys/c.jsonl#2", "surveys/d.csv#1000100", "surveys/d.csv#11000200"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("documents = %v, want %v", paths, want)
+	}
+	if byPath[archive+"!surveys/b.json.gz"] != `{"b": 1}` || byPath[archive+"!surveys/c.jsonl#2"] != `{"c": 2}` {
+		t.Errorf("documents = %v", byPath)
+	}
+}
+
+func TestMultiSourceStopsOnError(t *testing.T) {
+	dir := t.TempDir()
+	writeSourceFile(t, filepath.Join(dir, "a.json"), []byte(`{}`))
+	writeSourceFile(t, filepath.Join(dir, "b.json"), []byte(`{}`))
+	writeSourceFile(t, filepath.Join(dir, "c.ndjson"), []byte("{}\n"))
+	source := MultiSource{JSONDirSource{Dir: dir}, NDJSONSource{Path: dir}}
+	if name := source.Name(); name != "json_dir:"+dir+", ndjson:"+dir {
+		t.Errorf("name = %q", name)
+	}
+
+	stop := errors.New("stop")
+	var seen []string
+	err := source.Documents(func(doc SurveyDocument) error {
+		seen = append(seen, filepath.Base(doc.Path))
+		return stop
+	})
+	if !errors.Is(err, stop) || !reflect.DeepEqual(seen, []string{"a.json"}) {
+		t.Errorf("err = %v after %v, want stop after a.json", err, seen)
+	}
+}
+
+// The documents of a CSV source load like any other survey file.
+func TestCSVDocumentsLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeSourceFile(t, filepath.Join(dir, "lakes.csv"), []byte(lengthFrequencyCSV))
+	_, docs := readDocuments(t, CSVSource{Path: dir})
+	lakes, err := docs[0].Adapter.Convert(docs[0].Data)
+	if err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+	if len(lakes) != 1 || lakes[0].Result.DOWNumber != 1000100 || len(lakes[0].Result.Surveys) != 2 {
+		t.Errorf("converted lakes = %+v", lakes)
+	}
+}
@@ -0,0 +1,391 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"fishreports/model"
+)
+
+// ValidationEnv is what rules may consult besides the survey itself.
+type ValidationEnv struct {
+	DOWNumber  int
+	SpeciesMap map[string]model.Species
+}
+
+// ValidationRule checks surveys for one kind of data problem. Check returns
+// one flag per problem; the validator fills in the rule name and severity.
+type ValidationRule interface {
+	Name() string
+	DefaultSeverity() string
+	Check(survey *model.Survey, env ValidationEnv) []model.QualityFlag
+}
+
+// FixableRule is a rule that can repair the problems it finds.
+type FixableRule interface {
+	ValidationRule
+	Fix(survey *model.Survey, env ValidationEnv)
+}
+
+// DefaultValidationRules returns the built-in rules in the order they run.
+// Fixes run before the checks that depend on them, so zero-length counts are
+// removed before min/max and catch totals are compared.
+func DefaultValidationRules() []ValidationRule {
+	return []ValidationRule{
+		emptyDateRule{},
+		zeroLengthRule{},
+		minMaxRule{},
+		unknownSpeciesRule{},
+		catchSummaryRule{},
+	}
+}
+
+// Validator runs validation rules with configurable severities.
+type Validator struct {
+	rules      []ValidationRule
+	severities map[string]string
+	mu         sync.RWMutex
+}
+
+// NewValidator creates a validator running the given rules in order.
+func NewValidator(rules ...ValidationRule) *Validator {
+	v := &Validator{severities: make(map[string]string)}
+	for _, rule := range rules {
+		v.Register(rule)
+	}
+	return v
+}
+
+// Register adds a rule, replacing any rule with the same name.
+func (v *Validator) Register(rule ValidationRule) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for i, existing := range v.rules {
+		if existing.Name() == rule.Name() {
+			v.rules[i] = rule
+			return
+		}
+	}
+	v.rules = append(v.rules, rule)
+}
+
+// SetSeverity overrides a rule's severity.
+func (v *Validator) SetSeverity(name, severity string) error {
+	switch severity {
+	case model.SeverityDrop, model.SeverityFlag, model.SeverityFix, model.SeverityOff:
+	default:
+		return fmt.Errorf("unknown severity %q for rule %s", severity, name)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for _, rule := range v.rules {
+		if rule.Name() == name {
+			v.severities[name] = severity
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown validation rule %q", name)
+}
+
+// severity returns the effective severity of a rule. A fix severity on a
+// rule that can't fix falls back to flagging. Callers hold v.mu.
+func (v *Validator) severity(rule ValidationRule) string {
+	severity, exists := v.severities[rule.Name()]
+	if !exists {
+		severity = rule.DefaultSeverity()
+	}
+	if _, fixable := rule.(FixableRule); severity == model.SeverityFix && !fixable {
+		return model.SeverityFlag
+	}
+	return severity
+}
+
+// Validate runs every rule over the lake's surveys and replaces them with the
+// result: dropped surveys are removed, fixable problems are repaired, and the
+// rest are recorded in each survey's QualityFlags.
+func (v *Validator) Validate(fishData *model.FishData, speciesMap map[string]model.Species) *model.ValidationReport {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	report := model.NewValidationReport()
+	env := ValidationEnv{DOWNumber: fishData.Result.DOWNumber, SpeciesMap: speciesMap}
+	// Fixes go into new slices and maps, leaving the caller's surveys as they
+	// were loaded so they can be written back unchanged.
+	kept := make([]model.Survey, 0, len(fishData.Result.Surveys))
+	for _, survey := range fishData.Result.Surveys {
+		report.Surveys++
+		survey.QualityFlags = nil
+		if survey.Lengths != nil {
+			lengths := make(map[string]*model.LengthData, len(survey.Lengths))
+			for code, lengthData := range survey.Lengths {
+				lengths[code] = lengthData
+			}
+			survey.Lengths = lengths
+		}
+		dropped := false
+
+		for _, rule := range v.rules {
+			severity := v.severity(rule)
+			if severity == model.SeverityOff {
+				continue
+			}
+			issues := rule.Check(&survey, env)
+			if len(issues) == 0 {
+				continue
+			}
+
+			ruleReport, exists := report.Rules[rule.Name()]
+			if !exists {
+				ruleReport = &model.RuleReport{Severity: severity}
+				report.Rules[rule.Name()] = ruleReport
+			}
+			ruleReport.Issues += len(issues)
+			ruleReport.Surveys++
+
+			switch severity {
+			case model.SeverityDrop:
+				ruleReport.Dropped++
+				dropped = true
+			case model.SeverityFix:
+				rule.(FixableRule).Fix(&survey, env)
+				ruleReport.Fixed++
+			default:
+				ruleReport.Flagged++
+			}
+			if dropped {
+				break
+			}
+			for _, issue := range issues {
+				issue.Rule = rule.Name()
+				issue.Severity = severity
+				issue.Fixed = severity == model.SeverityFix
+				survey.QualityFlags = append(survey.QualityFlags, issue)
+			}
+		}
+
+		if dropped {
+			report.DroppedSurveys++
+			continue
+		}
+		if len(survey.QualityFlags) > 0 {
+			report.FlaggedSurveys++
+		}
+		kept = append(kept, survey)
+	}
+	fishData.Result.Surveys = kept
+	return report
+}
+
+// sortedLengthCodes returns a survey's length data species codes in order so
+// flags come out deterministically.
+func sortedLengthCodes(survey *model.Survey) []string {
+	codes := make([]string, 0, len(survey.Lengths))
+	for code := range survey.Lengths {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// emptyDateRule: surveys without a date can't be placed in time.
+type emptyDateRule struct{}
+
+func (emptyDateRule) Name() string            { return "empty_date" }
+func (emptyDateRule) DefaultSeverity() string { return model.SeverityDrop }
+
+func (emptyDateRule) Check(survey *model.Survey, env ValidationEnv) []model.QualityFlag {
+	if strings.TrimSpace(survey.SurveyDate) != "" {
+		return nil
+	}
+	return []model.QualityFlag{{Message: "survey has no date"}}
+}
+
+// zeroLengthRule: length histogram entries with a length of 0 or less.
+type zeroLengthRule struct{}
+
+func (zeroLengthRule) Name() string            { return "zero_length" }
+func (zeroLengthRule) DefaultSeverity() string { return model.SeverityFix }
+
+func (zeroLengthRule) Check(survey *model.Survey, env ValidationEnv) []model.QualityFlag {
+	var issues []model.QualityFlag
+	for _, code := range sortedLengthCodes(survey) {
+		lengthData := survey.Lengths[code]
+		if lengthData == nil {
+			continue
+		}
+		found, zero := false, 0
+		for _, count := range lengthData.FishCount {
+			if count.Length <= 0 {
+				found = true
+				zero += count.Quantity
+			}
+		}
+		if found {
+			issues = append(issues, model.QualityFlag{
+				Species: code,
+				Message: fmt.Sprintf("%d fish recorded with a length of 0", zero),
+			})
+		}
+	}
+	return issues
+}
+
+// Fix removes the zero-length entries.
+func (zeroLengthRule) Fix(survey *model.Survey, env ValidationEnv) {
+	for code, lengthData := range survey.Lengths {
+		if lengthData == nil {
+			continue
+		}
+		fixed := *lengthData
+		fixed.FishCount = nil
+		for _, count := range lengthData.FishCount {
+			if count.Length > 0 {
+				fixed.FishCount = append(fixed.FishCount, count)
+			}
+		}
+		survey.Lengths[code] = &fixed
+	}
+}
+
+// minMaxRule: minimum length greater than maximum length.
+type minMaxRule struct{}
+
+func (minMaxRule) Name() string            { return "min_greater_than_max" }
+func (minMaxRule) DefaultSeverity() string { return model.SeverityFix }
+
+func (minMaxRule) Check(survey *model.Survey, env ValidationEnv) []model.QualityFlag {
+	var issues []model.QualityFlag
+	for _, code := range sortedLengthCodes(survey) {
+		lengthData := survey.Lengths[code]
+		if lengthData != nil && lengthData.MinimumLength > lengthData.MaximumLength {
+			issues = append(issues, model.QualityFlag{
+				Species: code,
+				Message: fmt.Sprintf("minimum length %d is greater than maximum length %d", lengthData.MinimumLength, lengthData.MaximumLength),
+			})
+		}
+	}
+	return issues
+}
+
+// Fix takes min and max from the histogram when there is one and swaps them
+// otherwise.
+func (minMaxRule) Fix(survey *model.Survey, env ValidationEnv) {
+	for code, lengthData := range survey.Lengths {
+		if lengthData == nil || lengthData.MinimumLength <= lengthData.MaximumLength {
+			continue
+		}
+		fixed := *lengthData
+		if len(fixed.FishCount) > 0 {
+			fixed.MinimumLength, fixed.MaximumLength = fixed.FishCount[0].Length, fixed.FishCount[0].Length
+			for _, count := range fixed.FishCount {
+				if count.Length < fixed.MinimumLength {
+					fixed.MinimumLength = count.Length
+				}
+				if count.Length > fixed.MaximumLength {
+					fixed.MaximumLength = count.Length
+				}
+			}
+		} else {
+			fixed.MinimumLength, fixed.MaximumLength = fixed.MaximumLength, fixed.MinimumLength
+		}
+		survey.Lengths[code] = &fixed
+	}
+}
+
+// unknownSpeciesRule: species codes missing from the species catalog.
+type unknownSpeciesRule struct{}
+
+func (unknownSpeciesRule) Name() string            { return "unknown_species" }
+func (unknownSpeciesRule) DefaultSeverity() string { return model.SeverityFlag }
+
+func (unknownSpeciesRule) Check(survey *model.Survey, env ValidationEnv) []model.QualityFlag {
+	if len(env.SpeciesMap) == 0 {
+		return nil
+	}
+	var issues []model.QualityFlag
+	for _, code := range surveySpeciesCodes(survey) {
+		if _, known := env.SpeciesMap[code]; !known {
+			issues = append(issues, model.QualityFlag{
+				Species: code,
+				Message: fmt.Sprintf("species code %s is not in the species catalog", code),
+			})
+		}
+	}
+	return issues
+}
+
+// Fix removes the unknown species' length data and catch summaries.
+func (unknownSpeciesRule) Fix(survey *model.Survey, env ValidationEnv) {
+	lengths := make(map[string]*model.LengthData, len(survey.Lengths))
+	for code, lengthData := range survey.Lengths {
+		if _, known := env.SpeciesMap[code]; known {
+			lengths[code] = lengthData
+		}
+	}
+	survey.Lengths = lengths
+
+	var summaries []model.FishCatchSummary
+	for _, summary := range survey.FishCatchSummaries {
+		if summary.Species == nil {
+			continue
+		}
+		if _, known := env.SpeciesMap[*summary.Species]; known {
+			summaries = append(summaries, summary)
+		}
+	}
+	survey.FishCatchSummaries = summaries
+}
+
+// surveySpeciesCodes returns every species code in a survey's length data
+// and catch summaries, sorted and without duplicates.
+func surveySpeciesCodes(survey *model.Survey) []string {
+	seen := make(map[string]bool)
+	for code := range survey.Lengths {
+		seen[code] = true
+	}
+	for _, summary := range survey.FishCatchSummaries {
+		if summary.Species != nil && *summary.Species != "" {
+			seen[*summary.Species] = true
+		}
+	}
+	codes := make([]string, 0, len(seen))
+	for code := range seen {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// catchSummaryRule: a species' total catch disagrees with the sum of its
+// length histogram.
+type catchSummaryRule struct{}
+
+func (catchSummaryRule) Name() string            { return "catch_summary_mismatch" }
+func (catchSummaryRule) DefaultSeverity() string { return model.SeverityFlag }
+
+func (catchSummaryRule) Check(survey *model.Survey, env ValidationEnv) []model.QualityFlag {
+	var issues []model.QualityFlag
+	for _, summary := range survey.FishCatchSummaries {
+		if summary.Species == nil || summary.TotalCatch == nil {
+			continue
+		}
+		lengthData := survey.Lengths[*summary.Species]
+		if lengthData == nil || len(lengthData.FishCount) == 0 {
+			continue
+		}
+		measured := 0
+		for _, count := range lengthData.FishCount {
+			measured += count.Quantity
+		}
+		if measured != *summary.TotalCatch {
+			issues = append(issues, model.QualityFlag{
+				Species: *summary.Species,
+				Message: fmt.Sprintf("catch summary reports %d fish but the length histogram has %d", *summary.TotalCatch, measured),
+			})
+		}
+	}
+	return issues
+}
@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	"fishreports/model"
+)
+
+// newValidationLake returns a lake with one survey breaking each default rule.
+func newValidationLake() model.FishData {
+	minMax := fixtureSurvey("min-max", "2019-06-01", map[string]int{"WAE": 2},
+		map[string][]model.FishCount{"WAE": {{Length: 12, Quantity: 1}, {Length: 18, Quantity: 1}}})
+	minMax.Lengths["WAE"].MinimumLength, minMax.Lengths["WAE"].MaximumLength = 20, 10
+	return fixtureLake(1000100, "Aitkin", "Big Lake",
+		fixtureSurvey("no-date", "", map[string]int{"WAE": 1},
+			map[string][]model.FishCount{"WAE": {{Length: 14, Quantity: 1}}}),
+		fixtureSurvey("zero-length", "2018-06-01", map[string]int{"WAE": 3},
+			map[string][]model.FishCount{"WAE": {{Length: 0, Quantity: 2}, {Length: 14, Quantity: 3}}}),
+		minMax,
+		fixtureSurvey("unknown", "2020-06-01", map[string]int{"XYZ": 1},
+			map[string][]model.FishCount{"XYZ": {{Length: 5, Quantity: 1}}}),
+		fixtureSurvey("mismatch", "2021-06-01", map[string]int{"NOP": 5},
+			map[string][]model.FishCount{"NOP": {{Length: 24, Quantity: 2}}}),
+		fixtureSurvey("clean", "2022-06-01", map[string]int{"YEP": 2},
+			map[string][]model.FishCount{"YEP": {{Length: 7, Quantity: 2}}}),
+	)
+}
+
+// surveyFlags returns the rules flagged on each kept survey by survey ID.
+func surveyFlags(lake model.FishData) map[string][]string {
+	flags := make(map[string][]string)
+	for _, survey := range lake.Result.Surveys {
+		flags[survey.SurveyID] = []string{}
+		for _, flag := range survey.QualityFlags {
+			flags[survey.SurveyID] = append(flags[survey.SurveyID], flag.Rule+"/"+flag.Severity)
+		}
+	}
+	return flags
+}
+
+func TestValidateDefaultRules(t *testing.T) {
+	lake := newValidationLake()
+	report := NewValidator(DefaultValidationRules()...).Validate(&lake, fixtureSpecies())
+
+	want := map[string][]string{
+		"zero-length": {"zero_length/fix"},
+		"min-max":     {"min_greater_than_max/fix"},
+		"unknown":     {"unknown_species/flag"},
+		"mismatch":    {"catch_summary_mismatch/flag"},
+		"clean":       {},
+	}
+	if got := surveyFlags(lake); !reflect.DeepEqual(got, want) {
+		t.Errorf("flags = %v, want %v", got, want)
+	}
+	if report.Surveys != 6 || report.DroppedSurveys != 1 || report.FlaggedSurveys != 4 {
+		t.Errorf("report = %d surveys, %d dropped, %d flagged; want 6, 1, 4", report.Surveys, report.DroppedSurveys, report.FlaggedSurveys)
+	}
+	if rule := report.Rules["empty_date"]; rule == nil || rule.Dropped != 1 || rule.Severity != model.SeverityDrop {
+		t.Errorf("empty_date report = %+v", rule)
+	}
+	if rule := report.Rules["zero_length"]; rule == nil || rule.Fixed != 1 || rule.Issues != 1 {
+		t.Errorf("zero_length report = %+v", rule)
+	}
+
+	for _, survey := range lake.Result.Surveys {
+		switch survey.SurveyID {
+		case "zero-length":
+			// With the zero-length fish gone the catch summary agrees.
+			if counts := survey.Lengths["WAE"].FishCount; len(counts) != 1 || counts[0].Length != 14 {
+				t.Errorf("zero-length counts = %v, want only the 14 inch fish", counts)
+			}
+			if !survey.QualityFlags[0].Fixed || survey.QualityFlags[0].Species != "WAE" {
+				t.Errorf("zero-length flag = %+v", survey.QualityFlags[0])
+			}
+		case "min-max":
+			if l := survey.Lengths["WAE"]; l.MinimumLength != 12 || l.MaximumLength != 18 {
+				t.Errorf("min-max = %d-%d, want 12-18 from the histogram", l.MinimumLength, l.MaximumLength)
+			}
+		}
+	}
+}
+
+func TestValidateLeavesTheInputUnchanged(t *testing.T) {
+	lake := newValidationLake()
+	live := lake
+	NewValidator(DefaultValidationRules()...).Validate(&live, fixtureSpecies())
+
+	if !reflect.DeepEqual(lake, newValidationLake()) {
+		t.Error("validating a copy of the lake changed the original surveys")
+	}
+	if len(live.Result.Surveys) != 5 {
+		t.Errorf("the validated copy kept %d surveys, want 5", len(live.Result.Surveys))
+	}
+}
+
+func TestValidatorSeverities(t *testing.T) {
+	tests := []struct {
+		rule, severity string
+		survey         string
+		want           []string // the survey's flags, nil when dropped
+	}{
+		{"empty_date", model.SeverityFlag, "no-date", []string{"empty_date/flag"}},
+		{"zero_length", model.SeverityOff, "zero-length", []string{"catch_summary_mismatch/flag"}},
+		{"unknown_species", model.SeverityFix, "unknown", []string{"unknown_species/fix"}},
+		{"unknown_species", model.SeverityDrop, "unknown", nil},
+		// The catch summary rule can't fix, so it flags instead.
+		{"catch_summary_mismatch", model.SeverityFix, "mismatch", []string{"catch_summary_mismatch/flag"}},
+	}
+	for _, tt := range tests {
+		v := NewValidator(DefaultValidationRules()...)
+		if err := v.SetSeverity(tt.rule, tt.severity); err != nil {
+			t.Fatalf("SetSeverity(%s, %s): %v", tt.rule, tt.severity, err)
+		}
+		lake := newValidationLake()
+		v.Validate(&lake, fixtureSpecies())
+		got, kept := surveyFlags(lake)[tt.survey]
+		if tt.want == nil {
+			if kept {
+				t.Errorf("%s %s: survey %s kept with %v, want it dropped", tt.rule, tt.severity, tt.survey, got)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %s: survey %s flags = %v, want %v", tt.rule, tt.severity, tt.survey, got, tt.want)
+		}
+	}
+
+	// Fixing unknown species removes them.
+	v := NewValidator(DefaultValidationRules()...)
+	_ = v.SetSeverity("unknown_species", model.SeverityFix)
+	lake := newValidationLake()
+	v.Validate(&lake, fixtureSpecies())
+	for _, survey := range lake.Result.Surveys {
+		if survey.SurveyID == "unknown" && (len(survey.Lengths) != 0 || len(survey.FishCatchSummaries) != 0) {
+			t.Errorf("unknown species left in %+v", survey)
+		}
+	}
+}
+
+func TestSetSeverityRejectsUnknownValues(t *testing.T) {
+	v := NewValidator(DefaultValidationRules()...)
+	if err := v.SetSeverity("no_such_rule", model.SeverityFlag); err == nil {
+		t.Error("unknown rule accepted")
+	}
+	if err := v.SetSeverity("zero_length", "ignore"); err == nil {
+		t.Error("unknown severity accepted")
+	}
+}
+
+// lowDOWRule flags surveys of lakes with a DOW below 2000000.
+type lowDOWRule struct{ severity string }
+
+func (lowDOWRule) Name() string              { return "low_dow" }
+func (r lowDOWRule) DefaultSeverity() string { return r.severity }
+
+func (lowDOWRule) Check(survey *model.Survey, env ValidationEnv) []model.QualityFlag {
+	if env.DOWNumber < 2000000 {
+		return []model.QualityFlag{{Message: "low DOW"}}
+	}
+	return nil
+}
+
+func TestRegisterAddsAndReplacesRules(t *testing.T) {
+	v := NewValidator()
+	v.Register(lowDOWRule{severity: model.SeverityFlag})
+	lake := newValidationLake()
+	report := v.Validate(&lake, nil)
+	if report.FlaggedSurveys != 6 || report.Rules["low_dow"].Flagged != 6 {
+		t.Errorf("custom rule report = %+v", report)
+	}
+
+	v.Register(lowDOWRule{severity: model.SeverityDrop})
+	lake = newValidationLake()
+	if report := v.Validate(&lake, nil); report.DroppedSurveys != 6 || len(lake.Result.Surveys) != 0 || len(report.Rules) != 1 {
+		t.Errorf("replaced rule report = %+v, %d surveys kept", report, len(lake.Result.Surveys))
+	}
+}
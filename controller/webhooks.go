@@ -0,0 +1,503 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"fishreports/model"
+
+	"github.com/google/uuid"
+)
+
+// EventWebhookPing is the event type sent by a manual test delivery.
+const EventWebhookPing = "webhook.ping"
+
+// ErrInvalidWebhook is returned when a subscription fails validation.
+var ErrInvalidWebhook = errors.New("invalid webhook subscription")
+
+// Webhook request headers.
+const (
+	WebhookSignatureHeader = "X-FishReports-Signature"
+	WebhookTimestampHeader = "X-FishReports-Timestamp"
+	WebhookEventHeader     = "X-FishReports-Event"
+	WebhookDeliveryHeader  = "X-FishReports-Delivery"
+)
+
+// SignWebhookPayload returns the signature header value for a payload:
+// "sha256=" followed by the hex HMAC-SHA256 of "<timestamp>.<body>" keyed by
+// the subscription secret. Receivers recompute it to verify a delivery.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// LoadWebhooks reads webhook subscriptions from a JSON file. A missing file
+// yields no subscriptions.
+func LoadWebhooks(filePath string) ([]model.WebhookSubscription, error) {
+	var subscriptions []model.WebhookSubscription
+	file, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook file: %w", err)
+	}
+	if err := json.Unmarshal(file, &subscriptions); err != nil {
+		return nil, fmt.Errorf("failed to parse webhook JSON: %w", err)
+	}
+	return subscriptions, nil
+}
+
+// WebhookController stores webhook subscriptions and delivers survey events
+// to them. Deliveries are signed, retried with exponential backoff on network
+// errors, 408, 429 and 5xx responses, and every attempt is kept in a bounded
+// delivery log.
+type WebhookController struct {
+	File           string        // subscriptions are persisted here; empty keeps them in memory
+	Client         *http.Client  // HTTP client used for deliveries
+	MaxAttempts    int           // attempts per event, including the first
+	InitialBackoff time.Duration // wait before the first retry, doubled for each retry after it
+	MaxBackoff     time.Duration
+
+	subscriptions map[string]model.WebhookSubscription
+	deliveries    []model.WebhookDelivery // oldest first
+	logSize       int
+	slots         chan struct{} // bounds concurrent HTTP requests
+	wg            sync.WaitGroup
+	mu            sync.RWMutex
+}
+
+// NewWebhookController creates a controller for the given subscriptions,
+// keeping up to logSize delivery attempts and making at most concurrency
+// requests at once.
+func NewWebhookController(file string, subscriptions []model.WebhookSubscription, logSize, concurrency int) *WebhookController {
+	if logSize <= 0 {
+		logSize = 1000
+	}
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	wc := &WebhookController{
+		File:           file,
+		Client:         &http.Client{Timeout: 10 * time.Second},
+		MaxAttempts:    6,
+		InitialBackoff: 5 * time.Second,
+		MaxBackoff:     10 * time.Minute,
+		subscriptions:  make(map[string]model.WebhookSubscription, len(subscriptions)),
+		logSize:        logSize,
+		slots:          make(chan struct{}, concurrency),
+	}
+	for _, sub := range subscriptions {
+		wc.subscriptions[sub.ID] = sub
+	}
+	log.Printf("✅ Loaded %d webhook subscriptions", len(wc.subscriptions))
+	return wc
+}
+
+// ListSubscriptions returns the subscriptions, oldest first, with secrets masked.
+func (wc *WebhookController) ListSubscriptions() []model.WebhookSubscription {
+	wc.mu.RLock()
+	defer wc.mu.RUnlock()
+
+	list := make([]model.WebhookSubscription, 0, len(wc.subscriptions))
+	for _, sub := range wc.subscriptions {
+		sub.Secret = maskSecret(sub.Secret)
+		list = append(list, sub)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// GetSubscription returns a subscription with its secret masked, or nil.
+func (wc *WebhookController) GetSubscription(id string) *model.WebhookSubscription {
+	wc.mu.RLock()
+	defer wc.mu.RUnlock()
+
+	sub, exists := wc.subscriptions[id]
+	if !exists {
+		return nil
+	}
+	sub.Secret = maskSecret(sub.Secret)
+	return &sub
+}
+
+// CreateSubscription validates and stores a new subscription. The ID and
+// creation time are assigned here, and a secret is generated when none is
+// given. The returned subscription is the only place the secret is shown.
+func (wc *WebhookController) CreateSubscription(sub model.WebhookSubscription) (model.WebhookSubscription, error) {
+	target, err := url.Parse(sub.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return sub, fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+	for _, eventType := range sub.EventTypes {
+		if eventType != model.EventSurveyAdded && eventType != model.EventSurveyUpdated && eventType != model.EventSurveyRemoved {
+			return sub, fmt.Errorf("%w: unknown event type %q", ErrInvalidWebhook, eventType)
+		}
+	}
+	if sub.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return sub, err
+		}
+		sub.Secret = hex.EncodeToString(secret)
+	}
+	sub.ID = uuid.New().String()
+	sub.CreatedAt = time.Now().UTC()
+
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	wc.subscriptions[sub.ID] = sub
+	if err := wc.save(); err != nil {
+		delete(wc.subscriptions, sub.ID)
+		return sub, err
+	}
+	return sub, nil
+}
+
+// DeleteSubscription removes a subscription, reporting whether it existed.
+func (wc *WebhookController) DeleteSubscription(id string) (bool, error) {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+
+	sub, exists := wc.subscriptions[id]
+	if !exists {
+		return false, nil
+	}
+	delete(wc.subscriptions, id)
+	if err := wc.save(); err != nil {
+		wc.subscriptions[id] = sub
+		return false, err
+	}
+	return true, nil
+}
+
+// save persists the subscriptions. Callers hold wc.mu.
+func (wc *WebhookController) save() error {
+	if wc.File == "" {
+		return nil
+	}
+	list := make([]model.WebhookSubscription, 0, len(wc.subscriptions))
+	for _, sub := range wc.subscriptions {
+		list = append(list, sub)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(wc.File, data); err != nil {
+		return fmt.Errorf("failed to save webhooks: %w", err)
+	}
+	return nil
+}
+
+// Deliveries returns the logged attempts for a subscription (every
+// subscription when id is empty), newest first, at most limit entries.
+func (wc *WebhookController) Deliveries(id string, limit int) []model.WebhookDelivery {
+	wc.mu.RLock()
+	defer wc.mu.RUnlock()
+
+	var found []model.WebhookDelivery
+	for i := len(wc.deliveries) - 1; i >= 0 && (limit <= 0 || len(found) < limit); i-- {
+		if id == "" || wc.deliveries[i].SubscriptionID == id {
+			found = append(found, wc.deliveries[i])
+		}
+	}
+	return found
+}
+
+// record appends an attempt to the bounded delivery log.
+func (wc *WebhookController) record(delivery model.WebhookDelivery) {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+
+	wc.deliveries = append(wc.deliveries, delivery)
+	if overflow := len(wc.deliveries) - wc.logSize; overflow > 0 {
+		wc.deliveries = append([]model.WebhookDelivery(nil), wc.deliveries[overflow:]...)
+	}
+}
+
+// Matches reports whether a subscription wants an event.
+func (wc *WebhookController) Matches(sub model.WebhookSubscription, event model.SurveyEvent) bool {
+	if sub.Disabled {
+		return false
+	}
+	if len(sub.EventTypes) > 0 && !containsString(sub.EventTypes, event.Type) {
+		return false
+	}
+	if sub.GameFishOnly && !event.GameFish {
+		return false
+	}
+	if len(sub.CountyIDs) > 0 {
+		matched := false
+		for _, id := range event.CountyIDs {
+			if containsString(sub.CountyIDs, id) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(sub.DOWNumbers) > 0 {
+		matched := false
+		for _, dow := range sub.DOWNumbers {
+			if dow == event.DOWNumber {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(sub.SpeciesIDs) > 0 {
+		for _, id := range event.SpeciesIDs {
+			if containsString(sub.SpeciesIDs, id) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// containsString reports whether list holds s, ignoring case.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Dispatch starts a delivery of the event to every matching subscription.
+func (wc *WebhookController) Dispatch(ctx context.Context, event model.SurveyEvent) {
+	wc.mu.RLock()
+	var targets []model.WebhookSubscription
+	for _, sub := range wc.subscriptions {
+		if wc.Matches(sub, event) {
+			targets = append(targets, sub)
+		}
+	}
+	wc.mu.RUnlock()
+
+	for _, sub := range targets {
+		wc.wg.Add(1)
+		go func(sub model.WebhookSubscription) {
+			defer wc.wg.Done()
+			wc.deliver(ctx, sub, event)
+		}(sub)
+	}
+}
+
+// Run dispatches events from the survey event log until ctx is done, starting
+// with the events already in the log. If the log drops this subscriber for
+// falling behind, it resubscribes and catches up from the retained events.
+func (wc *WebhookController) Run(ctx context.Context, events *SurveyEventLog) {
+	var lastID int64
+	for {
+		live, cancel := events.Subscribe(1024)
+		missed, complete := events.Since(lastID)
+		if lastID > 0 && !complete {
+			log.Printf("❌ Webhooks fell behind the event log; some events were not delivered")
+		}
+		for _, event := range missed {
+			lastID = event.ID
+			wc.Dispatch(ctx, event)
+		}
+
+	stream:
+		for {
+			select {
+			case <-ctx.Done():
+				cancel()
+				return
+			case event, ok := <-live:
+				if !ok {
+					break stream
+				}
+				if event.ID <= lastID {
+					continue
+				}
+				lastID = event.ID
+				wc.Dispatch(ctx, event)
+			}
+		}
+		cancel()
+	}
+}
+
+// Wait blocks until in-flight deliveries, including their retries, finish.
+func (wc *WebhookController) Wait() {
+	wc.wg.Wait()
+}
+
+// Ping sends one test delivery to a subscription without retries.
+func (wc *WebhookController) Ping(ctx context.Context, id string) (*model.WebhookDelivery, error) {
+	wc.mu.RLock()
+	sub, exists := wc.subscriptions[id]
+	wc.mu.RUnlock()
+	if !exists {
+		return nil, nil
+	}
+	event := model.SurveyEvent{Type: EventWebhookPing, CreatedAt: time.Now().UTC()}
+	delivery := wc.attempt(ctx, sub, event, uuid.New().String(), 1)
+	delivery.Final = true
+	wc.record(delivery)
+	return &delivery, nil
+}
+
+// deliver POSTs an event to a subscription, retrying with exponential
+// backoff until it succeeds, fails permanently or runs out of attempts.
+func (wc *WebhookController) deliver(ctx context.Context, sub model.WebhookSubscription, event model.SurveyEvent) {
+	deliveryID := uuid.New().String()
+	maxAttempts := wc.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		delivery := wc.attempt(ctx, sub, event, deliveryID, attempt)
+		retry := !delivery.Success && retryableDelivery(delivery) && attempt < maxAttempts && ctx.Err() == nil
+		delivery.Final = !retry
+		wc.record(delivery)
+		if !retry {
+			if !delivery.Success {
+				log.Printf("❌ Webhook %s gave up on event %d after %d attempts: %s", sub.ID, event.ID, attempt, deliveryFailure(delivery))
+			}
+			return
+		}
+
+		timer := time.NewTimer(wc.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the wait after the given failed attempt.
+func (wc *WebhookController) backoff(attempt int) time.Duration {
+	wait := wc.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		wait *= 2
+		if wc.MaxBackoff > 0 && wait >= wc.MaxBackoff {
+			return wc.MaxBackoff
+		}
+	}
+	return wait
+}
+
+// attempt makes one signed delivery request.
+func (wc *WebhookController) attempt(ctx context.Context, sub model.WebhookSubscription, event model.SurveyEvent, deliveryID string, attempt int) model.WebhookDelivery {
+	delivery := model.WebhookDelivery{
+		DeliveryID:     deliveryID,
+		SubscriptionID: sub.ID,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		URL:            sub.URL,
+		Attempt:        attempt,
+		AttemptedAt:    time.Now().UTC(),
+	}
+
+	select {
+	case wc.slots <- struct{}{}:
+		defer func() { <-wc.slots }()
+	case <-ctx.Done():
+		delivery.Error = ctx.Err().Error()
+		return delivery
+	}
+
+	now := time.Now().UTC()
+	body, err := json.Marshal(model.WebhookPayload{
+		DeliveryID:     deliveryID,
+		SubscriptionID: sub.ID,
+		Event:          event,
+		SentAt:         now,
+	})
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "FishReports-Webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, event.Type)
+	req.Header.Set(WebhookDeliveryHeader, deliveryID)
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(sub.Secret, now.Unix(), body))
+
+	client := wc.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	delivery.DurationMS = time.Since(start).Milliseconds()
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	delivery.StatusCode = resp.StatusCode
+	delivery.Success = resp.StatusCode >= 200 && resp.StatusCode < 300
+	return delivery
+}
+
+// retryableDelivery reports whether a failed attempt is worth retrying:
+// network errors, timeouts, rate limits and server errors.
+func retryableDelivery(delivery model.WebhookDelivery) bool {
+	switch {
+	case delivery.StatusCode == 0:
+		return true
+	case delivery.StatusCode == http.StatusRequestTimeout, delivery.StatusCode == http.StatusTooManyRequests:
+		return true
+	default:
+		return delivery.StatusCode >= 500
+	}
+}
+
+// deliveryFailure describes why an attempt failed.
+func deliveryFailure(delivery model.WebhookDelivery) string {
+	if delivery.Error != "" {
+		return delivery.Error
+	}
+	return "HTTP " + strconv.Itoa(delivery.StatusCode)
+}
@@ -0,0 +1,233 @@
+package controller
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"fishreports/model"
+)
+
+// webhookReceiver is a local subscriber answering with the given status
+// codes in turn, then 200, and recording every request it gets.
+type webhookReceiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	requests []receivedWebhook
+}
+
+type receivedWebhook struct {
+	at      time.Time
+	headers http.Header
+	body    []byte
+}
+
+func newWebhookReceiver(t *testing.T, statuses ...int) *webhookReceiver {
+	t.Helper()
+	r := &webhookReceiver{statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		status := http.StatusOK
+		if n := len(r.requests); n < len(r.statuses) {
+			status = r.statuses[n]
+		}
+		r.requests = append(r.requests, receivedWebhook{at: time.Now(), headers: req.Header.Clone(), body: body})
+		r.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *webhookReceiver) received() []receivedWebhook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]receivedWebhook(nil), r.requests...)
+}
+
+// newTestWebhooks returns a controller with one subscription to url and
+// short backoffs.
+func newTestWebhooks(t *testing.T, url string) (*WebhookController, model.WebhookSubscription) {
+	t.Helper()
+	wc := NewWebhookController("", nil, 100, 2)
+	wc.MaxAttempts = 4
+	wc.InitialBackoff = 20 * time.Millisecond
+	wc.MaxBackoff = time.Second
+	sub, err := wc.CreateSubscription(model.WebhookSubscription{URL: url, Secret: "test-secret"})
+	if err != nil {
+		t.Fatalf("CreateSubscription: %v", err)
+	}
+	return wc, sub
+}
+
+func testSurveyEvent() model.SurveyEvent {
+	return model.SurveyEvent{ID: 7, Type: model.EventSurveyAdded, DOWNumber: 18005000, SurveyDate: "2024-06-01"}
+}
+
+func TestWebhookDeliveryIsSigned(t *testing.T) {
+	receiver := newWebhookReceiver(t)
+	wc, sub := newTestWebhooks(t, receiver.URL)
+
+	wc.Dispatch(context.Background(), testSurveyEvent())
+	wc.Wait()
+
+	requests := receiver.received()
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(requests))
+	}
+	req := requests[0]
+	timestamp, err := strconv.ParseInt(req.headers.Get(WebhookTimestampHeader), 10, 64)
+	if err != nil {
+		t.Fatalf("bad timestamp header %q", req.headers.Get(WebhookTimestampHeader))
+	}
+	mac := hmac.New(sha256.New, []byte(sub.Secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(req.body)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := req.headers.Get(WebhookSignatureHeader); !hmac.Equal([]byte(got), []byte(want)) {
+		t.Errorf("signature = %q, want %q", got, want)
+	}
+	if got := req.headers.Get(WebhookEventHeader); got != model.EventSurveyAdded {
+		t.Errorf("event header = %q, want %q", got, model.EventSurveyAdded)
+	}
+
+	var payload model.WebhookPayload
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatalf("payload: %v", err)
+	}
+	if payload.SubscriptionID != sub.ID || payload.Event.DOWNumber != 18005000 {
+		t.Errorf("payload = %+v", payload)
+	}
+	if payload.DeliveryID != req.headers.Get(WebhookDeliveryHeader) {
+		t.Errorf("delivery ID %q doesn't match header %q", payload.DeliveryID, req.headers.Get(WebhookDeliveryHeader))
+	}
+}
+
+func TestWebhookRetriesServerErrorsWithBackoff(t *testing.T) {
+	receiver := newWebhookReceiver(t, http.StatusInternalServerError, http.StatusTooManyRequests, http.StatusServiceUnavailable)
+	wc, sub := newTestWebhooks(t, receiver.URL)
+
+	wc.Dispatch(context.Background(), testSurveyEvent())
+	wc.Wait()
+
+	requests := receiver.received()
+	if len(requests) != 4 {
+		t.Fatalf("got %d requests, want 4", len(requests))
+	}
+	// The waits double: 20ms, 40ms, 80ms.
+	for i := 1; i < len(requests); i++ {
+		wait := wc.InitialBackoff << (i - 1)
+		if gap := requests[i].at.Sub(requests[i-1].at); gap < wait {
+			t.Errorf("retry %d came after %v, want at least %v", i, gap, wait)
+		}
+	}
+	// Every attempt of one event shares its delivery ID.
+	for _, req := range requests[1:] {
+		if req.headers.Get(WebhookDeliveryHeader) != requests[0].headers.Get(WebhookDeliveryHeader) {
+			t.Errorf("retry used delivery %q, want %q", req.headers.Get(WebhookDeliveryHeader), requests[0].headers.Get(WebhookDeliveryHeader))
+		}
+	}
+
+	deliveries := wc.Deliveries(sub.ID, 0)
+	if len(deliveries) != 4 {
+		t.Fatalf("logged %d attempts, want 4", len(deliveries))
+	}
+	latest := deliveries[0]
+	if !latest.Success || !latest.Final || latest.Attempt != 4 {
+		t.Errorf("last attempt = %+v, want a final success on attempt 4", latest)
+	}
+	for _, delivery := range deliveries[1:] {
+		if delivery.Success || delivery.Final {
+			t.Errorf("attempt %d = %+v, want a failure with a retry", delivery.Attempt, delivery)
+		}
+	}
+}
+
+func TestWebhookGivesUpAfterMaxAttempts(t *testing.T) {
+	receiver := newWebhookReceiver(t, 500, 500, 500, 500, 500)
+	wc, sub := newTestWebhooks(t, receiver.URL)
+
+	wc.Dispatch(context.Background(), testSurveyEvent())
+	wc.Wait()
+
+	if got := len(receiver.received()); got != wc.MaxAttempts {
+		t.Fatalf("got %d requests, want %d", got, wc.MaxAttempts)
+	}
+	if latest := wc.Deliveries(sub.ID, 1)[0]; latest.Success || !latest.Final {
+		t.Errorf("last attempt = %+v, want a final failure", latest)
+	}
+}
+
+func TestWebhookDoesNotRetryClientErrors(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusGone} {
+		t.Run(strconv.Itoa(status), func(t *testing.T) {
+			receiver := newWebhookReceiver(t, status)
+			wc, sub := newTestWebhooks(t, receiver.URL)
+
+			wc.Dispatch(context.Background(), testSurveyEvent())
+			wc.Wait()
+
+			if got := len(receiver.received()); got != 1 {
+				t.Fatalf("got %d requests, want 1", got)
+			}
+			deliveries := wc.Deliveries(sub.ID, 0)
+			if len(deliveries) != 1 || deliveries[0].Success || !deliveries[0].Final || deliveries[0].StatusCode != status {
+				t.Errorf("deliveries = %+v, want one final failure with status %d", deliveries, status)
+			}
+		})
+	}
+}
+
+func TestWebhookBackoffIsCapped(t *testing.T) {
+	wc := &WebhookController{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, wait := range want {
+		if got := wc.backoff(i + 1); got != wait {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, wait)
+		}
+	}
+}
+
+func TestWebhookMatches(t *testing.T) {
+	wc := NewWebhookController("", nil, 10, 1)
+	event := model.SurveyEvent{
+		Type:       model.EventSurveyUpdated,
+		DOWNumber:  18005000,
+		CountyIDs:  []string{"county-a", "county-c"},
+		SpeciesIDs: []string{"walleye", "perch"},
+		GameFish:   true,
+	}
+	tests := []struct {
+		name string
+		sub  model.WebhookSubscription
+		want bool
+	}{
+		{"no filters", model.WebhookSubscription{}, true},
+		{"disabled", model.WebhookSubscription{Disabled: true}, false},
+		{"event type", model.WebhookSubscription{EventTypes: []string{model.EventSurveyAdded}}, false},
+		{"county", model.WebhookSubscription{CountyIDs: []string{"COUNTY-A"}}, true},
+		{"second county", model.WebhookSubscription{CountyIDs: []string{"county-b", "county-c"}}, true},
+		{"other county", model.WebhookSubscription{CountyIDs: []string{"county-b"}}, false},
+		{"dow", model.WebhookSubscription{DOWNumbers: []int{1, 18005000}}, true},
+		{"other dow", model.WebhookSubscription{DOWNumbers: []int{1}}, false},
+		{"species", model.WebhookSubscription{SpeciesIDs: []string{"perch"}}, true},
+		{"other species", model.WebhookSubscription{SpeciesIDs: []string{"pike"}}, false},
+		{"game fish", model.WebhookSubscription{GameFishOnly: true}, true},
+	}
+	for _, tt := range tests {
+		if got := wc.Matches(tt.sub, event); got != tt.want {
+			t.Errorf("%s: Matches = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
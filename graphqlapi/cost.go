@@ -0,0 +1,166 @@
+package graphqlapi
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// listSizes estimates how many items each list field returns when the query
+// doesn't bound it with a limit argument.
+var listSizes = map[string]int{
+	"counties":           90,
+	"lakes":              50,
+	"surveys":            20,
+	"lengths":            15,
+	"fishCount":          30,
+	"fishCatchSummaries": 15,
+	"species":            100,
+	"data":               50,
+}
+
+// Cost is the static cost estimate of a query.
+type Cost struct {
+	Cost  int
+	Depth int
+}
+
+// EstimateCost parses a query and estimates how many objects it can resolve.
+// Every object field costs 1 plus the cost of its selections times the
+// expected list size (the field's "limit" argument when given). Leaf fields
+// are free. The estimate runs before execution so expensive queries are
+// rejected without touching the data.
+func EstimateCost(query string, operationName string, variables map[string]interface{}) (Cost, error) {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return Cost{}, err
+	}
+
+	fragments := make(map[string]*ast.FragmentDefinition)
+	var operation *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch d := def.(type) {
+		case *ast.FragmentDefinition:
+			fragments[d.Name.Value] = d
+		case *ast.OperationDefinition:
+			if operationName == "" || (d.Name != nil && d.Name.Value == operationName) {
+				if operation == nil {
+					operation = d
+				}
+			}
+		}
+	}
+	if operation == nil {
+		return Cost{}, fmt.Errorf("no operation found")
+	}
+
+	e := &costEstimator{fragments: fragments, variables: variables, visiting: make(map[string]bool)}
+	cost, depth := e.selectionSet(operation.SelectionSet, true, 0)
+	return Cost{Cost: cost, Depth: depth}, nil
+}
+
+type costEstimator struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	visiting  map[string]bool // guards against fragment cycles
+}
+
+// selectionSet returns the cost and depth of a selection set. root marks the
+// Query type's fields; pageLimit carries the limit of an enclosing SurveyPage
+// to its "data" list.
+func (e *costEstimator) selectionSet(set *ast.SelectionSet, root bool, pageLimit int) (int, int) {
+	if set == nil {
+		return 0, 0
+	}
+	total, maxDepth := 0, 0
+	for _, selection := range set.Selections {
+		var cost, depth int
+		switch s := selection.(type) {
+		case *ast.Field:
+			cost, depth = e.field(s, root, pageLimit)
+		case *ast.InlineFragment:
+			cost, depth = e.selectionSet(s.SelectionSet, root, pageLimit)
+		case *ast.FragmentSpread:
+			fragment, exists := e.fragments[s.Name.Value]
+			if !exists || e.visiting[s.Name.Value] {
+				continue
+			}
+			e.visiting[s.Name.Value] = true
+			cost, depth = e.selectionSet(fragment.SelectionSet, root, pageLimit)
+			delete(e.visiting, s.Name.Value)
+		}
+		total = saturate(total + cost)
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+	}
+	return total, maxDepth
+}
+
+// field returns the cost and depth of one field.
+func (e *costEstimator) field(field *ast.Field, root bool, pageLimit int) (int, int) {
+	if field.SelectionSet == nil {
+		return 0, 0
+	}
+	limit, hasLimit := e.intArgument(field, "limit")
+
+	// Query.surveys returns a single SurveyPage whose data list is bounded by
+	// the page limit (50 by default, like GET /surveys).
+	if root && field.Name.Value == "surveys" {
+		if !hasLimit || limit <= 0 {
+			limit = 50
+		}
+		childCost, childDepth := e.selectionSet(field.SelectionSet, false, limit)
+		return saturate(1 + childCost), childDepth + 1
+	}
+
+	childCost, childDepth := e.selectionSet(field.SelectionSet, false, 0)
+	size := 1
+	if n, known := listSizes[field.Name.Value]; known {
+		size = n
+	}
+	if field.Name.Value == "data" && pageLimit > 0 {
+		size = pageLimit
+	}
+	if hasLimit && limit > 0 {
+		size = limit
+	}
+	if size > costCeiling {
+		size = costCeiling
+	}
+	return saturate(1 + size*childCost), childDepth + 1
+}
+
+// costCeiling bounds intermediate costs so huge limits can't overflow.
+const costCeiling = 1 << 30
+
+func saturate(cost int) int {
+	if cost > costCeiling || cost < 0 {
+		return costCeiling
+	}
+	return cost
+}
+
+// intArgument resolves an integer argument from a literal or a variable.
+func (e *costEstimator) intArgument(field *ast.Field, name string) (int, bool) {
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != name {
+			continue
+		}
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			n, err := strconv.Atoi(v.Value)
+			return n, err == nil
+		case *ast.Variable:
+			switch n := e.variables[v.Name.Value].(type) {
+			case float64:
+				return int(n), true
+			case int:
+				return n, true
+			}
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,99 @@
+package graphqlapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"fishreports/controller"
+	"fishreports/model"
+)
+
+func TestEstimateCost(t *testing.T) {
+	const byLimit = `query Q($n: Int) { lake(dow: 1) { surveys(limit: $n) { lengths { fishCount { length } } } } }`
+	for _, tt := range []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		want      Cost
+	}{
+		{"leaves are free", `{ counties { id countyName } }`, nil, Cost{Cost: 1, Depth: 1}},
+		// surveys: 1; lakes: 1 + 50*1; counties: 1 + 90*51.
+		{"default list sizes", `{ counties { id lakes { name surveys { surveyID } } } }`, nil, Cost{Cost: 4591, Depth: 3}},
+		// fishCount: 1; lengths: 1 + 15*1; surveys: 1 + 20*16; lake: 1 + 321.
+		{"no limit", `{ lake(dow: 1) { surveys { lengths { fishCount { length } } } } }`, nil, Cost{Cost: 322, Depth: 4}},
+		// surveys: 1 + 3*16; lake: 1 + 49.
+		{"limit literal", `{ lake(dow: 1) { surveys(limit: 3) { lengths { fishCount { length } } } } }`, nil, Cost{Cost: 50, Depth: 4}},
+		{"limit variable from JSON", byLimit, map[string]interface{}{"n": float64(3)}, Cost{Cost: 50, Depth: 4}},
+		{"limit variable as int", byLimit, map[string]interface{}{"n": 3}, Cost{Cost: 50, Depth: 4}},
+		{"missing limit variable", byLimit, nil, Cost{Cost: 322, Depth: 4}},
+		{"zero limit", `{ lake(dow: 1) { surveys(limit: 0) { lengths { fishCount { length } } } } }`, nil, Cost{Cost: 322, Depth: 4}},
+		{"inline fragments", `{ lake(dow: 1) { ... on Lake { surveys(limit: 2) { lengths { speciesCode } } } } }`, nil, Cost{Cost: 4, Depth: 3}},
+		// The survey page's data list is as long as the page, 50 rows by
+		// default. Rows are leaves today; the estimate doesn't check the
+		// schema, so a nested selection shows the sizing.
+		{"survey page", `{ surveys { total data { surveyID } } }`, nil, Cost{Cost: 2, Depth: 2}},
+		{"survey page rows", `{ surveys { data { lengths { speciesCode } } } }`, nil, Cost{Cost: 52, Depth: 3}},
+		{"survey page limit", `{ surveys(limit: 10) { data { lengths { speciesCode } } } }`, nil, Cost{Cost: 12, Depth: 3}},
+		// lakes: 1; counties: 1 + 90*1, twice.
+		{"fragment used twice", `{ a: counties { ...L } b: counties { ...L } } fragment L on County { lakes { name } }`, nil, Cost{Cost: 182, Depth: 2}},
+		// The cycle is cut where it re-enters A: county: 1; lakes: 1 + 50*1; counties: 1 + 90*51.
+		{"fragment cycle", `{ counties { ...A } } fragment A on County { lakes { ...B } } fragment B on Lake { county { ...A } }`, nil, Cost{Cost: 4591, Depth: 3}},
+		{"fragment spreading itself", `{ counties { ...A } } fragment A on County { id ...A }`, nil, Cost{Cost: 1, Depth: 1}},
+		{"unknown fragment", `{ counties { ...Missing } }`, nil, Cost{Cost: 1, Depth: 1}},
+		{"huge limits saturate", `{ surveys(limit: 2000000000) { data { lengths { fishCount { length } } } } }`, nil, Cost{Cost: costCeiling, Depth: 4}},
+	} {
+		got, err := EstimateCost(tt.query, "", tt.variables)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: EstimateCost = %+v, %v; want %+v", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestEstimateCostOperations(t *testing.T) {
+	query := `query Small { species { code } } query Big { counties { lakes { surveys { surveyID } } } }`
+	if got, err := EstimateCost(query, "Big", nil); err != nil || got.Cost != 4591 {
+		t.Errorf("Big = %+v, %v; want cost 4591", got, err)
+	}
+	if got, err := EstimateCost(query, "", nil); err != nil || got.Cost != 1 {
+		t.Errorf("no operation name = %+v, %v; want the first operation", got, err)
+	}
+	if _, err := EstimateCost(query, "Missing", nil); err == nil {
+		t.Error("an unknown operation name was estimated")
+	}
+	if _, err := EstimateCost(`{ counties {`, "", nil); err == nil {
+		t.Error("a query that doesn't parse was estimated")
+	}
+}
+
+func TestExecuteRejectsExpensiveQueries(t *testing.T) {
+	fish := controller.NewFishSurveyController(&model.FishSurveyModel{})
+	executor, err := NewExecutor(fish, controller.NewCountyController(nil, fish.Model, fish.Reconciler), 1000, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		query string
+		want  string
+	}{
+		{`{ counties { lakes { surveys { lengths { speciesCode } } } } }`, "query depth 4 exceeds the limit of 3"},
+		{`{ lake(dow: 1) { surveys(limit: 999) { lengths { speciesCode } } } }`, "query cost 1001 exceeds the limit of 1000"},
+	} {
+		_, cost, err := executor.Execute(context.Background(), Request{Query: tt.query})
+		var costErr *CostError
+		if !errors.As(err, &costErr) {
+			t.Errorf("%s: error = %v, want a CostError", tt.query, err)
+			continue
+		}
+		if costErr.Cost != cost || costErr.Error() != tt.want {
+			t.Errorf("%s: %q for %+v, want %q", tt.query, costErr.Error(), cost, tt.want)
+		}
+	}
+
+	// Queries at the limits run.
+	result, cost, err := executor.Execute(context.Background(), Request{Query: `{ lake(dow: 1) { surveys(limit: 998) { lengths { speciesCode } } } }`})
+	if err != nil || result == nil || result.HasErrors() || cost != (Cost{Cost: 1000, Depth: 3}) {
+		t.Errorf("query at the limits = %+v, %+v, %v", result, cost, err)
+	}
+}
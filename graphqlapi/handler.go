@@ -0,0 +1,76 @@
+package graphqlapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/graphql-go/graphql"
+
+	"fishreports/controller"
+)
+
+// Request is a GraphQL request body.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Executor runs GraphQL requests against the schema within cost limits.
+type Executor struct {
+	schema         graphql.Schema
+	fishController *controller.FishSurveyController
+	maxCost        int
+	maxDepth       int
+}
+
+// NewExecutor builds the schema and an executor enforcing the given limits.
+// A zero limit disables that check.
+func NewExecutor(fishController *controller.FishSurveyController, countyController *controller.CountyController, maxCost, maxDepth int) (*Executor, error) {
+	schema, err := NewSchema(fishController, countyController)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build GraphQL schema: %w", err)
+	}
+	return &Executor{
+		schema:         schema,
+		fishController: fishController,
+		maxCost:        maxCost,
+		maxDepth:       maxDepth,
+	}, nil
+}
+
+// CostError reports a query rejected by the cost or depth limits.
+type CostError struct {
+	Cost     Cost
+	MaxCost  int
+	MaxDepth int
+}
+
+func (e *CostError) Error() string {
+	if e.MaxDepth > 0 && e.Cost.Depth > e.MaxDepth {
+		return fmt.Sprintf("query depth %d exceeds the limit of %d", e.Cost.Depth, e.MaxDepth)
+	}
+	return fmt.Sprintf("query cost %d exceeds the limit of %d", e.Cost.Cost, e.MaxCost)
+}
+
+// Execute checks the request against the cost limits and runs it with fresh
+// request-scoped loaders. Parse and limit failures return an error without
+// executing; resolver failures are reported in the result's errors.
+func (e *Executor) Execute(ctx context.Context, req Request) (*graphql.Result, Cost, error) {
+	cost, err := EstimateCost(req.Query, req.OperationName, req.Variables)
+	if err != nil {
+		return nil, cost, err
+	}
+	if (e.maxCost > 0 && cost.Cost > e.maxCost) || (e.maxDepth > 0 && cost.Depth > e.maxDepth) {
+		return nil, cost, &CostError{Cost: cost, MaxCost: e.maxCost, MaxDepth: e.maxDepth}
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         e.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        withLoaders(ctx, e.fishController),
+	})
+	return result, cost, nil
+}
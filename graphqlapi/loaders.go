@@ -0,0 +1,99 @@
+package graphqlapi
+
+import (
+	"context"
+	"sync"
+
+	"fishreports/controller"
+	"fishreports/model"
+)
+
+// loadersContextKey is the context key holding the request's *loaders.
+type loadersContextKey struct{}
+
+// loaders are request-scoped, dataloader-style batch loaders. The first lookup
+// of a kind loads every key in one pass and caches the result, so resolving
+// the lakes of all 87 counties or the surveys of hundreds of lakes costs one
+// scan of the model per request rather than one per parent object.
+type loaders struct {
+	fishController *controller.FishSurveyController
+
+	lakesOnce sync.Once
+	lakes     *controller.LakeIndex
+	lakesErr  error
+
+	speciesOnce sync.Once
+	speciesByID map[string]*model.Species
+}
+
+func newLoaders(fishController *controller.FishSurveyController) *loaders {
+	return &loaders{fishController: fishController}
+}
+
+// withLoaders attaches fresh loaders to a request context.
+func withLoaders(ctx context.Context, fishController *controller.FishSurveyController) context.Context {
+	return context.WithValue(ctx, loadersContextKey{}, newLoaders(fishController))
+}
+
+// loadersFrom returns the request's loaders, creating unshared ones if the
+// context has none.
+func loadersFrom(ctx context.Context, fishController *controller.FishSurveyController) *loaders {
+	if l, ok := ctx.Value(loadersContextKey{}).(*loaders); ok {
+		return l
+	}
+	return newLoaders(fishController)
+}
+
+// lakeIndex batch-loads every lake, keyed by DOW number and county ID.
+func (l *loaders) lakeIndex(ctx context.Context) (*controller.LakeIndex, error) {
+	l.lakesOnce.Do(func() {
+		l.lakes, l.lakesErr = l.fishController.BuildLakeIndexContext(ctx)
+	})
+	return l.lakes, l.lakesErr
+}
+
+// lakesByCounty loads the lakes for a county ID.
+func (l *loaders) lakesByCounty(ctx context.Context, countyID string) ([]*model.FishData, error) {
+	index, err := l.lakeIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return index.ByCountyID[countyID], nil
+}
+
+// lakeByDOW loads a lake by DOW number.
+func (l *loaders) lakeByDOW(ctx context.Context, dow int) (*model.FishData, error) {
+	index, err := l.lakeIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return index.ByDOW[dow], nil
+}
+
+// speciesByCode resolves a species from the species catalog.
+func (l *loaders) speciesByCode(code string) *model.Species {
+	species, exists := l.fishController.Model.Species()[code]
+	if !exists {
+		return nil
+	}
+	return &species
+}
+
+// speciesByIDs batch-loads species by ID.
+func (l *loaders) speciesByIDs(ids []string) []*model.Species {
+	l.speciesOnce.Do(func() {
+		speciesMap := l.fishController.Model.Species()
+		l.speciesByID = make(map[string]*model.Species, len(speciesMap))
+		for _, species := range speciesMap {
+			species := species
+			l.speciesByID[species.ID] = &species
+		}
+	})
+	var found []*model.Species
+	for _, id := range ids {
+		if species, exists := l.speciesByID[id]; exists {
+			found = append(found, species)
+		}
+	}
+	return found
+}
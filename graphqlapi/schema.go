@@ -0,0 +1,515 @@
+package graphqlapi
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+
+	"fishreports/controller"
+	"fishreports/model"
+)
+
+// surveyNode is the source value for the Survey type.
+type surveyNode struct {
+	lake   *model.FishData
+	survey model.Survey
+}
+
+// lengthNode is the source value for the LengthData type.
+type lengthNode struct {
+	code    string
+	data    *model.LengthData
+	species *model.Species
+}
+
+// resolver holds the controllers shared with the REST routes.
+type resolver struct {
+	fishController   *controller.FishSurveyController
+	countyController *controller.CountyController
+}
+
+// NewSchema builds the GraphQL schema linking County -> Lakes -> Surveys ->
+// LengthData -> Species on top of the REST controllers.
+func NewSchema(fishController *controller.FishSurveyController, countyController *controller.CountyController) (graphql.Schema, error) {
+	r := &resolver{fishController: fishController, countyController: countyController}
+
+	var countyType, lakeType, surveyType *graphql.Object
+
+	speciesType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Species",
+		Fields: graphql.Fields{
+			"id":             speciesField(graphql.NewNonNull(graphql.ID), func(s *model.Species) interface{} { return s.ID }),
+			"code":           speciesField(graphql.String, func(s *model.Species) interface{} { return s.Code }),
+			"commonName":     speciesField(graphql.String, func(s *model.Species) interface{} { return s.CommonName }),
+			"scientificName": speciesField(graphql.String, func(s *model.Species) interface{} { return s.ScientificName }),
+			"gameFish":       speciesField(graphql.Boolean, func(s *model.Species) interface{} { return s.GameFish }),
+			"status":         speciesField(graphql.NewList(graphql.String), func(s *model.Species) interface{} { return s.Status }),
+			"speciesGroup":   speciesField(graphql.String, func(s *model.Species) interface{} { return s.SpeciesGroup }),
+			"imageUrl":       speciesField(graphql.String, func(s *model.Species) interface{} { return s.ImageURL }),
+			"description":    speciesField(graphql.String, func(s *model.Species) interface{} { return s.Description }),
+		},
+	})
+
+	fishCountType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "FishCount",
+		Fields: graphql.Fields{
+			"length": &graphql.Field{Type: graphql.Int, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(model.FishCount).Length, nil
+			}},
+			"quantity": &graphql.Field{Type: graphql.Int, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(model.FishCount).Quantity, nil
+			}},
+		},
+	})
+
+	lengthDataType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "LengthData",
+		Fields: graphql.Fields{
+			"speciesCode": &graphql.Field{Type: graphql.String, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*lengthNode).code, nil
+			}},
+			"species": &graphql.Field{Type: speciesType, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return nilIfEmpty(p.Source.(*lengthNode).species), nil
+			}},
+			"minimumLength": &graphql.Field{Type: graphql.Int, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*lengthNode).data.MinimumLength, nil
+			}},
+			"maximumLength": &graphql.Field{Type: graphql.Int, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*lengthNode).data.MaximumLength, nil
+			}},
+			"fishCount": &graphql.Field{Type: graphql.NewList(fishCountType), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*lengthNode).data.FishCount, nil
+			}},
+		},
+	})
+
+	catchSummaryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "FishCatchSummary",
+		Fields: graphql.Fields{
+			"speciesCode": &graphql.Field{Type: graphql.String, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return *p.Source.(model.FishCatchSummary).Species, nil
+			}},
+			"totalCatch": &graphql.Field{Type: graphql.Int, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return *p.Source.(model.FishCatchSummary).TotalCatch, nil
+			}},
+		},
+	})
+
+	surveyType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Survey",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"surveyID":      surveyField(graphql.NewNonNull(graphql.ID), func(s *surveyNode) interface{} { return s.survey.SurveyID }),
+				"surveyDate":    surveyField(graphql.String, func(s *surveyNode) interface{} { return s.survey.SurveyDate }),
+				"surveyType":    surveyField(graphql.String, func(s *surveyNode) interface{} { return s.survey.SurveyType }),
+				"surveySubType": surveyField(graphql.String, func(s *surveyNode) interface{} { return s.survey.SurveySubType }),
+				"narrative":     surveyField(graphql.String, func(s *surveyNode) interface{} { return s.survey.Narrative }),
+				"fishCatchSummaries": &graphql.Field{
+					Type: graphql.NewList(catchSummaryType),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						var summaries []model.FishCatchSummary
+						for _, summary := range p.Source.(*surveyNode).survey.FishCatchSummaries {
+							if summary.Species != nil && summary.TotalCatch != nil {
+								summaries = append(summaries, summary)
+							}
+						}
+						return summaries, nil
+					},
+				},
+				"lengths": &graphql.Field{
+					Type:        graphql.NewList(lengthDataType),
+					Description: "Length data per species, optionally filtered like /surveys.",
+					Args:        speciesFilterArgs(),
+					Resolve:     r.resolveLengths,
+				},
+				"lake": &graphql.Field{
+					Type: lakeType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source.(*surveyNode).lake, nil
+					},
+				},
+			}
+		}),
+	})
+
+	lakeType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Lake",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"dowNumber":  lakeField(graphql.NewNonNull(graphql.Int), func(l *model.FishData) interface{} { return l.Result.DOWNumber }),
+				"name":       lakeField(graphql.String, func(l *model.FishData) interface{} { return l.Result.LakeName }),
+				"countyName": lakeField(graphql.String, func(l *model.FishData) interface{} { return l.Result.CountyName }),
+				"state":      lakeField(graphql.String, func(l *model.FishData) interface{} { return model.LakeState(*l) }),
+				"county": &graphql.Field{
+					Type: countyType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						id := r.fishController.Reconciler.LakeCountyID(*p.Source.(*model.FishData))
+						return nilIfEmpty(r.countyController.GetCountyByID(id)), nil
+					},
+				},
+				"surveys": &graphql.Field{
+					Type:        graphql.NewList(surveyType),
+					Description: "Surveys of the lake, newest first, filtered like /surveys.",
+					Args:        surveyFilterArgs(),
+					Resolve:     r.resolveLakeSurveys,
+				},
+			}
+		}),
+	})
+
+	countyType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "County",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":          countyField(graphql.NewNonNull(graphql.ID), func(c *model.County) interface{} { return c.ID }),
+				"countyName":  countyField(graphql.String, func(c *model.County) interface{} { return c.CountyName }),
+				"state":       countyField(graphql.String, func(c *model.County) interface{} { return model.CountyState(*c) }),
+				"fipsCode":    countyField(graphql.String, func(c *model.County) interface{} { return c.FIPSCode }),
+				"countySeat":  countyField(graphql.String, func(c *model.County) interface{} { return c.CountySeat }),
+				"established": countyField(graphql.Int, func(c *model.County) interface{} { return c.Established }),
+				"population":  countyField(graphql.Int, func(c *model.County) interface{} { return c.Population }),
+				"areaSqMiles": countyField(graphql.Float, func(c *model.County) interface{} { return c.AreaSqMiles }),
+				"mapImageUrl": countyField(graphql.String, func(c *model.County) interface{} { return c.MapImageURL }),
+				"lakes": &graphql.Field{
+					Type: graphql.NewList(lakeType),
+					Args: graphql.FieldConfigArgument{
+						"lake": &graphql.ArgumentConfig{Type: graphql.NewList(graphql.String), Description: "Lake names to include."},
+					},
+					Resolve: r.resolveCountyLakes,
+				},
+			}
+		}),
+	})
+
+	surveyRowType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "SurveyRow",
+		Description: "One row of GET /surveys: a survey and one species.",
+		Fields: graphql.Fields{
+			"surveyID":      rowField(graphql.ID, "surveyID"),
+			"dowNumber":     rowField(graphql.Int, "dow_number"),
+			"state":         rowField(graphql.String, "state"),
+			"surveyType":    rowField(graphql.String, "survey_type"),
+			"surveySubType": rowField(graphql.String, "survey_sub_type"),
+			"countyName":    rowField(graphql.String, "county_name"),
+			"lakeName":      rowField(graphql.String, "lake_name"),
+			"surveyDate":    rowField(graphql.String, "survey_date"),
+			"speciesName":   rowField(graphql.String, "species_name"),
+			"imageUrl":      rowField(graphql.String, "image_url"),
+			"narrative":     rowField(graphql.String, "narrative"),
+			"minLength":     rowField(graphql.Int, "min_length"),
+			"maxLength":     rowField(graphql.Int, "max_length"),
+			"totalCatch":    rowField(graphql.Int, "total_catch"),
+		},
+	})
+
+	surveyPageType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "SurveyPage",
+		Fields: graphql.Fields{
+			"data":     rowField(graphql.NewList(surveyRowType), "data"),
+			"total":    rowField(graphql.Int, "total"),
+			"limit":    rowField(graphql.Int, "limit"),
+			"page":     rowField(graphql.Int, "page"),
+			"prevPage": rowField(graphql.Int, "prev_page"),
+			"nextPage": rowField(graphql.Int, "next_page"),
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"counties": &graphql.Field{
+				Type: graphql.NewList(countyType),
+				Args: graphql.FieldConfigArgument{
+					"ids":   &graphql.ArgumentConfig{Type: graphql.NewList(graphql.ID)},
+					"state": &graphql.ArgumentConfig{Type: graphql.String, Description: "State code, e.g. MN."},
+				},
+				Resolve: r.resolveCounties,
+			},
+			"county": &graphql.Field{
+				Type: countyType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return nilIfEmpty(r.countyController.GetCountyByID(p.Args["id"].(string))), nil
+				},
+			},
+			"lake": &graphql.Field{
+				Type: lakeType,
+				Args: graphql.FieldConfigArgument{
+					"dow": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					lake, err := loadersFrom(p.Context, r.fishController).lakeByDOW(p.Context, p.Args["dow"].(int))
+					return nilIfEmpty(lake), err
+				},
+			},
+			"species": &graphql.Field{
+				Type: graphql.NewList(speciesType),
+				Args: graphql.FieldConfigArgument{
+					"ids":   &graphql.ArgumentConfig{Type: graphql.NewList(graphql.ID)},
+					"state": &graphql.ArgumentConfig{Type: graphql.String, Description: "Only species surveyed in this state."},
+				},
+				Resolve: r.resolveSpecies,
+			},
+			"surveys": &graphql.Field{
+				Type:        surveyPageType,
+				Description: "The same filtered, sorted and paginated rows as GET /surveys.",
+				Args:        surveyPageArgs(),
+				Resolve:     r.resolveSurveyPage,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+func speciesField(t graphql.Output, get func(*model.Species) interface{}) *graphql.Field {
+	return &graphql.Field{Type: t, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(*model.Species)), nil
+	}}
+}
+
+func surveyField(t graphql.Output, get func(*surveyNode) interface{}) *graphql.Field {
+	return &graphql.Field{Type: t, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(*surveyNode)), nil
+	}}
+}
+
+func lakeField(t graphql.Output, get func(*model.FishData) interface{}) *graphql.Field {
+	return &graphql.Field{Type: t, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(*model.FishData)), nil
+	}}
+}
+
+func countyField(t graphql.Output, get func(*model.County) interface{}) *graphql.Field {
+	return &graphql.Field{Type: t, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(*model.County)), nil
+	}}
+}
+
+// rowField reads a key from the loosely typed maps returned by FilterAndSortData.
+func rowField(t graphql.Output, key string) *graphql.Field {
+	return &graphql.Field{Type: t, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		row, _ := p.Source.(map[string]interface{})
+		return row[key], nil
+	}}
+}
+
+// nilIfEmpty turns typed nil pointers into untyped nils so GraphQL renders null.
+func nilIfEmpty[T any](value *T) interface{} {
+	if value == nil {
+		return nil
+	}
+	return value
+}
+
+// speciesFilterArgs are the /surveys species filters that apply to length data.
+func speciesFilterArgs() graphql.FieldConfigArgument {
+	return graphql.FieldConfigArgument{
+		"species":  &graphql.ArgumentConfig{Type: graphql.NewList(graphql.ID), Description: "Species IDs."},
+		"gameFish": &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false},
+	}
+}
+
+// surveyFilterArgs are the /surveys filters that apply to a lake's surveys.
+func surveyFilterArgs() graphql.FieldConfigArgument {
+	args := speciesFilterArgs()
+	args["minYear"] = &graphql.ArgumentConfig{Type: graphql.String}
+	args["maxYear"] = &graphql.ArgumentConfig{Type: graphql.String}
+	args["limit"] = &graphql.ArgumentConfig{Type: graphql.Int, Description: "Most recent surveys to return."}
+	return args
+}
+
+// surveyPageArgs mirror the GET /surveys query parameters.
+func surveyPageArgs() graphql.FieldConfigArgument {
+	args := speciesFilterArgs()
+	args["minYear"] = &graphql.ArgumentConfig{Type: graphql.String}
+	args["maxYear"] = &graphql.ArgumentConfig{Type: graphql.String}
+	args["counties"] = &graphql.ArgumentConfig{Type: graphql.NewList(graphql.ID), Description: "County IDs."}
+	args["lake"] = &graphql.ArgumentConfig{Type: graphql.NewList(graphql.String), Description: "Lake names."}
+	args["sortBy"] = &graphql.ArgumentConfig{Type: graphql.String}
+	args["order"] = &graphql.ArgumentConfig{Type: graphql.String}
+	args["search"] = &graphql.ArgumentConfig{Type: graphql.String}
+	args["invasive"] = &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false, Description: "Only species listed as invasive."}
+	args["state"] = &graphql.ArgumentConfig{Type: graphql.String, Description: "State code, e.g. MN."}
+	args["limit"] = &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 50}
+	args["page"] = &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 1}
+	return args
+}
+
+// stringsArg reads a list argument as strings.
+func stringsArg(args map[string]interface{}, name string) []string {
+	values, _ := args[name].([]interface{})
+	var out []string
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func stringArg(args map[string]interface{}, name string) string {
+	value, _ := args[name].(string)
+	return value
+}
+
+func intArg(args map[string]interface{}, name string) int {
+	value, _ := args[name].(int)
+	return value
+}
+
+func (r *resolver) resolveCounties(p graphql.ResolveParams) (interface{}, error) {
+	ids := stringsArg(p.Args, "ids")
+	state := stringArg(p.Args, "state")
+	inState := func(county *model.County) bool {
+		return state == "" || model.CountyState(*county) == model.NormalizeState(state)
+	}
+	var counties []*model.County
+	if len(ids) == 0 {
+		all := r.countyController.GetCounties()
+		for i := range all {
+			if inState(&all[i]) {
+				counties = append(counties, &all[i])
+			}
+		}
+		return counties, nil
+	}
+	for _, id := range ids {
+		if county := r.countyController.GetCountyByID(id); county != nil && inState(county) {
+			counties = append(counties, county)
+		}
+	}
+	return counties, nil
+}
+
+func (r *resolver) resolveCountyLakes(p graphql.ResolveParams) (interface{}, error) {
+	county := p.Source.(*model.County)
+	lakes, err := loadersFrom(p.Context, r.fishController).lakesByCounty(p.Context, county.ID)
+	if err != nil {
+		return nil, err
+	}
+	names := stringsArg(p.Args, "lake")
+	if len(names) == 0 {
+		return lakes, nil
+	}
+	var filtered []*model.FishData
+	for _, lake := range lakes {
+		for _, name := range names {
+			if strings.EqualFold(lake.Result.LakeName, name) {
+				filtered = append(filtered, lake)
+				break
+			}
+		}
+	}
+	return filtered, nil
+}
+
+func (r *resolver) resolveLakeSurveys(p graphql.ResolveParams) (interface{}, error) {
+	lake := p.Source.(*model.FishData)
+	minYear, _ := strconv.Atoi(stringArg(p.Args, "minYear"))
+	maxYear, _ := strconv.Atoi(stringArg(p.Args, "maxYear"))
+	speciesIDs := stringsArg(p.Args, "species")
+	gameFish, _ := p.Args["gameFish"].(bool)
+
+	var surveys []*surveyNode
+	for _, survey := range lake.Result.Surveys {
+		year := 0
+		if len(survey.SurveyDate) >= 4 {
+			year, _ = strconv.Atoi(survey.SurveyDate[:4])
+		}
+		if (minYear > 0 && year < minYear) || (maxYear > 0 && year > maxYear) {
+			continue
+		}
+		node := &surveyNode{lake: lake, survey: survey}
+		if (len(speciesIDs) > 0 || gameFish) && len(r.matchingLengths(p.Context, node, speciesIDs, gameFish)) == 0 {
+			continue
+		}
+		surveys = append(surveys, node)
+	}
+	sort.Slice(surveys, func(i, j int) bool {
+		return surveys[i].survey.SurveyDate > surveys[j].survey.SurveyDate
+	})
+	if limit := intArg(p.Args, "limit"); limit > 0 && len(surveys) > limit {
+		surveys = surveys[:limit]
+	}
+	return surveys, nil
+}
+
+func (r *resolver) resolveLengths(p graphql.ResolveParams) (interface{}, error) {
+	gameFish, _ := p.Args["gameFish"].(bool)
+	return r.matchingLengths(p.Context, p.Source.(*surveyNode), stringsArg(p.Args, "species"), gameFish), nil
+}
+
+// matchingLengths applies the /surveys species and game fish filters to a
+// survey's length data, ordered by species code.
+func (r *resolver) matchingLengths(ctx context.Context, node *surveyNode, speciesIDs []string, gameFish bool) []*lengthNode {
+	l := loadersFrom(ctx, r.fishController)
+	var lengths []*lengthNode
+	for code, data := range node.survey.Lengths {
+		if data == nil {
+			continue
+		}
+		species := data.Species
+		if species == nil {
+			species = l.speciesByCode(code)
+		}
+		if species == nil {
+			continue
+		}
+		if gameFish && !species.GameFish {
+			continue
+		}
+		if len(speciesIDs) > 0 {
+			matched := false
+			for _, id := range speciesIDs {
+				if strings.EqualFold(id, species.ID) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				continue
+			}
+		}
+		lengths = append(lengths, &lengthNode{code: code, data: data, species: species})
+	}
+	sort.Slice(lengths, func(i, j int) bool {
+		return lengths[i].code < lengths[j].code
+	})
+	return lengths
+}
+
+func (r *resolver) resolveSpecies(p graphql.ResolveParams) (interface{}, error) {
+	l := loadersFrom(p.Context, r.fishController)
+	ids := stringsArg(p.Args, "ids")
+	if len(ids) > 0 {
+		return l.speciesByIDs(ids), nil
+	}
+	// Same list as GET /species: species with survey data, by common name.
+	var species []*model.Species
+	for _, entry := range r.fishController.ForState(stringArg(p.Args, "state")).GetAllSpecies() {
+		species = append(species, l.speciesByIDs([]string{entry["id"]})...)
+	}
+	return species, nil
+}
+
+func (r *resolver) resolveSurveyPage(p graphql.ResolveParams) (interface{}, error) {
+	gameFish, _ := p.Args["gameFish"].(bool)
+	invasive, _ := p.Args["invasive"].(bool)
+	return r.fishController.ForState(stringArg(p.Args, "state")).FilterAndSortDataContext(
+		p.Context,
+		stringsArg(p.Args, "species"),
+		stringArg(p.Args, "minYear"), stringArg(p.Args, "maxYear"),
+		stringsArg(p.Args, "counties"),
+		stringsArg(p.Args, "lake"),
+		stringArg(p.Args, "sortBy"), stringArg(p.Args, "order"),
+		gameFish, invasive,
+		stringArg(p.Args, "search"),
+		intArg(p.Args, "limit"), intArg(p.Args, "page"),
+	)
+}
@@ -0,0 +1,196 @@
+package grpcapi
+
+import (
+	"fishreports/controller"
+	"fishreports/model"
+	"fishreports/pb"
+)
+
+// The controllers return loosely typed maps for the JSON routes; these helpers
+// read them defensively so a missing or mistyped field becomes a zero value.
+
+func stringField(m map[string]interface{}, key string) string {
+	value, _ := m[key].(string)
+	return value
+}
+
+func int32Field(m map[string]interface{}, key string) int32 {
+	value, _ := m[key].(int)
+	return int32(value)
+}
+
+func float64Field(m map[string]interface{}, key string) float64 {
+	value, _ := m[key].(float64)
+	return value
+}
+
+// surveyRowToProto converts one GET /surveys row.
+func surveyRowToProto(row map[string]interface{}) *pb.SurveyRow {
+	return &pb.SurveyRow{
+		SurveyId:      stringField(row, "surveyID"),
+		DowNumber:     int32Field(row, "dow_number"),
+		SurveyType:    stringField(row, "survey_type"),
+		SurveySubType: stringField(row, "survey_sub_type"),
+		CountyName:    stringField(row, "county_name"),
+		LakeName:      stringField(row, "lake_name"),
+		SurveyDate:    stringField(row, "survey_date"),
+		SpeciesName:   stringField(row, "species_name"),
+		ImageUrl:      stringField(row, "image_url"),
+		Narrative:     stringField(row, "narrative"),
+		MinLength:     int32Field(row, "min_length"),
+		MaxLength:     int32Field(row, "max_length"),
+		TotalCatch:    int32Field(row, "total_catch"),
+	}
+}
+
+// fishCountsToProto converts the histogram bins used by the graph responses.
+func fishCountsToProto(bins []controller.HistogramBin) []*pb.FishCount {
+	counts := make([]*pb.FishCount, 0, len(bins))
+	for _, bin := range bins {
+		counts = append(counts, &pb.FishCount{Length: int32(bin.Length), Quantity: int32(bin.Quantity)})
+	}
+	return counts
+}
+
+// speciesStatsToProto converts the GetSpeciesStats response.
+func speciesStatsToProto(stats map[string]interface{}) *pb.SpeciesStats {
+	graphData, _ := stats["graph_data"].([]controller.HistogramBin)
+	counties, _ := stats["counties"].([]map[string]interface{})
+
+	out := &pb.SpeciesStats{
+		Species:        stringField(stats, "species"),
+		PercentLakes:   int32Field(stats, "percent_lakes"),
+		AverageLength:  float64Field(stats, "average_length"),
+		BiggestLength:  int32Field(stats, "biggest_length"),
+		ShortestLength: int32Field(stats, "shortest_length"),
+		GraphData:      fishCountsToProto(graphData),
+		TotalFish:      int32Field(stats, "total_fish"),
+	}
+	for _, county := range counties {
+		out.Counties = append(out.Counties, &pb.CountyPrevalence{
+			CountyId:   stringField(county, "id"),
+			Percentage: int32Field(county, "percentage"),
+		})
+	}
+	return out
+}
+
+// countyStatsToProto converts the GetCountyStats response.
+func countyStatsToProto(stats map[string]interface{}) *pb.CountyStats {
+	surveyIDs, _ := stats["survey_ids"].([]string)
+	distribution, _ := stats["species_distribution"].(map[string]float64)
+
+	out := &pb.CountyStats{
+		NumberOfLakes:        int32Field(stats, "number_of_lakes"),
+		SurveyIds:            surveyIDs,
+		TotalSurveys:         int32Field(stats, "total_surveys"),
+		TotalFishCaught:      int32Field(stats, "total_fish_caught"),
+		NumberOfSpecies:      int32Field(stats, "number_of_species"),
+		SpeciesDistribution:  distribution,
+		AverageFishPerSurvey: float64Field(stats, "average_fish_per_survey"),
+	}
+	if county, ok := stats["county"].(*model.County); ok {
+		out.County = countyToProto(county)
+	}
+	return out
+}
+
+// lengthHistogramToProto converts the GetFishCountData response.
+func lengthHistogramToProto(graphData map[string]interface{}) *pb.LengthHistogram {
+	rows, _ := graphData["data"].([]controller.HistogramBin)
+	return &pb.LengthHistogram{
+		Species:    stringField(graphData, "species"),
+		SurveyDate: stringField(graphData, "surveyDate"),
+		Data:       fishCountsToProto(rows),
+	}
+}
+
+func countyToProto(county *model.County) *pb.County {
+	return &pb.County{
+		Id:          county.ID,
+		CountyName:  county.CountyName,
+		FipsCode:    county.FIPSCode,
+		CountySeat:  county.CountySeat,
+		Established: int32(county.Established),
+		Origin:      county.Origin,
+		Etymology:   county.Etymology,
+		Population:  int32(county.Population),
+		AreaSqMiles: county.AreaSqMiles,
+		MapImageUrl: county.MapImageURL,
+		Lakes:       county.Lakes,
+	}
+}
+
+func speciesToProto(species *model.Species) *pb.Species {
+	if species == nil {
+		return nil
+	}
+	return &pb.Species{
+		Id:             species.ID,
+		Code:           species.Code,
+		CommonName:     species.CommonName,
+		ScientificName: species.ScientificName,
+		GameFish:       species.GameFish,
+		SpeciesGroup:   species.SpeciesGroup,
+		ImageUrl:       species.ImageURL,
+		Description:    species.Description,
+	}
+}
+
+// surveyToProto converts a survey, resolving species from speciesMap when the
+// length data doesn't carry them.
+func surveyToProto(survey model.Survey, speciesMap map[string]model.Species) *pb.Survey {
+	out := &pb.Survey{
+		SurveyId:      survey.SurveyID,
+		SurveyDate:    survey.SurveyDate,
+		SurveyType:    survey.SurveyType,
+		SurveySubType: survey.SurveySubType,
+		Narrative:     survey.Narrative,
+		Lengths:       make(map[string]*pb.LengthData, len(survey.Lengths)),
+	}
+	for _, summary := range survey.FishCatchSummaries {
+		if summary.Species == nil || summary.TotalCatch == nil {
+			continue
+		}
+		out.FishCatchSummaries = append(out.FishCatchSummaries, &pb.FishCatchSummary{
+			Species:    *summary.Species,
+			TotalCatch: int32(*summary.TotalCatch),
+		})
+	}
+	for code, lengthData := range survey.Lengths {
+		if lengthData == nil {
+			continue
+		}
+		species := lengthData.Species
+		if species == nil {
+			if speciesObj, exists := speciesMap[code]; exists {
+				species = &speciesObj
+			}
+		}
+		lengths := &pb.LengthData{
+			Species:       speciesToProto(species),
+			MinimumLength: int32(lengthData.MinimumLength),
+			MaximumLength: int32(lengthData.MaximumLength),
+		}
+		for _, count := range lengthData.FishCount {
+			lengths.FishCount = append(lengths.FishCount, &pb.FishCount{Length: int32(count.Length), Quantity: int32(count.Quantity)})
+		}
+		out.Lengths[code] = lengths
+	}
+	return out
+}
+
+// lakeToProto converts a lake and all of its surveys, with the county the
+// reconciler matches it to.
+func lakeToProto(lake *model.FishData, speciesMap map[string]model.Species, reconciler *controller.CountyReconciler) *pb.Lake {
+	out := &pb.Lake{
+		DowNumber:  int32(lake.Result.DOWNumber),
+		LakeName:   lake.Result.LakeName,
+		CountyName: lake.Result.CountyName,
+		CountyId:   reconciler.LakeCountyID(*lake),
+	}
+	for _, survey := range lake.Result.Surveys {
+		out.Surveys = append(out.Surveys, surveyToProto(survey, speciesMap))
+	}
+	return out
+}
@@ -0,0 +1,144 @@
+package grpcapi
+
+import (
+	"context"
+	"log"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"fishreports/controller"
+	"fishreports/model"
+)
+
+// apiKeyContextKey is the context key holding the authenticated *model.APIKey.
+type apiKeyContextKey struct{}
+
+// authorize applies the same API key and rate limit rules as the REST
+// middleware and returns the caller's key. The key is read from the
+// "x-api-key" or "authorization: Bearer" metadata. A nil keyController
+// disables authentication and returns no key.
+func authorize(ctx context.Context, keyController *controller.APIKeyController) (*model.APIKey, error) {
+	if keyController == nil {
+		return nil, nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	secret := ""
+	if values := md.Get("x-api-key"); len(values) > 0 {
+		secret = values[0]
+	} else if values := md.Get("authorization"); len(values) > 0 {
+		secret = strings.TrimPrefix(values[0], "Bearer ")
+	}
+	if secret == "" {
+		return nil, status.Error(codes.Unauthenticated, "Missing API key")
+	}
+
+	key, ok := keyController.Authenticate(secret)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "Invalid API key")
+	}
+	if !key.HasScope(model.ScopePublicRead) {
+		return nil, status.Error(codes.PermissionDenied, "API key lacks the '"+model.ScopePublicRead+"' scope")
+	}
+	if limit := keyController.Allow(key); !limit.Allowed {
+		return nil, status.Error(codes.ResourceExhausted, "Rate limit exceeded")
+	}
+	return key, nil
+}
+
+// withAPIKey stores the caller's key, if any, in ctx for hasScope.
+func withAPIKey(ctx context.Context, key *model.APIKey) context.Context {
+	if key == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, apiKeyContextKey{}, key)
+}
+
+// hasScope reports whether the call's API key grants scope. Calls that went
+// through disabled authentication have no key and are granted every scope.
+func hasScope(ctx context.Context, scope string) bool {
+	key, ok := ctx.Value(apiKeyContextKey{}).(*model.APIKey)
+	return !ok || key.HasScope(scope)
+}
+
+// contextStream is a server stream whose handlers see ctx instead of the
+// stream's own context.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+func authUnaryInterceptor(keyController *controller.APIKeyController) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		key, err := authorize(ctx, keyController)
+		if err != nil {
+			return nil, err
+		}
+		return handler(withAPIKey(ctx, key), req)
+	}
+}
+
+func authStreamInterceptor(keyController *controller.APIKeyController) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		key, err := authorize(ss.Context(), keyController)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: withAPIKey(ss.Context(), key)})
+	}
+}
+
+// timeoutUnaryInterceptor applies the default request deadline to calls
+// whose client didn't set a shorter one.
+func timeoutUnaryInterceptor(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if timeout <= 0 {
+			return handler(ctx, req)
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+// timeoutStreamInterceptor applies the same deadline to streaming calls, so a
+// stream can't outlive the request timeout either.
+func timeoutStreamInterceptor(timeout time.Duration) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if timeout <= 0 {
+			return handler(srv, ss)
+		}
+		ctx, cancel := context.WithTimeout(ss.Context(), timeout)
+		defer cancel()
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func recoveryUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("❌ panic recovered [grpc method=%s]: %v\n%s", info.FullMethod, recovered, debug.Stack())
+			err = status.Error(codes.Internal, "Internal server error")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+func recoveryStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("❌ panic recovered [grpc method=%s]: %v\n%s", info.FullMethod, recovered, debug.Stack())
+			err = status.Error(codes.Internal, "Internal server error")
+		}
+	}()
+	return handler(srv, ss)
+}
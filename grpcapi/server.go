@@ -0,0 +1,218 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/tls"
+	"log"
+	"net"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+
+	"fishreports/config"
+	"fishreports/controller"
+	"fishreports/model"
+	"fishreports/pb"
+	"fishreports/server"
+)
+
+// Server implements the FishReports gRPC service on top of the same
+// controllers used by the REST routes.
+type Server struct {
+	pb.UnimplementedFishReportsServer
+	fishController   *controller.FishSurveyController
+	countyController *controller.CountyController
+}
+
+// NewServer creates the gRPC service implementation.
+func NewServer(fishController *controller.FishSurveyController, countyController *controller.CountyController) *Server {
+	return &Server{
+		fishController:   fishController,
+		countyController: countyController,
+	}
+}
+
+// Run serves the gRPC API on its own port until the listener fails. It reuses
+// the REST TLS certificate files when TLS is enabled and applies API key
+// authentication, rate limits, request deadlines and panic recovery.
+func Run(cfg *config.Config, srv *Server, keyController *controller.APIKeyController) error {
+	timeout := time.Duration(cfg.Limits.Timeouts.DefaultSeconds) * time.Second
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			recoveryUnaryInterceptor,
+			authUnaryInterceptor(keyController),
+			timeoutUnaryInterceptor(timeout),
+		),
+		grpc.ChainStreamInterceptor(
+			recoveryStreamInterceptor,
+			authStreamInterceptor(keyController),
+			timeoutStreamInterceptor(timeout),
+		),
+	}
+
+	if cfg.TLS.Enabled && !cfg.TLS.Autocert.Enabled {
+		reloader, err := server.NewCertReloader(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		if err != nil {
+			return err
+		}
+		if interval := time.Duration(cfg.TLS.ReloadIntervalSeconds) * time.Second; interval > 0 {
+			go reloader.Watch(interval)
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(&tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: reloader.GetCertificate,
+		})))
+	}
+
+	listener, err := net.Listen("tcp", ":"+cfg.GRPC.Port)
+	if err != nil {
+		return err
+	}
+	grpcServer := grpc.NewServer(opts...)
+	pb.RegisterFishReportsServer(grpcServer, srv)
+
+	log.Printf("gRPC server running on port %s...", cfg.GRPC.Port)
+	return grpcServer.Serve(listener)
+}
+
+// maxPublicPageSize caps ListSurveys pages like the limit on GET /surveys.
+const maxPublicPageSize = 500
+
+// ListSurveys streams the rows matching the same filters as GET /surveys.
+// Without a page size it streams every matching row, which like larger pages
+// is reserved for keys with the export scope; other keys get one capped page.
+func (s *Server) ListSurveys(req *pb.ListSurveysRequest, stream grpc.ServerStreamingServer[pb.SurveyRow]) error {
+	limit := int(req.GetPageSize())
+	page := int(req.GetPage())
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = int(^uint(0) >> 1)
+		page = 1
+	}
+	if limit > maxPublicPageSize && !hasScope(stream.Context(), model.ScopeExport) {
+		limit = maxPublicPageSize
+	}
+
+	fishController, err := s.forState(req.GetState())
+	if err != nil {
+		return err
+	}
+	result, err := fishController.FilterAndSortDataContext(
+		stream.Context(),
+		req.GetSpeciesIds(), req.GetMinYear(), req.GetMaxYear(), req.GetCountyIds(), req.GetLakes(),
+		req.GetSortBy(), req.GetOrder(), req.GetGameFishOnly(), false, req.GetSearch(), limit, page,
+	)
+	if err != nil {
+		return toStatus(err)
+	}
+
+	rows, _ := result["data"].([]map[string]interface{})
+	for _, row := range rows {
+		if err := stream.Context().Err(); err != nil {
+			return toStatus(err)
+		}
+		if err := stream.Send(surveyRowToProto(row)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSpeciesStats returns the statewide stats for one species.
+func (s *Server) GetSpeciesStats(ctx context.Context, req *pb.GetSpeciesStatsRequest) (*pb.SpeciesStats, error) {
+	fishController, err := s.forState(req.GetState())
+	if err != nil {
+		return nil, err
+	}
+	stats, err := fishController.GetSpeciesStatsByIDContext(ctx, req.GetSpeciesId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if stats == nil {
+		return nil, status.Error(codes.NotFound, "Species not found or no data available")
+	}
+	return speciesStatsToProto(stats), nil
+}
+
+// GetCountyStats returns the survey stats for one county.
+func (s *Server) GetCountyStats(ctx context.Context, req *pb.GetCountyStatsRequest) (*pb.CountyStats, error) {
+	state, err := s.normalizeState(req.GetState())
+	if err != nil {
+		return nil, err
+	}
+	county := s.countyController.GetCountyByID(req.GetCountyId())
+	if county == nil || (state != "" && model.CountyState(*county) != state) {
+		return nil, status.Error(codes.NotFound, "County not found")
+	}
+	stats, err := s.countyController.GetCountyStatsContext(ctx, county)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return countyStatsToProto(stats), nil
+}
+
+// GetLengthHistogram returns the length frequency for one species in one survey.
+func (s *Server) GetLengthHistogram(ctx context.Context, req *pb.GetLengthHistogramRequest) (*pb.LengthHistogram, error) {
+	if req.GetDowNumber() == 0 || req.GetSpecies() == "" || req.GetSurveyDate() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Missing request fields: dow_number, species, or survey_date")
+	}
+	fishController, err := s.forState(req.GetState())
+	if err != nil {
+		return nil, err
+	}
+	graphData, err := fishController.GetFishCountDataContext(ctx, strconv.Itoa(int(req.GetDowNumber())), req.GetSpecies(), req.GetSurveyDate())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if graphData == nil {
+		return nil, status.Error(codes.NotFound, "No data found for the specified parameters")
+	}
+	return lengthHistogramToProto(graphData), nil
+}
+
+// GetLake returns a lake with all of its surveys.
+func (s *Server) GetLake(ctx context.Context, req *pb.GetLakeRequest) (*pb.Lake, error) {
+	lake, err := s.fishController.GetLakeContext(ctx, int(req.GetDowNumber()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if lake == nil {
+		return nil, status.Error(codes.NotFound, "Lake not found")
+	}
+	return lakeToProto(lake, s.fishController.Model.Species(), s.fishController.Reconciler), nil
+}
+
+// normalizeState checks a request's state filter like ?state= on the REST
+// API: empty stays empty and an unknown state is an invalid argument.
+func (s *Server) normalizeState(state string) (string, error) {
+	if state == "" {
+		return "", nil
+	}
+	if _, exists := s.fishController.FindState(state); !exists {
+		return "", status.Error(codes.InvalidArgument, "Unknown state: "+state)
+	}
+	return model.NormalizeState(state), nil
+}
+
+// forState returns the fish controller narrowed to a request's state filter.
+func (s *Server) forState(state string) (*controller.FishSurveyController, error) {
+	state, err := s.normalizeState(state)
+	if err != nil {
+		return nil, err
+	}
+	return s.fishController.ForState(state), nil
+}
+
+// toStatus maps controller errors to gRPC status errors.
+func toStatus(err error) error {
+	if st, ok := status.FromError(err); ok {
+		return st.Err()
+	}
+	return status.FromContextError(err).Err()
+}
@@ -1,54 +1,366 @@
 package main
 
 import (
+	"context"
+	"fishreports/config"
 	"fishreports/model"
 	"fishreports/controller"
+	"fishreports/graphqlapi"
+	"fishreports/grpcapi"
+	"fishreports/server"
 	"fishreports/view"
+	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	os.Exit(runCLI(os.Args[1:]))
+}
+
+// dataset is the loaded survey data with the controllers serving it, shared
+// by the server and the offline CLI commands.
+type dataset struct {
+	Model    *model.FishSurveyModel
+	Fish     *controller.FishSurveyController
+	Counties *controller.CountyController
+}
+
+// loadDataset loads the counties, species and survey data named by the config
+// and precomputes the aggregates.
+func loadDataset(cfg *config.Config) (*dataset, error) {
 	// Initialize the model.
 	m := &model.FishSurveyModel{}
+	fish := controller.NewFishSurveyController(m)
 
-	// Declare a local variable for counties.
-	var counties []model.County
+	counties, err := configureCounties(cfg, fish)
+	if err != nil {
+		return nil, err
+	}
 
-	counties, err := controller.LoadCounties("data/minnesota_counties.json")
+	// Configure the data quality rules.
+	if err := configureValidation(cfg, fish.Validation); err != nil {
+		return nil, err
+	}
+	if err := configureRankings(cfg, fish); err != nil {
+		return nil, err
+	}
+	fish.AgeLengthKeys, err = controller.LoadAgeLengthKeys(cfg.Data.AgeLengthKeysFile)
 	if err != nil {
-		log.Fatalf("Error loading counties: %v", err)
+		return nil, fmt.Errorf("loading age-length keys: %w", err)
 	}
-	controller.Counties = counties 
+	fish.SpeciesStatuses, err = controller.LoadSpeciesStatuses(cfg.Data.SpeciesStatusFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading species statuses: %w", err)
+	}
+
+	if snapshot := loadSnapshot(cfg, fish.States); snapshot != nil {
+		// The snapshot holds the parsed species and surveys, and the counties
+		// with the IDs the surveys were indexed with.
+		snapshot.Restore(m)
+		counties = snapshot.Counties
+		controller.Counties = counties
+		fish.Reconciler.SetCounties(counties)
+	} else {
+		// Load species metadata first so validation can check species codes.
+		err = controller.LoadSpeciesMap(m, cfg.Data.SpeciesFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading species data: %w", err)
+		}
 
+		// Load fish survey data.
+		err = controller.LoadStateData(context.Background(), m, fish.States, fish.Validation)
+		if err != nil {
+			return nil, fmt.Errorf("loading fish survey data: %w", err)
+		}
+	}
+	controller.ApplySpeciesStatuses(m.SpeciesMap, fish.SpeciesStatuses)
 	for _, county := range counties {
 		log.Printf("Loaded county normalized: '%s' (original: '%s', ID: %s)", controller.NormalizeCountyName(county.CountyName), county.CountyName, county.ID)
 	}
+	log.Printf("✅ Validated %d surveys: %d dropped, %d flagged", m.Validation.Surveys, m.Validation.DroppedSurveys, m.Validation.FlaggedSurveys)
+
+	// Precompute species, county, lake and year rollups for the stats endpoints.
+	controller.MaterializeAggregates(m, fish.Reconciler)
+
+	// Enhance counties with lake names from fish survey data.
+	enhancedCounties := controller.EnhanceCountiesWithLakes(m, counties, fish.Reconciler)
+	reconciliation := fish.Reconciler.ReconcileCounties(m.FishDataByCounty, counties)
+	log.Printf("✅ Matched %d of %d survey county names; %d counties have no lakes",
+		reconciliation.Matched, reconciliation.SurveyCountyNames, len(reconciliation.CountiesWithoutLakes))
+	for _, match := range reconciliation.Reconciled {
+		log.Printf("County '%s' matched to '%s' by %s (confidence %.2f)", match.Name, match.CountyName, match.Method, match.Confidence)
+	}
+	for _, unmatched := range reconciliation.Unmatched {
+		log.Printf("❌ Survey county '%s' (%d lakes) matches no county; its lakes are left out of county filters", unmatched.Name, unmatched.Lakes)
+	}
+
+	return &dataset{
+		Model:    m,
+		Fish:     fish,
+		Counties: controller.NewCountyController(enhancedCounties, m, fish.Reconciler),
+	}, nil
+}
 
-	// Load fish survey data.
-	err = controller.LoadFishData(m, "data/surveys")
+// configureCounties sets up the survey controller's configured states, loads
+// their counties and prepares its county matching.
+func configureCounties(cfg *config.Config, fish *controller.FishSurveyController) ([]model.County, error) {
+	var err error
+	fish.States, err = stateDatasets(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("configuring states: %w", err)
+	}
+	var counties []model.County
+	for _, state := range fish.States {
+		stateCounties, err := controller.LoadCounties(state.CountiesFile, state.Code)
+		if err != nil {
+			return nil, fmt.Errorf("loading %s counties: %w", state.Code, err)
+		}
+		counties = append(counties, stateCounties...)
+	}
+	controller.Counties = counties 
+	fish.Reconciler.SetAliases(cfg.Counties.Aliases)
+	fish.Reconciler.SetMinConfidence(cfg.Counties.MinFuzzyConfidence)
+	fish.Reconciler.SetCounties(counties)
+	lakeCounties, err := controller.LoadLakeCounties(cfg.Data.LakeCountiesFile)
 	if err != nil {
-		log.Fatalf("Error loading fish survey data: %v", err)
+		return nil, fmt.Errorf("loading lake counties: %w", err)
 	}
+	fish.Reconciler.SetLakeCounties(lakeCounties)
+	return counties, nil
+}
 
-	// Load species metadata.
-	err = controller.LoadSpeciesMap(m, "data/fish_species.json")
+// loadSnapshot returns the configured dataset snapshot when it exists and was
+// built from the current data files of states, or nil to load the JSON files
+// instead.
+func loadSnapshot(cfg *config.Config, states []controller.StateDataset) *controller.DatasetSnapshot {
+	path := cfg.Data.SnapshotFile
+	if path == "" {
+		return nil
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	start := time.Now()
+	fingerprint, err := snapshotFingerprint(cfg, states)
 	if err != nil {
-		log.Fatalf("Error loading species data: %v", err)
+		log.Printf("⚠️ Not using dataset snapshot %s: %v", path, err)
+		return nil
 	}
+	snapshot, err := controller.LoadFreshSnapshot(path, fingerprint)
+	if err != nil {
+		log.Printf("⚠️ Not using dataset snapshot %s, loading the JSON files instead: %v", path, err)
+		return nil
+	}
+	log.Printf("✅ Loaded dataset snapshot %s: %d lakes, %d surveys in %s",
+		path, snapshot.Lakes(), snapshot.Surveys(), time.Since(start).Round(time.Millisecond))
+	return snapshot
+}
 
-	// Enhance counties with lake names from fish survey data.
-	enhancedCounties := controller.EnhanceCountiesWithLakes(m, counties)
+// snapshotFingerprint fingerprints the files and settings a dataset snapshot
+// is built from.
+func snapshotFingerprint(cfg *config.Config, states []controller.StateDataset) (string, error) {
+	files := []string{cfg.Data.SpeciesFile}
+	for _, state := range states {
+		files = append(files, state.CountiesFile)
+	}
+	settings := map[string]interface{}{
+		"states":     cfg.States,
+		"sources":    cfg.Data.Sources,
+		"validation": cfg.Validation.Rules,
+	}
+	return controller.InputFingerprint(files, states, settings)
+}
+
+// configureValidation applies the configured rule severities to validator.
+func configureValidation(cfg *config.Config, validator *controller.Validator) error {
+	for rule, severity := range cfg.Validation.Rules {
+		if err := validator.SetSeverity(rule, severity); err != nil {
+			return fmt.Errorf("configuring validation: %w", err)
+		}
+	}
+	return nil
+}
+
+// configureRankings applies the lake ranking weights.
+func configureRankings(cfg *config.Config, fish *controller.FishSurveyController) error {
+	r := cfg.Rankings
+	settings := controller.RankingSettings{
+		Weights:              r.Weights,
+		RecentSurveys:        r.RecentSurveys,
+		RecencyHalfLifeYears: r.RecencyHalfLifeYears,
+	}
+	if err := settings.Validate(); err != nil {
+		return fmt.Errorf("configuring rankings: %w", err)
+	}
+	fish.Rankings = settings
+	return nil
+}
+
+// serve loads the data and runs the HTTP server (and gRPC when enabled) until
+// it stops.
+func serve(cfg *config.Config) {
+	ds, err := loadDataset(cfg)
+	if err != nil {
+		log.Fatalf("Error %v", err)
+	}
+	fishController := ds.Fish
+	countyController := ds.Counties
+
+	// Track which surveys are new across loads and publish them as events.
+	surveyEvents := controller.NewSurveyEventLog(cfg.Data.EventLogSize)
+	reloader := controller.NewDataReloader(fishController, countyController, cfg.Data.SurveyDir, cfg.Data.SurveyKeysFile, surveyEvents)
+	reloader.LakeCountiesFile = cfg.Data.LakeCountiesFile
+	if result, err := reloader.Baseline(); err != nil {
+		log.Printf("❌ Error recording survey baseline: %v", err)
+	} else if result.NewSurveys > 0 {
+		log.Printf("✅ %d surveys are new since the last run", result.NewSurveys)
+	}
+	if interval := time.Duration(cfg.Data.ReloadIntervalSeconds) * time.Second; interval > 0 {
+		go func() {
+			for range time.Tick(interval) {
+				if _, err := reloader.Reload(context.Background()); err != nil {
+					log.Printf("❌ Periodic data reload failed: %v", err)
+				}
+			}
+		}()
+	}
+
+	// Load API keys from the config and the key store file.
+	var keyController *controller.APIKeyController
+	if cfg.Auth.Enabled {
+		keys, err := controller.LoadAPIKeys(cfg.Auth.KeysFile)
+		if err != nil {
+			log.Fatalf("Error loading API keys: %v", err)
+		}
+		keys = append(keys, cfg.Auth.Keys...)
+		keyController = controller.NewAPIKeyController(keys, cfg.Auth.DefaultRatePerMinute, cfg.Auth.DefaultBurst)
+		if keyController.KeyCount() == 0 {
+			log.Fatalf("API key authentication is enabled but no keys are configured; add keys to %s or auth.keys, or set auth.enabled to false", cfg.Auth.KeysFile)
+		}
+	} else {
+		log.Println("⚠️ API key authentication is disabled; all routes, including /admin, are open")
+	}
 
-	// Create controllers.
-	fishController := controller.NewFishSurveyController(m)
-	countyController := controller.NewCountyController(enhancedCounties, m)
+	// Deliver survey events to webhook subscribers.
+	var webhookController *controller.WebhookController
+	if cfg.Webhooks.Enabled {
+		subscriptions, err := controller.LoadWebhooks(cfg.Webhooks.File)
+		if err != nil {
+			log.Fatalf("Error loading webhooks: %v", err)
+		}
+		webhookController = controller.NewWebhookController(cfg.Webhooks.File, subscriptions, cfg.Webhooks.DeliveryLogSize, cfg.Webhooks.Concurrency)
+		webhookController.Client = &http.Client{Timeout: time.Duration(cfg.Webhooks.TimeoutSeconds) * time.Second}
+		webhookController.MaxAttempts = cfg.Webhooks.MaxAttempts
+		webhookController.InitialBackoff = time.Duration(cfg.Webhooks.InitialBackoffSeconds) * time.Second
+		webhookController.MaxBackoff = time.Duration(cfg.Webhooks.MaxBackoffSeconds) * time.Second
+		go webhookController.Run(context.Background(), surveyEvents)
+	}
 
 	// Setup router.
-	router := gin.Default()
-	view.SetupRoutes(router, fishController, countyController)
+	router := gin.New()
+	router.Use(view.RequestID())
+	router.Use(gin.Logger())
+	router.Use(view.Recovery())
+	router.Use(view.SecurityHeaders(cfg.Security))
+	router.Use(view.CORS(cfg.CORS))
+	router.Use(view.APIKeyAuth(keyController))
+	router.Use(view.BodyLimit(cfg.Limits.MaxBodyBytes, cfg.Limits.RouteBodyBytes))
+	router.Use(view.Timeout(cfg.Limits.Timeouts))
+	view.SetupRoutes(router, fishController, countyController, keyController)
+	admin := view.SetupAdminRoutes(router, keyController, reloader)
+	if webhookController != nil {
+		view.SetupWebhookRoutes(admin, webhookController)
+	}
+	view.SetupCountyAdminRoutes(admin, countyController)
+	view.SetupSpeciesAdminRoutes(admin, controller.NewSpeciesCatalog(reloader, cfg.Data.SpeciesFile, fishController.SpeciesStatuses))
+	view.SetupEventRoutes(router, surveyEvents, keyController)
+
+	if cfg.GraphQL.Enabled {
+		executor, err := graphqlapi.NewExecutor(fishController, countyController, cfg.GraphQL.MaxCost, cfg.GraphQL.MaxDepth)
+		if err != nil {
+			log.Fatalf("Error building GraphQL schema: %v", err)
+		}
+		view.SetupGraphQLRoutes(router, executor, keyController)
+	}
+
+	// Serve the gRPC API on its own port with the same controllers.
+	if cfg.GRPC.Enabled {
+		grpcServer := grpcapi.NewServer(fishController, countyController)
+		go func() {
+			if err := grpcapi.Run(cfg, grpcServer, keyController); err != nil {
+				log.Fatalf("gRPC server stopped: %v", err)
+			}
+		}()
+	}
 
-	log.Println("Server running on port 8080...")
-	router.Run(":8080")
-}
\ No newline at end of file
+	if err := server.Run(router, cfg); err != nil {
+		log.Fatalf("Server stopped: %v", err)
+	}
+}
+
+// stateDatasets builds the configured state datasets, defaulting to Minnesota
+// DNR data in the data section's files.
+func stateDatasets(cfg *config.Config) ([]controller.StateDataset, error) {
+	if len(cfg.States) == 0 {
+		state := controller.DefaultStateDataset(cfg.Data.CountiesFile, cfg.Data.SurveyDir)
+		sources, err := surveySources(cfg.Data.Sources)
+		if err != nil {
+			return nil, err
+		}
+		state.Sources = sources
+		return []controller.StateDataset{state}, nil
+	}
+	states := make([]controller.StateDataset, 0, len(cfg.States))
+	seen := make(map[string]bool)
+	for _, sc := range cfg.States {
+		code := model.NormalizeState(sc.Code)
+		if seen[code] {
+			return nil, fmt.Errorf("state %s is configured twice", code)
+		}
+		seen[code] = true
+		adapterName := sc.Adapter
+		if adapterName == "" {
+			adapterName = "mn_dnr"
+		}
+		adapter, exists := controller.GetAdapter(adapterName)
+		if !exists {
+			return nil, fmt.Errorf("state %s: unknown survey adapter %q", code, adapterName)
+		}
+		sources, err := surveySources(sc.Sources)
+		if err != nil {
+			return nil, fmt.Errorf("state %s: %w", code, err)
+		}
+		states = append(states, controller.StateDataset{
+			Code:         code,
+			Name:         sc.Name,
+			Agency:       sc.Agency,
+			CountiesFile: sc.CountiesFile,
+			SurveyDir:    sc.SurveyDir,
+			Adapter:      adapter,
+			LakeIDOffset: sc.LakeIDOffset,
+			Sources:      sources,
+		})
+	}
+	if err := controller.CheckLakeIDOffsets(states); err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+// surveySources creates the configured survey sources.
+func surveySources(configs []config.SourceConfig) ([]controller.SurveySource, error) {
+	var sources []controller.SurveySource
+	for _, sc := range configs {
+		source, err := controller.NewSurveySource(sc.Type, sc.Path)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
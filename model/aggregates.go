@@ -0,0 +1,87 @@
+package model
+
+// SpeciesAggregate is the precomputed rollup for a single species code
+// across every loaded survey.
+type SpeciesAggregate struct {
+	Code           string
+	SurveyCount    int
+	TotalQuantity  int
+	TotalLengthSum int
+	BiggestLength  int
+	ShortestLength int
+	Histogram      map[int]int                // length -> quantity
+	Lakes          map[string]bool            // lowercased lake names where the species was seen
+	LakesByCounty  map[string]map[string]bool // normalized county -> lake names where the species was seen
+}
+
+// CountyAggregate is the precomputed rollup for a single county, keyed by
+// its normalized name.
+type CountyAggregate struct {
+	NormalizedName  string
+	SurveyIDs       []string
+	TotalSurveys    int
+	TotalFishCaught int
+	SpeciesCounts   map[string]int // species ID -> total catch
+	UnknownCatch    int            // catch of species codes missing from the catalog
+	Lakes           map[string]bool
+}
+
+// LakeAggregate is the precomputed rollup for a single lake, keyed by DOW number.
+type LakeAggregate struct {
+	DOWNumber       int
+	LakeName        string
+	CountyName      string
+	State           string
+	TotalSurveys    int
+	TotalFishCaught int
+	FirstYear       int
+	LastYear        int
+	SpeciesCounts   map[string]int // species code -> total catch
+}
+
+// YearAggregate is the precomputed rollup for a single survey year.
+type YearAggregate struct {
+	Year            int
+	TotalSurveys    int
+	TotalFishCaught int
+	SpeciesCounts   map[string]int // species code -> total catch
+	Lakes           map[int]bool   // DOW numbers surveyed that year
+}
+
+// UnknownSpeciesAggregate tracks a species code found in survey data but
+// missing from the species catalog.
+type UnknownSpeciesAggregate struct {
+	Code         string       `json:"code"`
+	Surveys      int          `json:"surveys"`       // surveys mentioning the code
+	FishMeasured int          `json:"fish_measured"` // fish in the code's length histograms
+	TotalCatch   int          `json:"total_catch"`   // fish in the code's catch summaries
+	FirstSeen    string       `json:"first_seen"`    // earliest survey date
+	LastSeen     string       `json:"last_seen"`     // latest survey date
+	Lakes        map[int]bool `json:"-"`             // DOW numbers
+}
+
+// Aggregates holds every rollup materialized after the data load.
+type Aggregates struct {
+	Species          map[string]*SpeciesAggregate        // keyed by species code
+	Counties         map[string]*CountyAggregate         // keyed by state-qualified normalized county name
+	Lakes            map[int]*LakeAggregate              // keyed by DOW number
+	Years            map[int]*YearAggregate              // keyed by survey year
+	AllLakes         map[string]bool                     // lowercased lake names across the state
+	AllLakesByCounty map[string]map[string]bool          // normalized county -> lake names
+	UnknownSpecies   map[string]*UnknownSpeciesAggregate // keyed by species code
+	ByState          map[string]*Aggregates              // the same rollups per state code
+}
+
+// NewAggregates returns empty rollups.
+func NewAggregates() *Aggregates {
+	return &Aggregates{
+		Species:          make(map[string]*SpeciesAggregate),
+		Counties:         make(map[string]*CountyAggregate),
+		Lakes:            make(map[int]*LakeAggregate),
+		Years:            make(map[int]*YearAggregate),
+		AllLakes:         make(map[string]bool),
+		AllLakesByCounty: make(map[string]map[string]bool),
+		UnknownSpecies:   make(map[string]*UnknownSpeciesAggregate),
+		ByState:          make(map[string]*Aggregates),
+	}
+}
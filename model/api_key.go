@@ -0,0 +1,29 @@
+package model
+
+// API key scopes.
+const (
+	ScopePublicRead = "public:read"
+	ScopeExport     = "export"
+	ScopeAdmin      = "admin"
+)
+
+// APIKey describes a client allowed to call the API and its rate limit.
+type APIKey struct {
+	Key           string   `json:"key"`
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	RatePerMinute int      `json:"rate_per_minute,omitempty"` // falls back to the configured default when zero
+	Burst         int      `json:"burst,omitempty"`           // falls back to the configured default when zero
+	Disabled      bool     `json:"disabled,omitempty"`
+}
+
+// HasScope reports whether the key grants the given scope. The admin scope
+// implies every other scope.
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
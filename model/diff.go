@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+// FieldChange is one difference between two versions of a survey. Field is a
+// dotted path such as "narrative", "total_catch.WAE" or "lengths.WAE.12" (the
+// fish counted at 12 inches); Old or New is nil when the value is missing on
+// that side.
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// SurveyDiff describes one survey that was added, removed or modified between
+// two datasets. Changes is only set for modified surveys, and only when both
+// versions were available to compare.
+type SurveyDiff struct {
+	SurveyKey  string        `json:"survey_key"`
+	SurveyID   string        `json:"survey_id"`
+	SurveyDate string        `json:"survey_date"`
+	SurveyType string        `json:"survey_type"`
+	DOWNumber  int           `json:"dow_number"`
+	State      string        `json:"state"`
+	LakeName   string        `json:"lake_name"`
+	CountyName string        `json:"county_name"`
+	Changes    []FieldChange `json:"changes,omitempty"`
+}
+
+// DiffSummary counts the surveys in each category of a DatasetDiff.
+type DiffSummary struct {
+	Added     int `json:"added"`
+	Removed   int `json:"removed"`
+	Modified  int `json:"modified"`
+	Unchanged int `json:"unchanged"`
+}
+
+// DatasetDiff compares two datasets survey by survey, matching surveys by
+// SurveyKey. From and To label the two sides, e.g. a directory or "live".
+type DatasetDiff struct {
+	ComparedAt time.Time    `json:"compared_at"`
+	From       string       `json:"from"`
+	To         string       `json:"to"`
+	Summary    DiffSummary  `json:"summary"`
+	Added      []SurveyDiff `json:"added"`
+	Removed    []SurveyDiff `json:"removed"`
+	Modified   []SurveyDiff `json:"modified"`
+}
+
+// Empty reports whether the two datasets hold the same surveys.
+func (d *DatasetDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SurveyKey identifies a survey across data loads by lake DOW number, survey
+// date and survey type. Survey IDs are assigned at load time when the scraper
+// omits them, so they can't be used to tell loads apart.
+func SurveyKey(dowNumber int, survey Survey) string {
+	return strconv.Itoa(dowNumber) + "|" + survey.SurveyDate + "|" + survey.SurveyType
+}
+
+// ParseSurveyKey splits a SurveyKey into the lake DOW number, survey date and
+// survey type.
+func ParseSurveyKey(key string) (dowNumber int, surveyDate, surveyType string, ok bool) {
+	parts := strings.SplitN(key, "|", 3)
+	if len(parts) != 3 {
+		return 0, "", "", false
+	}
+	dowNumber, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, "", "", false
+	}
+	return dowNumber, parts[1], parts[2], true
+}
+
+// Survey event types.
+const (
+	EventSurveyAdded   = "survey.added"
+	EventSurveyUpdated = "survey.updated"
+	EventSurveyRemoved = "survey.removed"
+)
+
+// SurveyEvent announces a change to the survey data found by a data load.
+type SurveyEvent struct {
+	ID           int64         `json:"id"`
+	Type         string        `json:"type"`
+	CreatedAt    time.Time     `json:"created_at"`
+	SurveyKey    string        `json:"survey_key"`
+	SurveyID     string        `json:"survey_id"`
+	SurveyDate   string        `json:"survey_date"`
+	SurveyType   string        `json:"survey_type"`
+	DOWNumber    int           `json:"dow_number"`
+	State        string        `json:"state"`
+	LakeName     string        `json:"lake_name"`
+	CountyName   string        `json:"county_name"`
+	CountyIDs    []string      `json:"county_ids"` // every county the lake lies in
+	SpeciesIDs   []string      `json:"species_ids"`
+	SpeciesCodes []string      `json:"species_codes"`
+	GameFish     bool          `json:"game_fish"`         // at least one game fish species was caught
+	Changes      []FieldChange `json:"changes,omitempty"` // field-level changes of an updated survey
+}
@@ -7,14 +7,33 @@ import (
 // ✅ Data structures
 
 type Species struct {
-	ID             string `json:"id"`             // <-- New ID field
-	Code           string `json:"code"`
-	CommonName     string `json:"common_name"`
-	ScientificName string `json:"scientific_name"`
-	GameFish       bool   `json:"game_fish"`
-	SpeciesGroup   string `json:"species_group"`
-	ImageURL       string `json:"image_url"`
-	Description    string `json:"description"`
+	ID             string   `json:"id"`             // <-- New ID field
+	Code           string   `json:"code"`
+	CommonName     string   `json:"common_name"`
+	ScientificName string   `json:"scientific_name"`
+	GameFish       bool     `json:"game_fish"`
+	SpeciesGroup   string   `json:"species_group"`
+	ImageURL       string   `json:"image_url"`
+	Description    string   `json:"description"`
+	Status         []string `json:"status,omitempty"` // status categories such as "invasive", from the species status list
+}
+
+// Species status categories.
+const (
+	StatusInvasive       = "invasive"
+	StatusSpecialConcern = "special_concern"
+	StatusThreatened     = "threatened"
+	StatusEndangered     = "endangered"
+)
+
+// HasStatus reports whether the species is in a status category.
+func (s Species) HasStatus(status string) bool {
+	for _, category := range s.Status {
+		if category == status {
+			return true
+		}
+	}
+	return false
 }
 
 // ✅ Struct for fishCount entry
@@ -39,6 +58,7 @@ type Survey struct {
 	Lengths            map[string]*LengthData `json:"lengths"`
 	SurveyType         string                 `json:"surveyType"`
 	SurveySubType      string                 `json:"suveySubType"`
+	QualityFlags       []QualityFlag          `json:"quality_flags,omitempty"` // set by validation at load time
 }
 
 type FishCatchSummary struct {
@@ -50,6 +70,8 @@ type FishData struct {
 	Result struct {
 		DOWNumber  int      `json:"DOWNumber"`
 		CountyName string   `json:"countyName"`
+		CountyFIPS string   `json:"countyFIPS,omitempty"` // provided by some scraper versions
+		State      string   `json:"state,omitempty"`      // set at load time; empty means DefaultState
 		LakeName   string   `json:"lakeName"`
 		Surveys    []Survey `json:"surveys"`
 	} `json:"result"`
@@ -59,13 +81,53 @@ type FishData struct {
 type FishSurveyModel struct {
 	FishDataByCounty map[string][]FishData
 	SpeciesMap       map[string]Species
-	Mutex            sync.Mutex
+	Aggregates       *Aggregates      // populated by controller.MaterializeAggregates
+	LakeFiles        map[int][]string // survey files each lake was loaded from, by DOW number
+	ReadOnlyLakes    map[int]string   // lakes loaded from archives or CSV, with one source path
+	Validation       *ValidationReport // validation counts of the last file load
+	Mutex            sync.RWMutex
+	stateViews       map[string]stateView // InState views of the current snapshot, by state
+}
+
+// Snapshot returns the current survey data and aggregates. Loaded data is
+// never modified in place, so callers can iterate the returned values without
+// holding the lock while a reload swaps in new ones.
+func (m *FishSurveyModel) Snapshot() (map[string][]FishData, *Aggregates) {
+	m.Mutex.RLock()
+	defer m.Mutex.RUnlock()
+	return m.FishDataByCounty, m.Aggregates
+}
+
+// Species returns the current species catalog keyed by code. Like the survey
+// data it is replaced rather than modified, so callers may read it freely.
+func (m *FishSurveyModel) Species() map[string]Species {
+	m.Mutex.RLock()
+	defer m.Mutex.RUnlock()
+	return m.SpeciesMap
+}
+
+// ReplaceSpecies swaps in an edited species catalog with aggregates
+// recomputed against it.
+func (m *FishSurveyModel) ReplaceSpecies(speciesMap map[string]Species, aggregates *Aggregates) {
+	m.Mutex.Lock()
+	defer m.Mutex.Unlock()
+	m.SpeciesMap = speciesMap
+	m.Aggregates = aggregates
+}
+
+// Replace swaps in freshly loaded survey data and aggregates.
+func (m *FishSurveyModel) Replace(fishDataByCounty map[string][]FishData, aggregates *Aggregates) {
+	m.Mutex.Lock()
+	defer m.Mutex.Unlock()
+	m.FishDataByCounty = fishDataByCounty
+	m.Aggregates = aggregates
 }
 
 // County struct represents the county data.
 type County struct {
 	ID          string   `json:"id"`           // <-- New ID field
 	CountyName  string   `json:"county_name"`
+	State       string   `json:"state,omitempty"` // set at load time from the state's config
 	FIPSCode    string   `json:"fips_code"`
 	CountySeat  string   `json:"county_seat"`
 	Established int      `json:"established"`
@@ -0,0 +1,11 @@
+package model
+
+// RankingWeights weighs the components of a lake's ranking score, as set in
+// the config's rankings section. Only their ratios matter; they are
+// normalized to sum to 1.
+type RankingWeights struct {
+	Catch   float64 `json:"catch"`
+	Size    float64 `json:"size"`
+	Recency float64 `json:"recency"`
+	Trend   float64 `json:"trend"`
+}
@@ -0,0 +1,100 @@
+package model
+
+import "strings"
+
+// DefaultState is the state of data and counties that don't name one: the
+// original Minnesota dataset.
+const DefaultState = "MN"
+
+// State describes one state's dataset.
+type State struct {
+	Code         string `json:"code"`
+	Name         string `json:"name"`
+	Agency       string `json:"agency"`
+	LakeIDOffset int    `json:"lake_id_offset"` // added to the agency's lake IDs to keep them apart from other states'
+	Counties     int    `json:"counties"`
+	Lakes        int    `json:"lakes"`
+}
+
+// NormalizeState returns a state code in its canonical upper-case form, or
+// DefaultState when it is empty.
+func NormalizeState(state string) string {
+	state = strings.ToUpper(strings.TrimSpace(state))
+	if state == "" {
+		return DefaultState
+	}
+	return state
+}
+
+// LakeState returns the state a lake's survey data belongs to.
+func LakeState(data FishData) string {
+	return NormalizeState(data.Result.State)
+}
+
+// CountyState returns the state a county belongs to.
+func CountyState(county County) string {
+	return NormalizeState(county.State)
+}
+
+// stateView is a state's view of one snapshot, identified by its aggregates:
+// every load, ingest and catalog edit materializes new ones.
+type stateView struct {
+	aggregates *Aggregates
+	view       *FishSurveyModel
+}
+
+// InState returns a model restricted to one state's lakes. It shares the
+// species catalog and uses the state's own aggregates, so the controllers can
+// serve a state filter without knowing about states. The result is a
+// read-only view of the current snapshot, built once per snapshot and state.
+func (m *FishSurveyModel) InState(state string) *FishSurveyModel {
+	state = NormalizeState(state)
+	m.Mutex.RLock()
+	cached, exists := m.stateViews[state]
+	current := m.Aggregates
+	m.Mutex.RUnlock()
+	if exists && current != nil && cached.aggregates == current {
+		return cached.view
+	}
+
+	view := m.buildStateView(state)
+	m.Mutex.Lock()
+	defer m.Mutex.Unlock()
+	// Keep the view only if no new snapshot was swapped in meanwhile, and
+	// drop the views of older snapshots.
+	if current != nil && m.Aggregates == current {
+		for code, other := range m.stateViews {
+			if other.aggregates != current {
+				delete(m.stateViews, code)
+			}
+		}
+		if m.stateViews == nil {
+			m.stateViews = make(map[string]stateView)
+		}
+		m.stateViews[state] = stateView{aggregates: current, view: view}
+	}
+	return view
+}
+
+// buildStateView filters the current snapshot to one state's lakes.
+func (m *FishSurveyModel) buildStateView(state string) *FishSurveyModel {
+	fishDataByCounty, agg := m.Snapshot()
+	view := &FishSurveyModel{
+		FishDataByCounty: make(map[string][]FishData),
+		SpeciesMap:       m.Species(),
+	}
+	for countyName, fishDataList := range fishDataByCounty {
+		for _, data := range fishDataList {
+			if LakeState(data) == state {
+				view.FishDataByCounty[countyName] = append(view.FishDataByCounty[countyName], data)
+			}
+		}
+	}
+	if agg != nil {
+		view.Aggregates = agg.ByState[state]
+		if view.Aggregates == nil {
+			view.Aggregates = NewAggregates()
+		}
+	}
+	return view
+}
@@ -0,0 +1,54 @@
+package model
+
+import "testing"
+
+func stateLake(dow int, state string) FishData {
+	var data FishData
+	data.Result.DOWNumber = dow
+	data.Result.State = state
+	data.Result.CountyName = "Test"
+	return data
+}
+
+func TestInStateIsBuiltOncePerSnapshot(t *testing.T) {
+	wiAggregates := NewAggregates()
+	aggregates := NewAggregates()
+	aggregates.ByState["WI"] = wiAggregates
+	m := &FishSurveyModel{}
+	m.Replace(map[string][]FishData{"Test": {stateLake(1, "MN"), stateLake(100000001, "WI")}}, aggregates)
+
+	wi := m.InState("wi")
+	if got := len(wi.FishDataByCounty["Test"]); got != 1 || wi.FishDataByCounty["Test"][0].Result.DOWNumber != 100000001 {
+		t.Fatalf("WI view = %+v, want the one WI lake", wi.FishDataByCounty)
+	}
+	if wi.Aggregates != wiAggregates {
+		t.Error("WI view doesn't use the WI aggregates")
+	}
+	if again := m.InState("WI"); again != wi {
+		t.Error("InState rebuilt the view for the same snapshot")
+	}
+	mn := m.InState("")
+	if len(mn.FishDataByCounty["Test"]) != 1 || mn.FishDataByCounty["Test"][0].Result.DOWNumber != 1 {
+		t.Errorf("MN view = %+v, want the one MN lake", mn.FishDataByCounty)
+	}
+
+	// A new snapshot gets new views.
+	m.Replace(map[string][]FishData{"Test": {stateLake(100000002, "WI")}}, NewAggregates())
+	fresh := m.InState("WI")
+	if fresh == wi {
+		t.Fatal("InState returned the view of the previous snapshot")
+	}
+	if got := fresh.FishDataByCounty["Test"]; len(got) != 1 || got[0].Result.DOWNumber != 100000002 {
+		t.Errorf("WI view = %+v, want the new lake", fresh.FishDataByCounty)
+	}
+	if _, stale := m.stateViews["MN"]; stale {
+		t.Error("the previous snapshot's MN view was kept")
+	}
+}
+
+func TestInStateWithoutAggregatesIsNotCached(t *testing.T) {
+	m := &FishSurveyModel{FishDataByCounty: map[string][]FishData{"Test": {stateLake(1, "MN")}}}
+	if first, second := m.InState("MN"), m.InState("MN"); first == second {
+		t.Error("a view was cached without aggregates to identify the snapshot")
+	}
+}
@@ -0,0 +1,63 @@
+package model
+
+// Validation severities: what happens to a survey that breaks a rule.
+const (
+	SeverityDrop = "drop" // the survey is discarded
+	SeverityFlag = "flag" // the survey is kept and flagged
+	SeverityFix  = "fix"  // the rule repairs the survey, which is flagged as fixed
+	SeverityOff  = "off"  // the rule doesn't run
+)
+
+// QualityFlag records a data quality problem found on a survey.
+type QualityFlag struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Species  string `json:"species,omitempty"` // species code, empty for survey-wide problems
+	Message  string `json:"message"`
+	Fixed    bool   `json:"fixed,omitempty"`
+}
+
+// RuleReport counts what one validation rule did during a load.
+type RuleReport struct {
+	Severity string `json:"severity"`
+	Issues   int    `json:"issues"`  // problems found, possibly several per survey
+	Surveys  int    `json:"surveys"` // surveys with at least one problem
+	Dropped  int    `json:"dropped"`
+	Flagged  int    `json:"flagged"`
+	Fixed    int    `json:"fixed"`
+}
+
+// ValidationReport summarizes validation of a load or ingest request.
+type ValidationReport struct {
+	Surveys        int                    `json:"surveys"`
+	DroppedSurveys int                    `json:"dropped_surveys"`
+	FlaggedSurveys int                    `json:"flagged_surveys"`
+	Rules          map[string]*RuleReport `json:"rules"`
+}
+
+// NewValidationReport returns an empty report.
+func NewValidationReport() *ValidationReport {
+	return &ValidationReport{Rules: make(map[string]*RuleReport)}
+}
+
+// Merge adds another report's counts to this one.
+func (r *ValidationReport) Merge(other *ValidationReport) {
+	if other == nil {
+		return
+	}
+	r.Surveys += other.Surveys
+	r.DroppedSurveys += other.DroppedSurveys
+	r.FlaggedSurveys += other.FlaggedSurveys
+	for name, rule := range other.Rules {
+		total, exists := r.Rules[name]
+		if !exists {
+			total = &RuleReport{Severity: rule.Severity}
+			r.Rules[name] = total
+		}
+		total.Issues += rule.Issues
+		total.Surveys += rule.Surveys
+		total.Dropped += rule.Dropped
+		total.Flagged += rule.Flagged
+		total.Fixed += rule.Fixed
+	}
+}
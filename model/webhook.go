@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// WebhookSubscription registers a URL to be notified of survey events. Empty
+// filters match everything; filters of different kinds must all match.
+type WebhookSubscription struct {
+	ID           string    `json:"id"`
+	URL          string    `json:"url"`
+	Secret       string    `json:"secret"` // HMAC-SHA256 key for the X-FishReports-Signature header
+	Description  string    `json:"description,omitempty"`
+	CountyIDs    []string  `json:"county_ids,omitempty"`
+	DOWNumbers   []int     `json:"dow_numbers,omitempty"`
+	SpeciesIDs   []string  `json:"species_ids,omitempty"`
+	GameFishOnly bool      `json:"game_fish_only"`
+	EventTypes   []string  `json:"event_types,omitempty"` // defaults to every survey event
+	Disabled     bool      `json:"disabled"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// WebhookPayload is the JSON body POSTed to a subscriber.
+type WebhookPayload struct {
+	DeliveryID     string      `json:"delivery_id"`
+	SubscriptionID string      `json:"subscription_id"`
+	Event          SurveyEvent `json:"event"`
+	SentAt         time.Time   `json:"sent_at"`
+}
+
+// WebhookDelivery records one delivery attempt.
+type WebhookDelivery struct {
+	DeliveryID     string    `json:"delivery_id"`
+	SubscriptionID string    `json:"subscription_id"`
+	EventID        int64     `json:"event_id"`
+	EventType      string    `json:"event_type"`
+	URL            string    `json:"url"`
+	Attempt        int       `json:"attempt"`
+	StatusCode     int       `json:"status_code,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	Success        bool      `json:"success"`
+	Final          bool      `json:"final"` // no further attempts will be made
+	DurationMS     int64     `json:"duration_ms"`
+	AttemptedAt    time.Time `json:"attempted_at"`
+}
@@ -0,0 +1,1643 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: fishreports.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Species struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Code           string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	CommonName     string                 `protobuf:"bytes,3,opt,name=common_name,json=commonName,proto3" json:"common_name,omitempty"`
+	ScientificName string                 `protobuf:"bytes,4,opt,name=scientific_name,json=scientificName,proto3" json:"scientific_name,omitempty"`
+	GameFish       bool                   `protobuf:"varint,5,opt,name=game_fish,json=gameFish,proto3" json:"game_fish,omitempty"`
+	SpeciesGroup   string                 `protobuf:"bytes,6,opt,name=species_group,json=speciesGroup,proto3" json:"species_group,omitempty"`
+	ImageUrl       string                 `protobuf:"bytes,7,opt,name=image_url,json=imageUrl,proto3" json:"image_url,omitempty"`
+	Description    string                 `protobuf:"bytes,8,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Species) Reset() {
+	*x = Species{}
+	mi := &file_fishreports_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Species) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Species) ProtoMessage() {}
+
+func (x *Species) ProtoReflect() protoreflect.Message {
+	mi := &file_fishreports_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Species.ProtoReflect.Descriptor instead.
+func (*Species) Descriptor() ([]byte, []int) {
+	return file_fishreports_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Species) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Species) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Species) GetCommonName() string {
+	if x != nil {
+		return x.CommonName
+	}
+	return ""
+}
+
+func (x *Species) GetScientificName() string {
+	if x != nil {
+		return x.ScientificName
+	}
+	return ""
+}
+
+func (x *Species) GetGameFish() bool {
+	if x != nil {
+		return x.GameFish
+	}
+	return false
+}
+
+func (x *Species) GetSpeciesGroup() string {
+	if x != nil {
+		return x.SpeciesGroup
+	}
+	return ""
+}
+
+func (x *Species) GetImageUrl() string {
+	if x != nil {
+		return x.ImageUrl
+	}
+	return ""
+}
+
+func (x *Species) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type FishCount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Length        int32                  `protobuf:"varint,1,opt,name=length,proto3" json:"length,omitempty"`
+	Quantity      int32                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FishCount) Reset() {
+	*x = FishCount{}
+	mi := &file_fishreports_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FishCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FishCount) ProtoMessage() {}
+
+func (x *FishCount) ProtoReflect() protoreflect.Message {
+	mi := &file_fishreports_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FishCount.ProtoReflect.Descriptor instead.
+func (*FishCount) Descriptor() ([]byte, []int) {
+	return file_fishreports_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *FishCount) GetLength() int32 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *FishCount) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+type LengthData struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Species       *Species               `protobuf:"bytes,1,opt,name=species,proto3" json:"species,omitempty"`
+	MinimumLength int32                  `protobuf:"varint,2,opt,name=minimum_length,json=minimumLength,proto3" json:"minimum_length,omitempty"`
+	MaximumLength int32                  `protobuf:"varint,3,opt,name=maximum_length,json=maximumLength,proto3" json:"maximum_length,omitempty"`
+	FishCount     []*FishCount           `protobuf:"bytes,4,rep,name=fish_count,json=fishCount,proto3" json:"fish_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LengthData) Reset() {
+	*x = LengthData{}
+	mi := &file_fishreports_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LengthData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LengthData) ProtoMessage() {}
+
+func (x *LengthData) ProtoReflect() protoreflect.Message {
+	mi := &file_fishreports_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LengthData.ProtoReflect.Descriptor instead.
+func (*LengthData) Descriptor() ([]byte, []int) {
+	return file_fishreports_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *LengthData) GetSpecies() *Species {
+	if x != nil {
+		return x.Species
+	}
+	return nil
+}
+
+func (x *LengthData) GetMinimumLength() int32 {
+	if x != nil {
+		return x.MinimumLength
+	}
+	return 0
+}
+
+func (x *LengthData) GetMaximumLength() int32 {
+	if x != nil {
+		return x.MaximumLength
+	}
+	return 0
+}
+
+func (x *LengthData) GetFishCount() []*FishCount {
+	if x != nil {
+		return x.FishCount
+	}
+	return nil
+}
+
+type FishCatchSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Species       string                 `protobuf:"bytes,1,opt,name=species,proto3" json:"species,omitempty"`
+	TotalCatch    int32                  `protobuf:"varint,2,opt,name=total_catch,json=totalCatch,proto3" json:"total_catch,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FishCatchSummary) Reset() {
+	*x = FishCatchSummary{}
+	mi := &file_fishreports_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FishCatchSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FishCatchSummary) ProtoMessage() {}
+
+func (x *FishCatchSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_fishreports_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FishCatchSummary.ProtoReflect.Descriptor instead.
+func (*FishCatchSummary) Descriptor() ([]byte, []int) {
+	return file_fishreports_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *FishCatchSummary) GetSpecies() string {
+	if x != nil {
+		return x.Species
+	}
+	return ""
+}
+
+func (x *FishCatchSummary) GetTotalCatch() int32 {
+	if x != nil {
+		return x.TotalCatch
+	}
+	return 0
+}
+
+type Survey struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	SurveyId           string                 `protobuf:"bytes,1,opt,name=survey_id,json=surveyId,proto3" json:"survey_id,omitempty"`
+	SurveyDate         string                 `protobuf:"bytes,2,opt,name=survey_date,json=surveyDate,proto3" json:"survey_date,omitempty"`
+	SurveyType         string                 `protobuf:"bytes,3,opt,name=survey_type,json=surveyType,proto3" json:"survey_type,omitempty"`
+	SurveySubType      string                 `protobuf:"bytes,4,opt,name=survey_sub_type,json=surveySubType,proto3" json:"survey_sub_type,omitempty"`
+	Narrative          string                 `protobuf:"bytes,5,opt,name=narrative,proto3" json:"narrative,omitempty"`
+	FishCatchSummaries []*FishCatchSummary    `protobuf:"bytes,6,rep,name=fish_catch_summaries,json=fishCatchSummaries,proto3" json:"fish_catch_summaries,omitempty"`
+	// Keyed by species code.
+	Lengths       map[string]*LengthData `protobuf:"bytes,7,rep,name=lengths,proto3" json:"lengths,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Survey) Reset() {
+	*x = Survey{}
+	mi := &file_fishreports_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Survey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Survey) ProtoMessage() {}
+
+func (x *Survey) ProtoReflect() protoreflect.Message {
+	mi := &file_fishreports_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Survey.ProtoReflect.Descriptor instead.
+func (*Survey) Descriptor() ([]byte, []int) {
+	return file_fishreports_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Survey) GetSurveyId() string {
+	if x != nil {
+		return x.SurveyId
+	}
+	return ""
+}
+
+func (x *Survey) GetSurveyDate() string {
+	if x != nil {
+		return x.SurveyDate
+	}
+	return ""
+}
+
+func (x *Survey) GetSurveyType() string {
+	if x != nil {
+		return x.SurveyType
+	}
+	return ""
+}
+
+func (x *Survey) GetSurveySubType() string {
+	if x != nil {
+		return x.SurveySubType
+	}
+	return ""
+}
+
+func (x *Survey) GetNarrative() string {
+	if x != nil {
+		return x.Narrative
+	}
+	return ""
+}
+
+func (x *Survey) GetFishCatchSummaries() []*FishCatchSummary {
+	if x != nil {
+		return x.FishCatchSummaries
+	}
+	return nil
+}
+
+func (x *Survey) GetLengths() map[string]*LengthData {
+	if x != nil {
+		return x.Lengths
+	}
+	return nil
+}
+
+type Lake struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DowNumber     int32                  `protobuf:"varint,1,opt,name=dow_number,json=dowNumber,proto3" json:"dow_number,omitempty"`
+	LakeName      string                 `protobuf:"bytes,2,opt,name=lake_name,json=lakeName,proto3" json:"lake_name,omitempty"`
+	CountyName    string                 `protobuf:"bytes,3,opt,name=county_name,json=countyName,proto3" json:"county_name,omitempty"`
+	CountyId      string                 `protobuf:"bytes,4,opt,name=county_id,json=countyId,proto3" json:"county_id,omitempty"`
+	Surveys       []*Survey              `protobuf:"bytes,5,rep,name=surveys,proto3" json:"surveys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Lake) Reset() {
+	*x = Lake{}
+	mi := &file_fishreports_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Lake) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Lake) ProtoMessage() {}
+
+func (x *Lake) ProtoReflect() protoreflect.Message {
+	mi := &file_fishreports_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Lake.ProtoReflect.Descriptor instead.
+func (*Lake) Descriptor() ([]byte, []int) {
+	return file_fishreports_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Lake) GetDowNumber() int32 {
+	if x != nil {
+		return x.DowNumber
+	}
+	return 0
+}
+
+func (x *Lake) GetLakeName() string {
+	if x != nil {
+		return x.LakeName
+	}
+	return ""
+}
+
+func (x *Lake) GetCountyName() string {
+	if x != nil {
+		return x.CountyName
+	}
+	return ""
+}
+
+func (x *Lake) GetCountyId() string {
+	if x != nil {
+		return x.CountyId
+	}
+	return ""
+}
+
+func (x *Lake) GetSurveys() []*Survey {
+	if x != nil {
+		return x.Surveys
+	}
+	return nil
+}
+
+type County struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CountyName    string                 `protobuf:"bytes,2,opt,name=county_name,json=countyName,proto3" json:"county_name,omitempty"`
+	FipsCode      string                 `protobuf:"bytes,3,opt,name=fips_code,json=fipsCode,proto3" json:"fips_code,omitempty"`
+	CountySeat    string                 `protobuf:"bytes,4,opt,name=county_seat,json=countySeat,proto3" json:"county_seat,omitempty"`
+	Established   int32                  `protobuf:"varint,5,opt,name=established,proto3" json:"established,omitempty"`
+	Origin        string                 `protobuf:"bytes,6,opt,name=origin,proto3" json:"origin,omitempty"`
+	Etymology     string                 `protobuf:"bytes,7,opt,name=etymology,proto3" json:"etymology,omitempty"`
+	Population    int32                  `protobuf:"varint,8,opt,name=population,proto3" json:"population,omitempty"`
+	AreaSqMiles   float64                `protobuf:"fixed64,9,opt,name=area_sq_miles,json=areaSqMiles,proto3" json:"area_sq_miles,omitempty"`
+	MapImageUrl   string                 `protobuf:"bytes,10,opt,name=map_image_url,json=mapImageUrl,proto3" json:"map_image_url,omitempty"`
+	Lakes         []string               `protobuf:"bytes,11,rep,name=lakes,proto3" json:"lakes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *County) Reset() {
+	*x = County{}
+	mi := &file_fishreports_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *County) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*County) ProtoMessage() {}
+
+func (x *County) ProtoReflect() protoreflect.Message {
+	mi := &file_fishreports_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use County.ProtoReflect.Descriptor instead.
+func (*County) Descriptor() ([]byte, []int) {
+	return file_fishreports_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *County) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *County) GetCountyName() string {
+	if x != nil {
+		return x.CountyName
+	}
+	return ""
+}
+
+func (x *County) GetFipsCode() string {
+	if x != nil {
+		return x.FipsCode
+	}
+	return ""
+}
+
+func (x *County) GetCountySeat() string {
+	if x != nil {
+		return x.CountySeat
+	}
+	return ""
+}
+
+func (x *County) GetEstablished() int32 {
+	if x != nil {
+		return x.Established
+	}
+	return 0
+}
+
+func (x *County) GetOrigin() string {
+	if x != nil {
+		return x.Origin
+	}
+	return ""
+}
+
+func (x *County) GetEtymology() string {
+	if x != nil {
+		return x.Etymology
+	}
+	return ""
+}
+
+func (x *County) GetPopulation() int32 {
+	if x != nil {
+		return x.Population
+	}
+	return 0
+}
+
+func (x *County) GetAreaSqMiles() float64 {
+	if x != nil {
+		return x.AreaSqMiles
+	}
+	return 0
+}
+
+func (x *County) GetMapImageUrl() string {
+	if x != nil {
+		return x.MapImageUrl
+	}
+	return ""
+}
+
+func (x *County) GetLakes() []string {
+	if x != nil {
+		return x.Lakes
+	}
+	return nil
+}
+
+type ListSurveysRequest struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	SpeciesIds   []string               `protobuf:"bytes,1,rep,name=species_ids,json=speciesIds,proto3" json:"species_ids,omitempty"`
+	MinYear      string                 `protobuf:"bytes,2,opt,name=min_year,json=minYear,proto3" json:"min_year,omitempty"`
+	MaxYear      string                 `protobuf:"bytes,3,opt,name=max_year,json=maxYear,proto3" json:"max_year,omitempty"`
+	CountyIds    []string               `protobuf:"bytes,4,rep,name=county_ids,json=countyIds,proto3" json:"county_ids,omitempty"`
+	Lakes        []string               `protobuf:"bytes,5,rep,name=lakes,proto3" json:"lakes,omitempty"`
+	SortBy       string                 `protobuf:"bytes,6,opt,name=sort_by,json=sortBy,proto3" json:"sort_by,omitempty"`
+	Order        string                 `protobuf:"bytes,7,opt,name=order,proto3" json:"order,omitempty"`
+	GameFishOnly bool                   `protobuf:"varint,8,opt,name=game_fish_only,json=gameFishOnly,proto3" json:"game_fish_only,omitempty"`
+	Search       string                 `protobuf:"bytes,9,opt,name=search,proto3" json:"search,omitempty"`
+	PageSize     int32                  `protobuf:"varint,10,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Page         int32                  `protobuf:"varint,11,opt,name=page,proto3" json:"page,omitempty"`
+	// State code, as accepted by ?state= on the REST API; empty means all
+	// states.
+	State         string `protobuf:"bytes,12,opt,name=state,proto3" json:"state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSurveysRequest) Reset() {
+	*x = ListSurveysRequest{}
+	mi := &file_fishreports_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSurveysRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSurveysRequest) ProtoMessage() {}
+
+func (x *ListSurveysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fishreports_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSurveysRequest.ProtoReflect.Descriptor instead.
+func (*ListSurveysRequest) Descriptor() ([]byte, []int) {
+	return file_fishreports_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListSurveysRequest) GetSpeciesIds() []string {
+	if x != nil {
+		return x.SpeciesIds
+	}
+	return nil
+}
+
+func (x *ListSurveysRequest) GetMinYear() string {
+	if x != nil {
+		return x.MinYear
+	}
+	return ""
+}
+
+func (x *ListSurveysRequest) GetMaxYear() string {
+	if x != nil {
+		return x.MaxYear
+	}
+	return ""
+}
+
+func (x *ListSurveysRequest) GetCountyIds() []string {
+	if x != nil {
+		return x.CountyIds
+	}
+	return nil
+}
+
+func (x *ListSurveysRequest) GetLakes() []string {
+	if x != nil {
+		return x.Lakes
+	}
+	return nil
+}
+
+func (x *ListSurveysRequest) GetSortBy() string {
+	if x != nil {
+		return x.SortBy
+	}
+	return ""
+}
+
+func (x *ListSurveysRequest) GetOrder() string {
+	if x != nil {
+		return x.Order
+	}
+	return ""
+}
+
+func (x *ListSurveysRequest) GetGameFishOnly() bool {
+	if x != nil {
+		return x.GameFishOnly
+	}
+	return false
+}
+
+func (x *ListSurveysRequest) GetSearch() string {
+	if x != nil {
+		return x.Search
+	}
+	return ""
+}
+
+func (x *ListSurveysRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListSurveysRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListSurveysRequest) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+// SurveyRow mirrors one row of GET /surveys.
+type SurveyRow struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SurveyId      string                 `protobuf:"bytes,1,opt,name=survey_id,json=surveyId,proto3" json:"survey_id,omitempty"`
+	DowNumber     int32                  `protobuf:"varint,2,opt,name=dow_number,json=dowNumber,proto3" json:"dow_number,omitempty"`
+	SurveyType    string                 `protobuf:"bytes,3,opt,name=survey_type,json=surveyType,proto3" json:"survey_type,omitempty"`
+	SurveySubType string                 `protobuf:"bytes,4,opt,name=survey_sub_type,json=surveySubType,proto3" json:"survey_sub_type,omitempty"`
+	CountyName    string                 `protobuf:"bytes,5,opt,name=county_name,json=countyName,proto3" json:"county_name,omitempty"`
+	LakeName      string                 `protobuf:"bytes,6,opt,name=lake_name,json=lakeName,proto3" json:"lake_name,omitempty"`
+	SurveyDate    string                 `protobuf:"bytes,7,opt,name=survey_date,json=surveyDate,proto3" json:"survey_date,omitempty"`
+	SpeciesName   string                 `protobuf:"bytes,8,opt,name=species_name,json=speciesName,proto3" json:"species_name,omitempty"`
+	ImageUrl      string                 `protobuf:"bytes,9,opt,name=image_url,json=imageUrl,proto3" json:"image_url,omitempty"`
+	Narrative     string                 `protobuf:"bytes,10,opt,name=narrative,proto3" json:"narrative,omitempty"`
+	MinLength     int32                  `protobuf:"varint,11,opt,name=min_length,json=minLength,proto3" json:"min_length,omitempty"`
+	MaxLength     int32                  `protobuf:"varint,12,opt,name=max_length,json=maxLength,proto3" json:"max_length,omitempty"`
+	TotalCatch    int32                  `protobuf:"varint,13,opt,name=total_catch,json=totalCatch,proto3" json:"total_catch,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SurveyRow) Reset() {
+	*x = SurveyRow{}
+	mi := &file_fishreports_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SurveyRow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SurveyRow) ProtoMessage() {}
+
+func (x *SurveyRow) ProtoReflect() protoreflect.Message {
+	mi := &file_fishreports_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SurveyRow.ProtoReflect.Descriptor instead.
+func (*SurveyRow) Descriptor() ([]byte, []int) {
+	return file_fishreports_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SurveyRow) GetSurveyId() string {
+	if x != nil {
+		return x.SurveyId
+	}
+	return ""
+}
+
+func (x *SurveyRow) GetDowNumber() int32 {
+	if x != nil {
+		return x.DowNumber
+	}
+	return 0
+}
+
+func (x *SurveyRow) GetSurveyType() string {
+	if x != nil {
+		return x.SurveyType
+	}
+	return ""
+}
+
+func (x *SurveyRow) GetSurveySubType() string {
+	if x != nil {
+		return x.SurveySubType
+	}
+	return ""
+}
+
+func (x *SurveyRow) GetCountyName() string {
+	if x != nil {
+		return x.CountyName
+	}
+	return ""
+}
+
+func (x *SurveyRow) GetLakeName() string {
+	if x != nil {
+		return x.LakeName
+	}
+	return ""
+}
+
+func (x *SurveyRow) GetSurveyDate() string {
+	if x != nil {
+		return x.SurveyDate
+	}
+	return ""
+}
+
+func (x *SurveyRow) GetSpeciesName() string {
+	if x != nil {
+		return x.SpeciesName
+	}
+	return ""
+}
+
+func (x *SurveyRow) GetImageUrl() string {
+	if x != nil {
+		return x.ImageUrl
+	}
+	return ""
+}
+
+func (x *SurveyRow) GetNarrative() string {
+	if x != nil {
+		return x.Narrative
+	}
+	return ""
+}
+
+func (x *SurveyRow) GetMinLength() int32 {
+	if x != nil {
+		return x.MinLength
+	}
+	return 0
+}
+
+func (x *SurveyRow) GetMaxLength() int32 {
+	if x != nil {
+		return x.MaxLength
+	}
+	return 0
+}
+
+func (x *SurveyRow) GetTotalCatch() int32 {
+	if x != nil {
+		return x.TotalCatch
+	}
+	return 0
+}
+
+type GetSpeciesStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SpeciesId     string                 `protobuf:"bytes,1,opt,name=species_id,json=speciesId,proto3" json:"species_id,omitempty"`
+	State         string                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSpeciesStatsRequest) Reset() {
+	*x = GetSpeciesStatsRequest{}
+	mi := &file_fishreports_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSpeciesStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSpeciesStatsRequest) ProtoMessage() {}
+
+func (x *GetSpeciesStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fishreports_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSpeciesStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetSpeciesStatsRequest) Descriptor() ([]byte, []int) {
+	return file_fishreports_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetSpeciesStatsRequest) GetSpeciesId() string {
+	if x != nil {
+		return x.SpeciesId
+	}
+	return ""
+}
+
+func (x *GetSpeciesStatsRequest) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+type CountyPrevalence struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CountyId      string                 `protobuf:"bytes,1,opt,name=county_id,json=countyId,proto3" json:"county_id,omitempty"`
+	Percentage    int32                  `protobuf:"varint,2,opt,name=percentage,proto3" json:"percentage,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CountyPrevalence) Reset() {
+	*x = CountyPrevalence{}
+	mi := &file_fishreports_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CountyPrevalence) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountyPrevalence) ProtoMessage() {}
+
+func (x *CountyPrevalence) ProtoReflect() protoreflect.Message {
+	mi := &file_fishreports_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountyPrevalence.ProtoReflect.Descriptor instead.
+func (*CountyPrevalence) Descriptor() ([]byte, []int) {
+	return file_fishreports_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CountyPrevalence) GetCountyId() string {
+	if x != nil {
+		return x.CountyId
+	}
+	return ""
+}
+
+func (x *CountyPrevalence) GetPercentage() int32 {
+	if x != nil {
+		return x.Percentage
+	}
+	return 0
+}
+
+type SpeciesStats struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Species        string                 `protobuf:"bytes,1,opt,name=species,proto3" json:"species,omitempty"`
+	PercentLakes   int32                  `protobuf:"varint,2,opt,name=percent_lakes,json=percentLakes,proto3" json:"percent_lakes,omitempty"`
+	AverageLength  float64                `protobuf:"fixed64,3,opt,name=average_length,json=averageLength,proto3" json:"average_length,omitempty"`
+	BiggestLength  int32                  `protobuf:"varint,4,opt,name=biggest_length,json=biggestLength,proto3" json:"biggest_length,omitempty"`
+	ShortestLength int32                  `protobuf:"varint,5,opt,name=shortest_length,json=shortestLength,proto3" json:"shortest_length,omitempty"`
+	GraphData      []*FishCount           `protobuf:"bytes,6,rep,name=graph_data,json=graphData,proto3" json:"graph_data,omitempty"`
+	TotalFish      int32                  `protobuf:"varint,7,opt,name=total_fish,json=totalFish,proto3" json:"total_fish,omitempty"`
+	Counties       []*CountyPrevalence    `protobuf:"bytes,8,rep,name=counties,proto3" json:"counties,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SpeciesStats) Reset() {
+	*x = SpeciesStats{}
+	mi := &file_fishreports_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SpeciesStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SpeciesStats) ProtoMessage() {}
+
+func (x *SpeciesStats) ProtoReflect() protoreflect.Message {
+	mi := &file_fishreports_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SpeciesStats.ProtoReflect.Descriptor instead.
+func (*SpeciesStats) Descriptor() ([]byte, []int) {
+	return file_fishreports_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SpeciesStats) GetSpecies() string {
+	if x != nil {
+		return x.Species
+	}
+	return ""
+}
+
+func (x *SpeciesStats) GetPercentLakes() int32 {
+	if x != nil {
+		return x.PercentLakes
+	}
+	return 0
+}
+
+func (x *SpeciesStats) GetAverageLength() float64 {
+	if x != nil {
+		return x.AverageLength
+	}
+	return 0
+}
+
+func (x *SpeciesStats) GetBiggestLength() int32 {
+	if x != nil {
+		return x.BiggestLength
+	}
+	return 0
+}
+
+func (x *SpeciesStats) GetShortestLength() int32 {
+	if x != nil {
+		return x.ShortestLength
+	}
+	return 0
+}
+
+func (x *SpeciesStats) GetGraphData() []*FishCount {
+	if x != nil {
+		return x.GraphData
+	}
+	return nil
+}
+
+func (x *SpeciesStats) GetTotalFish() int32 {
+	if x != nil {
+		return x.TotalFish
+	}
+	return 0
+}
+
+func (x *SpeciesStats) GetCounties() []*CountyPrevalence {
+	if x != nil {
+		return x.Counties
+	}
+	return nil
+}
+
+type GetCountyStatsRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	CountyId string                 `protobuf:"bytes,1,opt,name=county_id,json=countyId,proto3" json:"county_id,omitempty"`
+	// When set, the county must be in this state.
+	State         string `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCountyStatsRequest) Reset() {
+	*x = GetCountyStatsRequest{}
+	mi := &file_fishreports_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCountyStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCountyStatsRequest) ProtoMessage() {}
+
+func (x *GetCountyStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fishreports_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCountyStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetCountyStatsRequest) Descriptor() ([]byte, []int) {
+	return file_fishreports_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetCountyStatsRequest) GetCountyId() string {
+	if x != nil {
+		return x.CountyId
+	}
+	return ""
+}
+
+func (x *GetCountyStatsRequest) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+type CountyStats struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	County          *County                `protobuf:"bytes,1,opt,name=county,proto3" json:"county,omitempty"`
+	NumberOfLakes   int32                  `protobuf:"varint,2,opt,name=number_of_lakes,json=numberOfLakes,proto3" json:"number_of_lakes,omitempty"`
+	SurveyIds       []string               `protobuf:"bytes,3,rep,name=survey_ids,json=surveyIds,proto3" json:"survey_ids,omitempty"`
+	TotalSurveys    int32                  `protobuf:"varint,4,opt,name=total_surveys,json=totalSurveys,proto3" json:"total_surveys,omitempty"`
+	TotalFishCaught int32                  `protobuf:"varint,5,opt,name=total_fish_caught,json=totalFishCaught,proto3" json:"total_fish_caught,omitempty"`
+	NumberOfSpecies int32                  `protobuf:"varint,6,opt,name=number_of_species,json=numberOfSpecies,proto3" json:"number_of_species,omitempty"`
+	// Percentage of the catch keyed by species ID.
+	SpeciesDistribution  map[string]float64 `protobuf:"bytes,7,rep,name=species_distribution,json=speciesDistribution,proto3" json:"species_distribution,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	AverageFishPerSurvey float64            `protobuf:"fixed64,8,opt,name=average_fish_per_survey,json=averageFishPerSurvey,proto3" json:"average_fish_per_survey,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *CountyStats) Reset() {
+	*x = CountyStats{}
+	mi := &file_fishreports_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CountyStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountyStats) ProtoMessage() {}
+
+func (x *CountyStats) ProtoReflect() protoreflect.Message {
+	mi := &file_fishreports_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountyStats.ProtoReflect.Descriptor instead.
+func (*CountyStats) Descriptor() ([]byte, []int) {
+	return file_fishreports_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *CountyStats) GetCounty() *County {
+	if x != nil {
+		return x.County
+	}
+	return nil
+}
+
+func (x *CountyStats) GetNumberOfLakes() int32 {
+	if x != nil {
+		return x.NumberOfLakes
+	}
+	return 0
+}
+
+func (x *CountyStats) GetSurveyIds() []string {
+	if x != nil {
+		return x.SurveyIds
+	}
+	return nil
+}
+
+func (x *CountyStats) GetTotalSurveys() int32 {
+	if x != nil {
+		return x.TotalSurveys
+	}
+	return 0
+}
+
+func (x *CountyStats) GetTotalFishCaught() int32 {
+	if x != nil {
+		return x.TotalFishCaught
+	}
+	return 0
+}
+
+func (x *CountyStats) GetNumberOfSpecies() int32 {
+	if x != nil {
+		return x.NumberOfSpecies
+	}
+	return 0
+}
+
+func (x *CountyStats) GetSpeciesDistribution() map[string]float64 {
+	if x != nil {
+		return x.SpeciesDistribution
+	}
+	return nil
+}
+
+func (x *CountyStats) GetAverageFishPerSurvey() float64 {
+	if x != nil {
+		return x.AverageFishPerSurvey
+	}
+	return 0
+}
+
+type GetLengthHistogramRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	DowNumber int32                  `protobuf:"varint,1,opt,name=dow_number,json=dowNumber,proto3" json:"dow_number,omitempty"`
+	// Species common name, as accepted by GET /graph.
+	Species       string `protobuf:"bytes,2,opt,name=species,proto3" json:"species,omitempty"`
+	SurveyDate    string `protobuf:"bytes,3,opt,name=survey_date,json=surveyDate,proto3" json:"survey_date,omitempty"`
+	State         string `protobuf:"bytes,4,opt,name=state,proto3" json:"state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLengthHistogramRequest) Reset() {
+	*x = GetLengthHistogramRequest{}
+	mi := &file_fishreports_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLengthHistogramRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLengthHistogramRequest) ProtoMessage() {}
+
+func (x *GetLengthHistogramRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fishreports_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLengthHistogramRequest.ProtoReflect.Descriptor instead.
+func (*GetLengthHistogramRequest) Descriptor() ([]byte, []int) {
+	return file_fishreports_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetLengthHistogramRequest) GetDowNumber() int32 {
+	if x != nil {
+		return x.DowNumber
+	}
+	return 0
+}
+
+func (x *GetLengthHistogramRequest) GetSpecies() string {
+	if x != nil {
+		return x.Species
+	}
+	return ""
+}
+
+func (x *GetLengthHistogramRequest) GetSurveyDate() string {
+	if x != nil {
+		return x.SurveyDate
+	}
+	return ""
+}
+
+func (x *GetLengthHistogramRequest) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+type LengthHistogram struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Species       string                 `protobuf:"bytes,1,opt,name=species,proto3" json:"species,omitempty"`
+	SurveyDate    string                 `protobuf:"bytes,2,opt,name=survey_date,json=surveyDate,proto3" json:"survey_date,omitempty"`
+	Data          []*FishCount           `protobuf:"bytes,3,rep,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LengthHistogram) Reset() {
+	*x = LengthHistogram{}
+	mi := &file_fishreports_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LengthHistogram) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LengthHistogram) ProtoMessage() {}
+
+func (x *LengthHistogram) ProtoReflect() protoreflect.Message {
+	mi := &file_fishreports_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LengthHistogram.ProtoReflect.Descriptor instead.
+func (*LengthHistogram) Descriptor() ([]byte, []int) {
+	return file_fishreports_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *LengthHistogram) GetSpecies() string {
+	if x != nil {
+		return x.Species
+	}
+	return ""
+}
+
+func (x *LengthHistogram) GetSurveyDate() string {
+	if x != nil {
+		return x.SurveyDate
+	}
+	return ""
+}
+
+func (x *LengthHistogram) GetData() []*FishCount {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type GetLakeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DowNumber     int32                  `protobuf:"varint,1,opt,name=dow_number,json=dowNumber,proto3" json:"dow_number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLakeRequest) Reset() {
+	*x = GetLakeRequest{}
+	mi := &file_fishreports_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLakeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLakeRequest) ProtoMessage() {}
+
+func (x *GetLakeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fishreports_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLakeRequest.ProtoReflect.Descriptor instead.
+func (*GetLakeRequest) Descriptor() ([]byte, []int) {
+	return file_fishreports_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetLakeRequest) GetDowNumber() int32 {
+	if x != nil {
+		return x.DowNumber
+	}
+	return 0
+}
+
+var File_fishreports_proto protoreflect.FileDescriptor
+
+const file_fishreports_proto_rawDesc = "" +
+	"\n" +
+	"\x11fishreports.proto\x12\x0efishreports.v1\"\xf8\x01\n" +
+	"\aSpecies\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\x12\x1f\n" +
+	"\vcommon_name\x18\x03 \x01(\tR\n" +
+	"commonName\x12'\n" +
+	"\x0fscientific_name\x18\x04 \x01(\tR\x0escientificName\x12\x1b\n" +
+	"\tgame_fish\x18\x05 \x01(\bR\bgameFish\x12#\n" +
+	"\rspecies_group\x18\x06 \x01(\tR\fspeciesGroup\x12\x1b\n" +
+	"\timage_url\x18\a \x01(\tR\bimageUrl\x12 \n" +
+	"\vdescription\x18\b \x01(\tR\vdescription\"?\n" +
+	"\tFishCount\x12\x16\n" +
+	"\x06length\x18\x01 \x01(\x05R\x06length\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\"\xc7\x01\n" +
+	"\n" +
+	"LengthData\x121\n" +
+	"\aspecies\x18\x01 \x01(\v2\x17.fishreports.v1.SpeciesR\aspecies\x12%\n" +
+	"\x0eminimum_length\x18\x02 \x01(\x05R\rminimumLength\x12%\n" +
+	"\x0emaximum_length\x18\x03 \x01(\x05R\rmaximumLength\x128\n" +
+	"\n" +
+	"fish_count\x18\x04 \x03(\v2\x19.fishreports.v1.FishCountR\tfishCount\"M\n" +
+	"\x10FishCatchSummary\x12\x18\n" +
+	"\aspecies\x18\x01 \x01(\tR\aspecies\x12\x1f\n" +
+	"\vtotal_catch\x18\x02 \x01(\x05R\n" +
+	"totalCatch\"\x98\x03\n" +
+	"\x06Survey\x12\x1b\n" +
+	"\tsurvey_id\x18\x01 \x01(\tR\bsurveyId\x12\x1f\n" +
+	"\vsurvey_date\x18\x02 \x01(\tR\n" +
+	"surveyDate\x12\x1f\n" +
+	"\vsurvey_type\x18\x03 \x01(\tR\n" +
+	"surveyType\x12&\n" +
+	"\x0fsurvey_sub_type\x18\x04 \x01(\tR\rsurveySubType\x12\x1c\n" +
+	"\tnarrative\x18\x05 \x01(\tR\tnarrative\x12R\n" +
+	"\x14fish_catch_summaries\x18\x06 \x03(\v2 .fishreports.v1.FishCatchSummaryR\x12fishCatchSummaries\x12=\n" +
+	"\alengths\x18\a \x03(\v2#.fishreports.v1.Survey.LengthsEntryR\alengths\x1aV\n" +
+	"\fLengthsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x120\n" +
+	"\x05value\x18\x02 \x01(\v2\x1a.fishreports.v1.LengthDataR\x05value:\x028\x01\"\xb2\x01\n" +
+	"\x04Lake\x12\x1d\n" +
+	"\n" +
+	"dow_number\x18\x01 \x01(\x05R\tdowNumber\x12\x1b\n" +
+	"\tlake_name\x18\x02 \x01(\tR\blakeName\x12\x1f\n" +
+	"\vcounty_name\x18\x03 \x01(\tR\n" +
+	"countyName\x12\x1b\n" +
+	"\tcounty_id\x18\x04 \x01(\tR\bcountyId\x120\n" +
+	"\asurveys\x18\x05 \x03(\v2\x16.fishreports.v1.SurveyR\asurveys\"\xcd\x02\n" +
+	"\x06County\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
+	"\vcounty_name\x18\x02 \x01(\tR\n" +
+	"countyName\x12\x1b\n" +
+	"\tfips_code\x18\x03 \x01(\tR\bfipsCode\x12\x1f\n" +
+	"\vcounty_seat\x18\x04 \x01(\tR\n" +
+	"countySeat\x12 \n" +
+	"\vestablished\x18\x05 \x01(\x05R\vestablished\x12\x16\n" +
+	"\x06origin\x18\x06 \x01(\tR\x06origin\x12\x1c\n" +
+	"\tetymology\x18\a \x01(\tR\tetymology\x12\x1e\n" +
+	"\n" +
+	"population\x18\b \x01(\x05R\n" +
+	"population\x12\"\n" +
+	"\rarea_sq_miles\x18\t \x01(\x01R\vareaSqMiles\x12\"\n" +
+	"\rmap_image_url\x18\n" +
+	" \x01(\tR\vmapImageUrl\x12\x14\n" +
+	"\x05lakes\x18\v \x03(\tR\x05lakes\"\xd4\x02\n" +
+	"\x12ListSurveysRequest\x12\x1f\n" +
+	"\vspecies_ids\x18\x01 \x03(\tR\n" +
+	"speciesIds\x12\x19\n" +
+	"\bmin_year\x18\x02 \x01(\tR\aminYear\x12\x19\n" +
+	"\bmax_year\x18\x03 \x01(\tR\amaxYear\x12\x1d\n" +
+	"\n" +
+	"county_ids\x18\x04 \x03(\tR\tcountyIds\x12\x14\n" +
+	"\x05lakes\x18\x05 \x03(\tR\x05lakes\x12\x17\n" +
+	"\asort_by\x18\x06 \x01(\tR\x06sortBy\x12\x14\n" +
+	"\x05order\x18\a \x01(\tR\x05order\x12$\n" +
+	"\x0egame_fish_only\x18\b \x01(\bR\fgameFishOnly\x12\x16\n" +
+	"\x06search\x18\t \x01(\tR\x06search\x12\x1b\n" +
+	"\tpage_size\x18\n" +
+	" \x01(\x05R\bpageSize\x12\x12\n" +
+	"\x04page\x18\v \x01(\x05R\x04page\x12\x14\n" +
+	"\x05state\x18\f \x01(\tR\x05state\"\xac\x03\n" +
+	"\tSurveyRow\x12\x1b\n" +
+	"\tsurvey_id\x18\x01 \x01(\tR\bsurveyId\x12\x1d\n" +
+	"\n" +
+	"dow_number\x18\x02 \x01(\x05R\tdowNumber\x12\x1f\n" +
+	"\vsurvey_type\x18\x03 \x01(\tR\n" +
+	"surveyType\x12&\n" +
+	"\x0fsurvey_sub_type\x18\x04 \x01(\tR\rsurveySubType\x12\x1f\n" +
+	"\vcounty_name\x18\x05 \x01(\tR\n" +
+	"countyName\x12\x1b\n" +
+	"\tlake_name\x18\x06 \x01(\tR\blakeName\x12\x1f\n" +
+	"\vsurvey_date\x18\a \x01(\tR\n" +
+	"surveyDate\x12!\n" +
+	"\fspecies_name\x18\b \x01(\tR\vspeciesName\x12\x1b\n" +
+	"\timage_url\x18\t \x01(\tR\bimageUrl\x12\x1c\n" +
+	"\tnarrative\x18\n" +
+	" \x01(\tR\tnarrative\x12\x1d\n" +
+	"\n" +
+	"min_length\x18\v \x01(\x05R\tminLength\x12\x1d\n" +
+	"\n" +
+	"max_length\x18\f \x01(\x05R\tmaxLength\x12\x1f\n" +
+	"\vtotal_catch\x18\r \x01(\x05R\n" +
+	"totalCatch\"M\n" +
+	"\x16GetSpeciesStatsRequest\x12\x1d\n" +
+	"\n" +
+	"species_id\x18\x01 \x01(\tR\tspeciesId\x12\x14\n" +
+	"\x05state\x18\x02 \x01(\tR\x05state\"O\n" +
+	"\x10CountyPrevalence\x12\x1b\n" +
+	"\tcounty_id\x18\x01 \x01(\tR\bcountyId\x12\x1e\n" +
+	"\n" +
+	"percentage\x18\x02 \x01(\x05R\n" +
+	"percentage\"\xdb\x02\n" +
+	"\fSpeciesStats\x12\x18\n" +
+	"\aspecies\x18\x01 \x01(\tR\aspecies\x12#\n" +
+	"\rpercent_lakes\x18\x02 \x01(\x05R\fpercentLakes\x12%\n" +
+	"\x0eaverage_length\x18\x03 \x01(\x01R\raverageLength\x12%\n" +
+	"\x0ebiggest_length\x18\x04 \x01(\x05R\rbiggestLength\x12'\n" +
+	"\x0fshortest_length\x18\x05 \x01(\x05R\x0eshortestLength\x128\n" +
+	"\n" +
+	"graph_data\x18\x06 \x03(\v2\x19.fishreports.v1.FishCountR\tgraphData\x12\x1d\n" +
+	"\n" +
+	"total_fish\x18\a \x01(\x05R\ttotalFish\x12<\n" +
+	"\bcounties\x18\b \x03(\v2 .fishreports.v1.CountyPrevalenceR\bcounties\"J\n" +
+	"\x15GetCountyStatsRequest\x12\x1b\n" +
+	"\tcounty_id\x18\x01 \x01(\tR\bcountyId\x12\x14\n" +
+	"\x05state\x18\x02 \x01(\tR\x05state\"\xe9\x03\n" +
+	"\vCountyStats\x12.\n" +
+	"\x06county\x18\x01 \x01(\v2\x16.fishreports.v1.CountyR\x06county\x12&\n" +
+	"\x0fnumber_of_lakes\x18\x02 \x01(\x05R\rnumberOfLakes\x12\x1d\n" +
+	"\n" +
+	"survey_ids\x18\x03 \x03(\tR\tsurveyIds\x12#\n" +
+	"\rtotal_surveys\x18\x04 \x01(\x05R\ftotalSurveys\x12*\n" +
+	"\x11total_fish_caught\x18\x05 \x01(\x05R\x0ftotalFishCaught\x12*\n" +
+	"\x11number_of_species\x18\x06 \x01(\x05R\x0fnumberOfSpecies\x12g\n" +
+	"\x14species_distribution\x18\a \x03(\v24.fishreports.v1.CountyStats.SpeciesDistributionEntryR\x13speciesDistribution\x125\n" +
+	"\x17average_fish_per_survey\x18\b \x01(\x01R\x14averageFishPerSurvey\x1aF\n" +
+	"\x18SpeciesDistributionEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"\x8b\x01\n" +
+	"\x19GetLengthHistogramRequest\x12\x1d\n" +
+	"\n" +
+	"dow_number\x18\x01 \x01(\x05R\tdowNumber\x12\x18\n" +
+	"\aspecies\x18\x02 \x01(\tR\aspecies\x12\x1f\n" +
+	"\vsurvey_date\x18\x03 \x01(\tR\n" +
+	"surveyDate\x12\x14\n" +
+	"\x05state\x18\x04 \x01(\tR\x05state\"{\n" +
+	"\x0fLengthHistogram\x12\x18\n" +
+	"\aspecies\x18\x01 \x01(\tR\aspecies\x12\x1f\n" +
+	"\vsurvey_date\x18\x02 \x01(\tR\n" +
+	"surveyDate\x12-\n" +
+	"\x04data\x18\x03 \x03(\v2\x19.fishreports.v1.FishCountR\x04data\"/\n" +
+	"\x0eGetLakeRequest\x12\x1d\n" +
+	"\n" +
+	"dow_number\x18\x01 \x01(\x05R\tdowNumber2\xaf\x03\n" +
+	"\vFishReports\x12N\n" +
+	"\vListSurveys\x12\".fishreports.v1.ListSurveysRequest\x1a\x19.fishreports.v1.SurveyRow0\x01\x12W\n" +
+	"\x0fGetSpeciesStats\x12&.fishreports.v1.GetSpeciesStatsRequest\x1a\x1c.fishreports.v1.SpeciesStats\x12T\n" +
+	"\x0eGetCountyStats\x12%.fishreports.v1.GetCountyStatsRequest\x1a\x1b.fishreports.v1.CountyStats\x12`\n" +
+	"\x12GetLengthHistogram\x12).fishreports.v1.GetLengthHistogramRequest\x1a\x1f.fishreports.v1.LengthHistogram\x12?\n" +
+	"\aGetLake\x12\x1e.fishreports.v1.GetLakeRequest\x1a\x14.fishreports.v1.LakeB\x13Z\x11fishreports/pb;pbb\x06proto3"
+
+var (
+	file_fishreports_proto_rawDescOnce sync.Once
+	file_fishreports_proto_rawDescData []byte
+)
+
+func file_fishreports_proto_rawDescGZIP() []byte {
+	file_fishreports_proto_rawDescOnce.Do(func() {
+		file_fishreports_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_fishreports_proto_rawDesc), len(file_fishreports_proto_rawDesc)))
+	})
+	return file_fishreports_proto_rawDescData
+}
+
+var file_fishreports_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
+var file_fishreports_proto_goTypes = []any{
+	(*Species)(nil),                   // 0: fishreports.v1.Species
+	(*FishCount)(nil),                 // 1: fishreports.v1.FishCount
+	(*LengthData)(nil),                // 2: fishreports.v1.LengthData
+	(*FishCatchSummary)(nil),          // 3: fishreports.v1.FishCatchSummary
+	(*Survey)(nil),                    // 4: fishreports.v1.Survey
+	(*Lake)(nil),                      // 5: fishreports.v1.Lake
+	(*County)(nil),                    // 6: fishreports.v1.County
+	(*ListSurveysRequest)(nil),        // 7: fishreports.v1.ListSurveysRequest
+	(*SurveyRow)(nil),                 // 8: fishreports.v1.SurveyRow
+	(*GetSpeciesStatsRequest)(nil),    // 9: fishreports.v1.GetSpeciesStatsRequest
+	(*CountyPrevalence)(nil),          // 10: fishreports.v1.CountyPrevalence
+	(*SpeciesStats)(nil),              // 11: fishreports.v1.SpeciesStats
+	(*GetCountyStatsRequest)(nil),     // 12: fishreports.v1.GetCountyStatsRequest
+	(*CountyStats)(nil),               // 13: fishreports.v1.CountyStats
+	(*GetLengthHistogramRequest)(nil), // 14: fishreports.v1.GetLengthHistogramRequest
+	(*LengthHistogram)(nil),           // 15: fishreports.v1.LengthHistogram
+	(*GetLakeRequest)(nil),            // 16: fishreports.v1.GetLakeRequest
+	nil,                               // 17: fishreports.v1.Survey.LengthsEntry
+	nil,                               // 18: fishreports.v1.CountyStats.SpeciesDistributionEntry
+}
+var file_fishreports_proto_depIdxs = []int32{
+	0,  // 0: fishreports.v1.LengthData.species:type_name -> fishreports.v1.Species
+	1,  // 1: fishreports.v1.LengthData.fish_count:type_name -> fishreports.v1.FishCount
+	3,  // 2: fishreports.v1.Survey.fish_catch_summaries:type_name -> fishreports.v1.FishCatchSummary
+	17, // 3: fishreports.v1.Survey.lengths:type_name -> fishreports.v1.Survey.LengthsEntry
+	4,  // 4: fishreports.v1.Lake.surveys:type_name -> fishreports.v1.Survey
+	1,  // 5: fishreports.v1.SpeciesStats.graph_data:type_name -> fishreports.v1.FishCount
+	10, // 6: fishreports.v1.SpeciesStats.counties:type_name -> fishreports.v1.CountyPrevalence
+	6,  // 7: fishreports.v1.CountyStats.county:type_name -> fishreports.v1.County
+	18, // 8: fishreports.v1.CountyStats.species_distribution:type_name -> fishreports.v1.CountyStats.SpeciesDistributionEntry
+	1,  // 9: fishreports.v1.LengthHistogram.data:type_name -> fishreports.v1.FishCount
+	2,  // 10: fishreports.v1.Survey.LengthsEntry.value:type_name -> fishreports.v1.LengthData
+	7,  // 11: fishreports.v1.FishReports.ListSurveys:input_type -> fishreports.v1.ListSurveysRequest
+	9,  // 12: fishreports.v1.FishReports.GetSpeciesStats:input_type -> fishreports.v1.GetSpeciesStatsRequest
+	12, // 13: fishreports.v1.FishReports.GetCountyStats:input_type -> fishreports.v1.GetCountyStatsRequest
+	14, // 14: fishreports.v1.FishReports.GetLengthHistogram:input_type -> fishreports.v1.GetLengthHistogramRequest
+	16, // 15: fishreports.v1.FishReports.GetLake:input_type -> fishreports.v1.GetLakeRequest
+	8,  // 16: fishreports.v1.FishReports.ListSurveys:output_type -> fishreports.v1.SurveyRow
+	11, // 17: fishreports.v1.FishReports.GetSpeciesStats:output_type -> fishreports.v1.SpeciesStats
+	13, // 18: fishreports.v1.FishReports.GetCountyStats:output_type -> fishreports.v1.CountyStats
+	15, // 19: fishreports.v1.FishReports.GetLengthHistogram:output_type -> fishreports.v1.LengthHistogram
+	5,  // 20: fishreports.v1.FishReports.GetLake:output_type -> fishreports.v1.Lake
+	16, // [16:21] is the sub-list for method output_type
+	11, // [11:16] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_fishreports_proto_init() }
+func file_fishreports_proto_init() {
+	if File_fishreports_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_fishreports_proto_rawDesc), len(file_fishreports_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   19,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_fishreports_proto_goTypes,
+		DependencyIndexes: file_fishreports_proto_depIdxs,
+		MessageInfos:      file_fishreports_proto_msgTypes,
+	}.Build()
+	File_fishreports_proto = out.File
+	file_fishreports_proto_goTypes = nil
+	file_fishreports_proto_depIdxs = nil
+}
@@ -0,0 +1,299 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: fishreports.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	FishReports_ListSurveys_FullMethodName        = "/fishreports.v1.FishReports/ListSurveys"
+	FishReports_GetSpeciesStats_FullMethodName    = "/fishreports.v1.FishReports/GetSpeciesStats"
+	FishReports_GetCountyStats_FullMethodName     = "/fishreports.v1.FishReports/GetCountyStats"
+	FishReports_GetLengthHistogram_FullMethodName = "/fishreports.v1.FishReports/GetLengthHistogram"
+	FishReports_GetLake_FullMethodName            = "/fishreports.v1.FishReports/GetLake"
+)
+
+// FishReportsClient is the client API for FishReports service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// FishReports exposes the survey data served by the REST API to backend
+// services. Every call requires an API key in the "x-api-key" metadata.
+type FishReportsClient interface {
+	// ListSurveys streams one row per survey and species, using the same
+	// filters, sort and pagination as GET /surveys. A page_size of 0 streams
+	// every matching row.
+	ListSurveys(ctx context.Context, in *ListSurveysRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SurveyRow], error)
+	// GetSpeciesStats returns the statewide stats for one species.
+	GetSpeciesStats(ctx context.Context, in *GetSpeciesStatsRequest, opts ...grpc.CallOption) (*SpeciesStats, error)
+	// GetCountyStats returns the survey stats for one county.
+	GetCountyStats(ctx context.Context, in *GetCountyStatsRequest, opts ...grpc.CallOption) (*CountyStats, error)
+	// GetLengthHistogram returns the length frequency for one species in one
+	// survey of one lake.
+	GetLengthHistogram(ctx context.Context, in *GetLengthHistogramRequest, opts ...grpc.CallOption) (*LengthHistogram, error)
+	// GetLake returns a lake with all of its surveys.
+	GetLake(ctx context.Context, in *GetLakeRequest, opts ...grpc.CallOption) (*Lake, error)
+}
+
+type fishReportsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFishReportsClient(cc grpc.ClientConnInterface) FishReportsClient {
+	return &fishReportsClient{cc}
+}
+
+func (c *fishReportsClient) ListSurveys(ctx context.Context, in *ListSurveysRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SurveyRow], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &FishReports_ServiceDesc.Streams[0], FishReports_ListSurveys_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListSurveysRequest, SurveyRow]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FishReports_ListSurveysClient = grpc.ServerStreamingClient[SurveyRow]
+
+func (c *fishReportsClient) GetSpeciesStats(ctx context.Context, in *GetSpeciesStatsRequest, opts ...grpc.CallOption) (*SpeciesStats, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SpeciesStats)
+	err := c.cc.Invoke(ctx, FishReports_GetSpeciesStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fishReportsClient) GetCountyStats(ctx context.Context, in *GetCountyStatsRequest, opts ...grpc.CallOption) (*CountyStats, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CountyStats)
+	err := c.cc.Invoke(ctx, FishReports_GetCountyStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fishReportsClient) GetLengthHistogram(ctx context.Context, in *GetLengthHistogramRequest, opts ...grpc.CallOption) (*LengthHistogram, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LengthHistogram)
+	err := c.cc.Invoke(ctx, FishReports_GetLengthHistogram_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fishReportsClient) GetLake(ctx context.Context, in *GetLakeRequest, opts ...grpc.CallOption) (*Lake, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Lake)
+	err := c.cc.Invoke(ctx, FishReports_GetLake_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FishReportsServer is the server API for FishReports service.
+// All implementations must embed UnimplementedFishReportsServer
+// for forward compatibility.
+//
+// FishReports exposes the survey data served by the REST API to backend
+// services. Every call requires an API key in the "x-api-key" metadata.
+type FishReportsServer interface {
+	// ListSurveys streams one row per survey and species, using the same
+	// filters, sort and pagination as GET /surveys. A page_size of 0 streams
+	// every matching row.
+	ListSurveys(*ListSurveysRequest, grpc.ServerStreamingServer[SurveyRow]) error
+	// GetSpeciesStats returns the statewide stats for one species.
+	GetSpeciesStats(context.Context, *GetSpeciesStatsRequest) (*SpeciesStats, error)
+	// GetCountyStats returns the survey stats for one county.
+	GetCountyStats(context.Context, *GetCountyStatsRequest) (*CountyStats, error)
+	// GetLengthHistogram returns the length frequency for one species in one
+	// survey of one lake.
+	GetLengthHistogram(context.Context, *GetLengthHistogramRequest) (*LengthHistogram, error)
+	// GetLake returns a lake with all of its surveys.
+	GetLake(context.Context, *GetLakeRequest) (*Lake, error)
+	mustEmbedUnimplementedFishReportsServer()
+}
+
+// UnimplementedFishReportsServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedFishReportsServer struct{}
+
+func (UnimplementedFishReportsServer) ListSurveys(*ListSurveysRequest, grpc.ServerStreamingServer[SurveyRow]) error {
+	return status.Errorf(codes.Unimplemented, "method ListSurveys not implemented")
+}
+func (UnimplementedFishReportsServer) GetSpeciesStats(context.Context, *GetSpeciesStatsRequest) (*SpeciesStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSpeciesStats not implemented")
+}
+func (UnimplementedFishReportsServer) GetCountyStats(context.Context, *GetCountyStatsRequest) (*CountyStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCountyStats not implemented")
+}
+func (UnimplementedFishReportsServer) GetLengthHistogram(context.Context, *GetLengthHistogramRequest) (*LengthHistogram, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLengthHistogram not implemented")
+}
+func (UnimplementedFishReportsServer) GetLake(context.Context, *GetLakeRequest) (*Lake, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLake not implemented")
+}
+func (UnimplementedFishReportsServer) mustEmbedUnimplementedFishReportsServer() {}
+func (UnimplementedFishReportsServer) testEmbeddedByValue()                     {}
+
+// UnsafeFishReportsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FishReportsServer will
+// result in compilation errors.
+type UnsafeFishReportsServer interface {
+	mustEmbedUnimplementedFishReportsServer()
+}
+
+func RegisterFishReportsServer(s grpc.ServiceRegistrar, srv FishReportsServer) {
+	// If the following call pancis, it indicates UnimplementedFishReportsServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&FishReports_ServiceDesc, srv)
+}
+
+func _FishReports_ListSurveys_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListSurveysRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FishReportsServer).ListSurveys(m, &grpc.GenericServerStream[ListSurveysRequest, SurveyRow]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FishReports_ListSurveysServer = grpc.ServerStreamingServer[SurveyRow]
+
+func _FishReports_GetSpeciesStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSpeciesStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FishReportsServer).GetSpeciesStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FishReports_GetSpeciesStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FishReportsServer).GetSpeciesStats(ctx, req.(*GetSpeciesStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FishReports_GetCountyStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCountyStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FishReportsServer).GetCountyStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FishReports_GetCountyStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FishReportsServer).GetCountyStats(ctx, req.(*GetCountyStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FishReports_GetLengthHistogram_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLengthHistogramRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FishReportsServer).GetLengthHistogram(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FishReports_GetLengthHistogram_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FishReportsServer).GetLengthHistogram(ctx, req.(*GetLengthHistogramRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FishReports_GetLake_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLakeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FishReportsServer).GetLake(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FishReports_GetLake_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FishReportsServer).GetLake(ctx, req.(*GetLakeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// FishReports_ServiceDesc is the grpc.ServiceDesc for FishReports service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var FishReports_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "fishreports.v1.FishReports",
+	HandlerType: (*FishReportsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetSpeciesStats",
+			Handler:    _FishReports_GetSpeciesStats_Handler,
+		},
+		{
+			MethodName: "GetCountyStats",
+			Handler:    _FishReports_GetCountyStats_Handler,
+		},
+		{
+			MethodName: "GetLengthHistogram",
+			Handler:    _FishReports_GetLengthHistogram_Handler,
+		},
+		{
+			MethodName: "GetLake",
+			Handler:    _FishReports_GetLake_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListSurveys",
+			Handler:       _FishReports_ListSurveys_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "fishreports.proto",
+}
@@ -0,0 +1,5 @@
+// Package pb holds the protobuf schema and generated gRPC bindings for the
+// FishReports API.
+package pb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative fishreports.proto
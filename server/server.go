@@ -0,0 +1,170 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+
+	"fishreports/config"
+)
+
+// Run serves handler on the configured port, using plain HTTP, TLS with
+// certificate files, or ACME-managed certificates depending on cfg.TLS.
+func Run(handler http.Handler, cfg *config.Config) error {
+	srv := &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	switch {
+	case cfg.TLS.Autocert.Enabled:
+		manager, err := newAutocertManager(cfg.TLS.Autocert)
+		if err != nil {
+			return err
+		}
+		srv.TLSConfig = manager.TLSConfig()
+
+		// HTTP-01 challenges and HTTPS redirects on the plain HTTP port.
+		go func() {
+			challengeAddr := ":" + cfg.TLS.Autocert.HTTPPort
+			log.Printf("ACME challenge server running on port %s...", cfg.TLS.Autocert.HTTPPort)
+			if err := http.ListenAndServe(challengeAddr, manager.HTTPHandler(nil)); err != nil {
+				log.Printf("❌ ACME challenge server stopped: %v", err)
+			}
+		}()
+
+		log.Printf("Server running with ACME certificates on port %s...", cfg.Port)
+		return srv.ListenAndServeTLS("", "")
+
+	case cfg.TLS.Enabled:
+		reloader, err := NewCertReloader(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		if err != nil {
+			return err
+		}
+		interval := time.Duration(cfg.TLS.ReloadIntervalSeconds) * time.Second
+		if interval > 0 {
+			go reloader.Watch(interval)
+		}
+		srv.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: reloader.GetCertificate,
+		}
+
+		log.Printf("Server running with TLS on port %s...", cfg.Port)
+		return srv.ListenAndServeTLS("", "")
+
+	default:
+		log.Printf("Server running on port %s...", cfg.Port)
+		return srv.ListenAndServe()
+	}
+}
+
+// newAutocertManager builds an ACME certificate manager restricted to the
+// configured domains.
+func newAutocertManager(cfg config.AutocertConfig) (*autocert.Manager, error) {
+	if len(cfg.Domains) == 0 {
+		return nil, fmt.Errorf("autocert is enabled but no domains are configured")
+	}
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(cfg.Domains...),
+		Cache:      autocert.DirCache(cfg.CacheDir),
+		Email:      cfg.Email,
+	}
+	if cfg.Directory != "" {
+		manager.Client = &acme.Client{DirectoryURL: cfg.Directory}
+	}
+	return manager, nil
+}
+
+// CertReloader serves a certificate loaded from disk and reloads it when the
+// certificate or key file changes, so renewed certificates are picked up
+// without a restart.
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	cert     *tls.Certificate
+	modTime  time.Time
+	mu       sync.RWMutex
+}
+
+// NewCertReloader loads the initial certificate pair.
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the certificate pair from disk.
+func (r *CertReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.cert = &cert
+	r.modTime = modTime
+	r.mu.Unlock()
+	return nil
+}
+
+// Watch polls the certificate files and reloads them when they change.
+func (r *CertReloader) Watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		modTime, err := r.latestModTime()
+		if err != nil {
+			log.Printf("❌ Failed to stat TLS certificate: %v", err)
+			continue
+		}
+		r.mu.RLock()
+		changed := modTime.After(r.modTime)
+		r.mu.RUnlock()
+		if !changed {
+			continue
+		}
+		if err := r.Reload(); err != nil {
+			// Keep serving the previous certificate.
+			log.Printf("❌ Failed to reload TLS certificate: %v", err)
+			continue
+		}
+		log.Printf("✅ Reloaded TLS certificate from %s", r.certFile)
+	}
+}
+
+// GetCertificate implements tls.Config.GetCertificate.
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// latestModTime returns the newer of the certificate and key modification times.
+func (r *CertReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, path := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,78 @@
+package view
+
+import (
+	"errors"
+	"fishreports/controller"
+	"fishreports/model"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupAdminRoutes registers the /admin routes. They require an API key with
+// the admin scope and return the group so other admin features can extend it.
+func SetupAdminRoutes(router *gin.Engine, keyController *controller.APIKeyController, reloader *controller.DataReloader) *gin.RouterGroup {
+	admin := router.Group("/admin", RequireScope(keyController, model.ScopeAdmin))
+
+	// List the configured API keys with their secrets masked.
+	admin.GET("/keys", func(c *gin.Context) {
+		if keyController == nil {
+			c.JSON(http.StatusOK, gin.H{"auth_enabled": false, "data": []interface{}{}})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"auth_enabled": true, "data": keyController.ListKeys()})
+	})
+
+	// Reload the survey data from disk and report the surveys that are new.
+	admin.POST("/reload", func(c *gin.Context) {
+		result, err := reloader.Reload(c.Request.Context())
+		if err != nil {
+			respondControllerError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	})
+
+	// The added, removed and modified surveys found by the most recent reload,
+	// ingest or startup comparison with the previous run.
+	admin.GET("/diff/latest", func(c *gin.Context) {
+		diff := reloader.LatestDiff()
+		if diff == nil {
+			respondError(c, http.StatusNotFound, "No data load has been compared yet")
+			return
+		}
+		c.JSON(http.StatusOK, diff)
+	})
+
+	// Ingest survey documents in a state's format (?state=, default MN): one
+	// JSON object or NDJSON.
+	admin.POST("/surveys", func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				respondError(c, http.StatusRequestEntityTooLarge, "Request body too large")
+				return
+			}
+			respondError(c, http.StatusBadRequest, "Failed to read request body")
+			return
+		}
+		report, err := reloader.Ingest(c.Request.Context(), body, c.Query("state"))
+		if errors.Is(err, controller.ErrInvalidIngest) {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err != nil {
+			respondControllerError(c, err)
+			return
+		}
+		status := http.StatusOK
+		if report.Lakes == 0 {
+			status = http.StatusUnprocessableEntity
+		}
+		c.JSON(status, report)
+	})
+
+	return admin
+}
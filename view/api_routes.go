@@ -1,7 +1,9 @@
 package view
 
 import (
+	"errors"
 	"fishreports/controller"
+	"fishreports/model"
 
 	"net/http"
 	"strconv"
@@ -9,11 +11,81 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// maxPublicLimit caps the /surveys page size for keys without the export scope.
+const maxPublicLimit = 500
+
+// stateQuery reads the optional state filter, responding 400 for a state
+// that isn't configured.
+func stateQuery(c *gin.Context, fishController *controller.FishSurveyController) (string, bool) {
+	state := c.Query("state")
+	if state == "" {
+		return "", true
+	}
+	if _, exists := fishController.FindState(state); !exists {
+		respondError(c, http.StatusBadRequest, "Unknown state: "+state)
+		return "", false
+	}
+	return model.NormalizeState(state), true
+}
+
+// histogramQuery reads the bin, unit, mode and density parameters of the
+// length histograms, responding 400 for unsupported values.
+func histogramQuery(c *gin.Context) (controller.HistogramOptions, bool) {
+	opts, err := controller.ParseHistogramOptions(c.Query("bin"), c.Query("unit"), c.Query("mode"), c.Query("density"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return opts, false
+	}
+	return opts, true
+}
+
+// communityQuery reads the counties, minYear, maxYear and dow filters of the
+// community analytics, responding 400 for values that aren't numbers.
+func communityQuery(c *gin.Context) (controller.CommunityFilters, bool) {
+	filters := controller.CommunityFilters{Counties: c.QueryArray("counties")}
+	for name, target := range map[string]*int{"minYear": &filters.MinYear, "maxYear": &filters.MaxYear, "dow": &filters.DOW} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid "+name+": "+value)
+			return filters, false
+		}
+		*target = number
+	}
+	return filters, true
+}
+
+// yearRangeQuery reads the optional minYear and maxYear parameters,
+// responding 400 for values that aren't numbers.
+func yearRangeQuery(c *gin.Context) (minYear, maxYear int, ok bool) {
+	for name, target := range map[string]*int{"minYear": &minYear, "maxYear": &maxYear} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid "+name+": "+value)
+			return 0, 0, false
+		}
+		*target = number
+	}
+	return minYear, maxYear, true
+}
+
 // ✅ Setup API routes
-func SetupRoutes(router *gin.Engine, fishController *controller.FishSurveyController, countyController *controller.CountyController) {
+func SetupRoutes(router *gin.Engine, fishController *controller.FishSurveyController, countyController *controller.CountyController, keyController *controller.APIKeyController) {
+	// Every data route requires an API key with public read access.
+	public := router.Group("", RequireScope(keyController, model.ScopePublicRead))
 
-	
-	router.GET("/surveys", func(c *gin.Context) {
+	router.NoRoute(func(c *gin.Context) {
+		respondError(c, http.StatusNotFound, "Route not found")
+	})
+
+	public.GET("/surveys", func(c *gin.Context) {
 		// Expect species and county IDs instead of names.
 		species := c.QueryArray("species") // species IDs
 		minYear := c.Query("minYear")
@@ -28,15 +100,36 @@ func SetupRoutes(router *gin.Engine, fishController *controller.FishSurveyContro
 		// New query parameter for game fish
 		gameFishStr := c.DefaultQuery("game_fish", "false")
 		gameFishOnly, _ := strconv.ParseBool(gameFishStr)
+		invasiveOnly, _ := strconv.ParseBool(c.DefaultQuery("invasive", "false"))
 
 		limit, _ := strconv.Atoi(limitStr)
 		page, _ := strconv.Atoi(pageStr)
+		if limit < 1 {
+			limit = 1
+		}
+		if page < 1 {
+			page = 1
+		}
+		// Bulk pulls are reserved for keys with the export scope.
+		if limit > maxPublicLimit && !hasScope(c, model.ScopeExport) {
+			limit = maxPublicLimit
+		}
+
+		state, ok := stateQuery(c, fishController)
+		if !ok {
+			return
+		}
 
 		// Pass the parameters to the controller.
-		filteredData := fishController.FilterAndSortData(
+		filteredData, err := fishController.ForState(state).FilterAndSortDataContext(
+			c.Request.Context(),
 			species, minYear, maxYear, counties, lakes,
-			sortBy, order, gameFishOnly, search, limit, page,
+			sortBy, order, gameFishOnly, invasiveOnly, search, limit, page,
 		)
+		if err != nil {
+			respondControllerError(c, err)
+			return
+		}
 
 		c.JSON(http.StatusOK, gin.H{
 			"data":      filteredData["data"],
@@ -49,59 +142,353 @@ func SetupRoutes(router *gin.Engine, fishController *controller.FishSurveyContro
 	})
 
 
-	router.GET("/graph", func(c *gin.Context) {
+	public.GET("/graph", func(c *gin.Context) {
 		dowNumber := c.Query("dow")
 		speciesName := c.Query("species")
 		surveyDate := c.Query("date")
 
 		if dowNumber == "" || speciesName == "" || surveyDate == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing request query parameters: dow, species, or date"})
+			respondError(c, http.StatusBadRequest, "Missing request query parameters: dow, species, or date")
+			return
+		}
+
+		state, ok := stateQuery(c, fishController)
+		if !ok {
+			return
+		}
+		opts, ok := histogramQuery(c)
+		if !ok {
 			return
 		}
 
-		graphData := fishController.GetFishCountData(dowNumber, speciesName, surveyDate)
+		graphData, err := fishController.ForState(state).GetFishCountDataWithOptions(c.Request.Context(), dowNumber, speciesName, surveyDate, opts)
+		if err != nil {
+			respondControllerError(c, err)
+			return
+		}
 		if graphData == nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "No data found for the specified parameters"})
+			respondError(c, http.StatusNotFound, "No data found for the specified parameters")
 			return
 		}
 
 		c.JSON(http.StatusOK, graphData)
 	})
 
-	router.GET("/counties", func(c *gin.Context) {
+	// Length histograms of one species in a lake, per survey year and combined.
+	public.GET("/lakes/:dow/histogram", func(c *gin.Context) {
+		dow, err := strconv.Atoi(c.Param("dow"))
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid DOW number")
+			return
+		}
+		speciesName := c.Query("species")
+		if speciesName == "" {
+			respondError(c, http.StatusBadRequest, "Missing request query parameter: species")
+			return
+		}
+		minYear, maxYear, ok := yearRangeQuery(c)
+		if !ok {
+			return
+		}
+		state, ok := stateQuery(c, fishController)
+		if !ok {
+			return
+		}
+		opts, ok := histogramQuery(c)
+		if !ok {
+			return
+		}
+
+		histogram, err := fishController.ForState(state).GetLakeHistogramContext(c.Request.Context(), dow, speciesName, minYear, maxYear, opts)
+		if err != nil {
+			respondControllerError(c, err)
+			return
+		}
+		if histogram == nil {
+			respondError(c, http.StatusNotFound, "No length data found for the lake and species")
+			return
+		}
+		c.JSON(http.StatusOK, histogram)
+	})
+
+	// Cohorts (likely year classes) in one survey's length frequencies of a species.
+	public.GET("/surveys/:surveyID/species/:species_id/cohorts", func(c *gin.Context) {
+		cohorts, maxCohorts := 0, 0
+		var err error
+		if value := c.Query("cohorts"); value != "" {
+			if cohorts, err = strconv.Atoi(value); err != nil || cohorts < 1 {
+				respondError(c, http.StatusBadRequest, "cohorts must be a positive whole number")
+				return
+			}
+		}
+		if value := c.Query("max_cohorts"); value != "" {
+			if maxCohorts, err = strconv.Atoi(value); err != nil || maxCohorts < 1 {
+				respondError(c, http.StatusBadRequest, "max_cohorts must be a positive whole number")
+				return
+			}
+		}
+		state, ok := stateQuery(c, fishController)
+		if !ok {
+			return
+		}
+
+		result, err := fishController.ForState(state).GetSurveyCohortsContext(c.Request.Context(), c.Param("surveyID"), c.Param("species_id"), cohorts, maxCohorts)
+		if errors.Is(err, controller.ErrInvalidCohorts) {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err != nil {
+			respondControllerError(c, err)
+			return
+		}
+		if result == nil {
+			respondError(c, http.StatusNotFound, "No length data found for the survey and species")
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	})
+
+	// Lakes where an invasive species was found for the first time.
+	public.GET("/alerts/invasive", func(c *gin.Context) {
+		state, ok := stateQuery(c, fishController)
+		if !ok {
+			return
+		}
+		filters := controller.AlertFilters{
+			Since:    c.Query("since"),
+			Counties: c.QueryArray("counties"),
+			Species:  c.QueryArray("species"),
+		}
+		if dow := c.Query("dow"); dow != "" {
+			var err error
+			if filters.DOW, err = strconv.Atoi(dow); err != nil {
+				respondError(c, http.StatusBadRequest, "Invalid DOW number")
+				return
+			}
+		}
+		filters.IncludeBaseline, _ = strconv.ParseBool(c.DefaultQuery("include_baseline", "false"))
+		filters.Limit, _ = strconv.Atoi(c.Query("limit"))
+
+		alerts, err := fishController.ForState(state).InvasiveAlertsContext(c.Request.Context(), filters)
+		if errors.Is(err, controller.ErrInvalidAlerts) {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err != nil {
+			respondControllerError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, alerts)
+	})
+
+	// Which species are found together, with their Jaccard similarity.
+	public.GET("/community/cooccurrence", func(c *gin.Context) {
+		filters, ok := communityQuery(c)
+		if !ok {
+			return
+		}
+		state, ok := stateQuery(c, fishController)
+		if !ok {
+			return
+		}
+		top, _ := strconv.Atoi(c.Query("top"))
+
+		matrix, err := fishController.ForState(state).CooccurrenceContext(c.Request.Context(), filters, c.Query("by"), c.QueryArray("species"), top)
+		if errors.Is(err, controller.ErrInvalidCommunity) {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err != nil {
+			respondControllerError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, matrix)
+	})
+
+	// Species richness and diversity indices per survey.
+	public.GET("/community/diversity", func(c *gin.Context) {
+		filters, ok := communityQuery(c)
+		if !ok {
+			return
+		}
+		state, ok := stateQuery(c, fishController)
+		if !ok {
+			return
+		}
+		limit, _ := strconv.Atoi(c.Query("limit"))
+		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+
+		diversity, err := fishController.ForState(state).DiversityContext(c.Request.Context(), filters, c.Query("sort_by"), c.Query("order"), limit, page)
+		if errors.Is(err, controller.ErrInvalidCommunity) {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err != nil {
+			respondControllerError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, diversity)
+	})
+
+	// Compare species metrics across lakes, or across survey dates of one lake.
+	public.GET("/compare", func(c *gin.Context) {
+		var dows []int
+		for _, value := range c.QueryArray("dow") {
+			dow, err := strconv.Atoi(value)
+			if err != nil {
+				respondError(c, http.StatusBadRequest, "Invalid DOW number: "+value)
+				return
+			}
+			dows = append(dows, dow)
+		}
+		state, ok := stateQuery(c, fishController)
+		if !ok {
+			return
+		}
+		opts, ok := histogramQuery(c)
+		if !ok {
+			return
+		}
+
+		comparison, err := fishController.ForState(state).CompareContext(c.Request.Context(), dows, c.QueryArray("date"), c.QueryArray("species"), opts)
+		switch {
+		case errors.Is(err, controller.ErrInvalidCompare):
+			respondError(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, controller.ErrCompareNotFound):
+			respondError(c, http.StatusNotFound, err.Error())
+		case err != nil:
+			respondControllerError(c, err)
+		default:
+			c.JSON(http.StatusOK, comparison)
+		}
+	})
+
+	// Rank the lakes for a species by the configured score, with its breakdown.
+	public.GET("/rankings/lakes", func(c *gin.Context) {
+		speciesName := c.Query("species")
+		if speciesName == "" {
+			respondError(c, http.StatusBadRequest, "Missing request query parameter: species")
+			return
+		}
+		state, ok := stateQuery(c, fishController)
+		if !ok {
+			return
+		}
+		limit, _ := strconv.Atoi(c.Query("limit"))
+
+		rankings, err := fishController.ForState(state).RankLakesContext(c.Request.Context(), speciesName, c.Query("county"), state, c.Query("metric"), limit)
+		if errors.Is(err, controller.ErrInvalidRanking) {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err != nil {
+			respondControllerError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, rankings)
+	})
+
+	public.GET("/counties", func(c *gin.Context) {
+		state, ok := stateQuery(c, fishController)
+		if !ok {
+			return
+		}
+		counties := countyController.GetCounties()
+		if state != "" {
+			inState := make([]model.County, 0, len(counties))
+			for _, county := range counties {
+				if model.CountyState(county) == state {
+					inState = append(inState, county)
+				}
+			}
+			counties = inState
+		}
 		c.JSON(http.StatusOK, gin.H{
-			"data": countyController.GetCounties(),
+			"data": counties,
+		})
+	})
+
+	// Configured states with their agency and data counts.
+	public.GET("/states", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"data": fishController.StateSummaries(countyController.GetCounties()),
 		})
 	})
 
 	 // Route to get all species.
-    router.GET("/species", func(c *gin.Context) {
-        speciesList := fishController.GetAllSpecies()
+    public.GET("/species", func(c *gin.Context) {
+        state, ok := stateQuery(c, fishController)
+        if !ok {
+            return
+        }
+        speciesList := fishController.ForState(state).GetAllSpecies()
         c.JSON(http.StatusOK, gin.H{
             "data": speciesList,
         })
     })
 
     // New endpoint to retrieve stats for a specific species by its ID.
-    router.GET("/species/id/:species_id", func(c *gin.Context) {
+    public.GET("/species/id/:species_id", func(c *gin.Context) {
     speciesID := c.Param("species_id")
-    stats := fishController.GetSpeciesStatsByID(speciesID)
+    state, ok := stateQuery(c, fishController)
+    if !ok {
+        return
+    }
+    opts, ok := histogramQuery(c)
+    if !ok {
+        return
+    }
+    stats, err := fishController.ForState(state).GetSpeciesStatsByIDWithOptions(c.Request.Context(), speciesID, opts)
+    if err != nil {
+        respondControllerError(c, err)
+        return
+    }
     if stats == nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Species not found or no data available"})
+        respondError(c, http.StatusNotFound, "Species not found or no data available")
         return
     }
     c.JSON(http.StatusOK, stats)
     })
 
+	// Lake prevalence of a species per county over time, with first detections.
+	public.GET("/species/id/:species_id/distribution", func(c *gin.Context) {
+		state, ok := stateQuery(c, fishController)
+		if !ok {
+			return
+		}
+		distribution, err := fishController.ForState(state).GetSpeciesDistributionContext(c.Request.Context(), c.Param("species_id"), c.Query("by"))
+		if errors.Is(err, controller.ErrInvalidDistribution) {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err != nil {
+			respondControllerError(c, err)
+			return
+		}
+		if distribution == nil {
+			respondError(c, http.StatusNotFound, "Species not found")
+			return
+		}
+		c.JSON(http.StatusOK, distribution)
+	})
+
     // New endpoint: GET /counties/id/:id
-	router.GET("/counties/id/:id", func(c *gin.Context) {
+	public.GET("/counties/id/:id", func(c *gin.Context) {
 		id := c.Param("id")
+		state, ok := stateQuery(c, fishController)
+		if !ok {
+			return
+		}
 		county := countyController.GetCountyByID(id)
-		if county == nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "County not found"})
+		if county == nil || (state != "" && model.CountyState(*county) != state) {
+			respondError(c, http.StatusNotFound, "County not found")
+			return
+		}
+		stats, err := countyController.GetCountyStatsContext(c.Request.Context(), county)
+		if err != nil {
+			respondControllerError(c, err)
 			return
 		}
-		stats := countyController.GetCountyStats(county)
 		c.JSON(http.StatusOK, stats)
 	})
 }
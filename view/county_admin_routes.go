@@ -0,0 +1,18 @@
+package view
+
+import (
+	"fishreports/controller"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupCountyAdminRoutes registers the county reconciliation report under the
+// admin group.
+func SetupCountyAdminRoutes(admin *gin.RouterGroup, countyController *controller.CountyController) {
+	// Survey county names that match no county, non-exact matches, and
+	// counties without lakes.
+	admin.GET("/counties/reconciliation", func(c *gin.Context) {
+		c.JSON(http.StatusOK, countyController.Reconciliation())
+	})
+}
@@ -0,0 +1,102 @@
+package view
+
+import (
+	"context"
+	"errors"
+	"fishreports/model"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// requestIDContextKey is the gin context key holding the request ID.
+const requestIDContextKey = "request_id"
+
+// errorCodes maps HTTP statuses to the stable codes used in error bodies.
+var errorCodes = map[int]string{
+	http.StatusBadRequest:            "bad_request",
+	http.StatusUnauthorized:          "unauthorized",
+	http.StatusForbidden:             "forbidden",
+	http.StatusNotFound:              "not_found",
+	http.StatusConflict:              "conflict",
+	http.StatusRequestEntityTooLarge: "body_too_large",
+	http.StatusTooManyRequests:       "rate_limited",
+	http.StatusInternalServerError:   "internal_error",
+	http.StatusServiceUnavailable:    "unavailable",
+	http.StatusGatewayTimeout:        "timeout",
+}
+
+// respondError aborts the request with the consistent JSON error body:
+// {"error": message, "code": code, "request_id": id}.
+func respondError(c *gin.Context, status int, message string) {
+	code, ok := errorCodes[status]
+	if !ok {
+		code = strings.ToLower(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+	}
+	c.AbortWithStatusJSON(status, gin.H{
+		"error":      message,
+		"code":       code,
+		"request_id": c.GetString(requestIDContextKey),
+	})
+}
+
+// respondControllerError maps an error returned by a controller to a response.
+// Deadline errors become 504s and client disconnects are dropped silently.
+func respondControllerError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		respondError(c, http.StatusGatewayTimeout, "Request took too long to process")
+	case errors.Is(err, context.Canceled):
+		c.Abort()
+	default:
+		log.Printf("❌ [%s] %s %s: %v", c.GetString(requestIDContextKey), c.Request.Method, c.Request.URL.Path, err)
+		respondError(c, http.StatusInternalServerError, "Internal server error")
+	}
+}
+
+// RequestID tags each request with an ID, reusing a well-formed incoming
+// X-Request-ID header, and echoes it in the response.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader("X-Request-ID")
+		if id == "" || len(id) > 128 {
+			id = uuid.New().String()
+		}
+		c.Set(requestIDContextKey, id)
+		c.Header("X-Request-ID", id)
+		c.Next()
+	}
+}
+
+// Recovery turns panics into a JSON 500 and logs the stack trace with the
+// request context so the failure can be traced from the client's request ID.
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+			keyName := ""
+			if key, ok := c.Value(apiKeyContextKey).(*model.APIKey); ok {
+				keyName = key.Name
+			}
+			log.Printf("❌ panic recovered [request_id=%s method=%s path=%s query=%q client=%s key=%s]: %v\n%s",
+				c.GetString(requestIDContextKey), c.Request.Method, c.Request.URL.Path, c.Request.URL.RawQuery,
+				c.ClientIP(), keyName, recovered, debug.Stack())
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			respondError(c, http.StatusInternalServerError, "Internal server error")
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,178 @@
+package view
+
+import (
+	"encoding/json"
+	"fishreports/controller"
+	"fishreports/model"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sseKeepAlive is how often an idle event stream sends a comment so proxies
+// keep the connection open.
+const sseKeepAlive = 15 * time.Second
+
+// surveyEventFilter holds the optional filters of GET /events/surveys.
+type surveyEventFilter struct {
+	counties map[string]bool
+	dows     map[int]bool
+	species  map[string]bool
+	state    string
+}
+
+func newSurveyEventFilter(c *gin.Context) surveyEventFilter {
+	filter := surveyEventFilter{
+		counties: make(map[string]bool),
+		dows:     make(map[int]bool),
+		species:  make(map[string]bool),
+	}
+	for _, id := range c.QueryArray("counties") {
+		filter.counties[strings.ToLower(id)] = true
+	}
+	for _, dow := range c.QueryArray("dow") {
+		if n, err := strconv.Atoi(dow); err == nil {
+			filter.dows[n] = true
+		}
+	}
+	for _, id := range c.QueryArray("species") {
+		filter.species[strings.ToLower(id)] = true
+	}
+	if state := c.Query("state"); state != "" {
+		filter.state = model.NormalizeState(state)
+	}
+	return filter
+}
+
+// matches reports whether an event passes every filter that was given.
+func (f surveyEventFilter) matches(event model.SurveyEvent) bool {
+	if len(f.counties) > 0 {
+		matched := false
+		for _, id := range event.CountyIDs {
+			if f.counties[strings.ToLower(id)] {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if f.state != "" && model.NormalizeState(event.State) != f.state {
+		return false
+	}
+	if len(f.dows) > 0 && !f.dows[event.DOWNumber] {
+		return false
+	}
+	if len(f.species) > 0 {
+		for _, id := range event.SpeciesIDs {
+			if f.species[strings.ToLower(id)] {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// writeSSE writes one event in text/event-stream format.
+func writeSSE(w io.Writer, event model.SurveyEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
+
+// SetupEventRoutes registers GET /events/surveys, a Server-Sent Events stream
+// of surveys found to be new by a data load. Filters: counties (county IDs),
+// dow and species (species IDs), each repeatable, and state. Clients resume with the
+// Last-Event-ID header (or last_event_id query parameter); events still in
+// the bounded log are replayed, and a "reset" event signals that some were
+// already evicted.
+func SetupEventRoutes(router *gin.Engine, events *controller.SurveyEventLog, keyController *controller.APIKeyController) {
+	group := router.Group("/events", RequireScope(keyController, model.ScopePublicRead))
+
+	group.GET("/surveys", func(c *gin.Context) {
+		filter := newSurveyEventFilter(c)
+
+		lastEventID := c.GetHeader("Last-Event-ID")
+		if lastEventID == "" {
+			lastEventID = c.Query("last_event_id")
+		}
+		var lastID int64
+		if lastEventID != "" {
+			parsed, err := strconv.ParseInt(lastEventID, 10, 64)
+			if err != nil {
+				respondError(c, http.StatusBadRequest, "Invalid Last-Event-ID")
+				return
+			}
+			lastID = parsed
+		}
+
+		// Subscribe before replaying so nothing published in between is lost.
+		live, cancel := events.Subscribe(256)
+		defer cancel()
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+
+		w := c.Writer
+		fmt.Fprint(w, "retry: 5000\n\n")
+
+		if lastID > 0 {
+			replay, complete := events.Since(lastID)
+			if !complete {
+				fmt.Fprint(w, "event: reset\ndata: {\"reason\":\"events evicted from the log\"}\n\n")
+			}
+			for _, event := range replay {
+				if event.ID <= lastID {
+					continue
+				}
+				lastID = event.ID
+				if filter.matches(event) {
+					if err := writeSSE(w, event); err != nil {
+						return
+					}
+				}
+			}
+		}
+		w.Flush()
+
+		keepAlive := time.NewTicker(sseKeepAlive)
+		defer keepAlive.Stop()
+		for {
+			select {
+			case <-c.Request.Context().Done():
+				return
+			case <-keepAlive.C:
+				if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+					return
+				}
+				w.Flush()
+			case event, ok := <-live:
+				if !ok {
+					// Fell behind; the client reconnects and resumes from the log.
+					return
+				}
+				if event.ID <= lastID || !filter.matches(event) {
+					continue
+				}
+				lastID = event.ID
+				if err := writeSSE(w, event); err != nil {
+					return
+				}
+				w.Flush()
+			}
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package view
+
+import (
+	"errors"
+	"fishreports/controller"
+	"fishreports/graphqlapi"
+	"fishreports/model"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupGraphQLRoutes registers GET and POST /graphql. Both require public
+// read access; the estimated query cost is returned in the X-Query-Cost header.
+func SetupGraphQLRoutes(router *gin.Engine, executor *graphqlapi.Executor, keyController *controller.APIKeyController) {
+	handler := func(c *gin.Context) {
+		var req graphqlapi.Request
+		if c.Request.Method == http.MethodGet {
+			req.Query = c.Query("query")
+			req.OperationName = c.Query("operationName")
+		} else if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid GraphQL request body")
+			return
+		}
+		if req.Query == "" {
+			respondError(c, http.StatusBadRequest, "Missing GraphQL query")
+			return
+		}
+
+		result, cost, err := executor.Execute(c.Request.Context(), req)
+		c.Header("X-Query-Cost", strconv.Itoa(cost.Cost))
+		if err != nil {
+			var costErr *graphqlapi.CostError
+			if errors.As(err, &costErr) {
+				respondError(c, http.StatusBadRequest, costErr.Error())
+				return
+			}
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+
+	group := router.Group("/graphql", RequireScope(keyController, model.ScopePublicRead))
+	group.GET("", handler)
+	group.POST("", handler)
+}
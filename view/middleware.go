@@ -0,0 +1,214 @@
+package view
+
+import (
+	"context"
+	"fishreports/config"
+	"fishreports/controller"
+	"fishreports/model"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeyContextKey is the gin context key holding the authenticated *model.APIKey.
+const apiKeyContextKey = "api_key"
+
+// APIKeyAuth authenticates the request's API key and applies its rate limit.
+// The key is read from the X-API-Key header or an "Authorization: Bearer"
+// header; it is never taken from the URL, which ends up in access logs. When
+// keyController is nil authentication is disabled and every request is
+// allowed.
+func APIKeyAuth(keyController *controller.APIKeyController) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if keyController == nil {
+			c.Next()
+			return
+		}
+
+		secret := c.GetHeader("X-API-Key")
+		if secret == "" {
+			secret = strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		}
+		if secret == "" {
+			respondError(c, http.StatusUnauthorized, "Missing API key")
+			return
+		}
+
+		key, ok := keyController.Authenticate(secret)
+		if !ok {
+			respondError(c, http.StatusUnauthorized, "Invalid API key")
+			return
+		}
+
+		status := keyController.Allow(key)
+		resetSeconds := int(math.Ceil(status.Reset.Seconds()))
+		c.Header("RateLimit-Limit", strconv.Itoa(status.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(status.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(resetSeconds))
+		c.Header("RateLimit-Policy", strconv.Itoa(status.Limit)+";w="+strconv.Itoa(int(status.Window.Seconds())))
+		if !status.Allowed {
+			c.Header("Retry-After", strconv.Itoa(max(int(math.Ceil(status.RetryAfter.Seconds())), 1)))
+			respondError(c, http.StatusTooManyRequests, "Rate limit exceeded")
+			return
+		}
+
+		c.Set(apiKeyContextKey, key)
+		c.Next()
+	}
+}
+
+// RequireScope rejects requests whose API key lacks the given scope. It must
+// run after APIKeyAuth; when authentication is disabled it allows everything.
+func RequireScope(keyController *controller.APIKeyController, scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if keyController == nil {
+			c.Next()
+			return
+		}
+		if !hasScope(c, scope) {
+			respondError(c, http.StatusForbidden, "API key lacks the '"+scope+"' scope")
+			return
+		}
+		c.Next()
+	}
+}
+
+// hasScope reports whether the request's API key grants scope. Requests that
+// went through disabled authentication have no key and are granted every scope.
+func hasScope(c *gin.Context, scope string) bool {
+	value, exists := c.Get(apiKeyContextKey)
+	if !exists {
+		return true
+	}
+	key, ok := value.(*model.APIKey)
+	return ok && key.HasScope(scope)
+}
+
+// CORS applies the configured cross-origin policy and answers preflight
+// requests. It must be installed before APIKeyAuth so preflights, which carry
+// no credentials, are not rejected.
+func CORS(cfg config.CORSConfig) gin.HandlerFunc {
+	allowAll := false
+	origins := make(map[string]bool)
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			allowAll = true
+		}
+		origins[strings.TrimSuffix(origin, "/")] = true
+	}
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	exposed := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(cfg.MaxAgeSeconds)
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" || len(origins) == 0 {
+			c.Next()
+			return
+		}
+		c.Header("Vary", "Origin")
+		if !allowAll && !origins[origin] {
+			if c.Request.Method == http.MethodOptions {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if allowAll && !cfg.AllowCredentials {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		if cfg.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+		if exposed != "" {
+			c.Header("Access-Control-Expose-Headers", exposed)
+		}
+
+		// Answer preflight requests directly.
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+			c.Header("Access-Control-Allow-Methods", methods)
+			c.Header("Access-Control-Allow-Headers", headers)
+			if cfg.MaxAgeSeconds > 0 {
+				c.Header("Access-Control-Max-Age", maxAge)
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
+
+// SecurityHeaders sets the standard security headers on every response.
+// Strict-Transport-Security is only sent on TLS connections.
+func SecurityHeaders(cfg config.SecurityConfig) gin.HandlerFunc {
+	hsts := "max-age=" + strconv.Itoa(cfg.HSTSMaxAgeSeconds) + "; includeSubDomains"
+	return func(c *gin.Context) {
+		if !cfg.Headers {
+			c.Next()
+			return
+		}
+		c.Header("X-Content-Type-Options", "nosniff")
+		c.Header("X-Frame-Options", "DENY")
+		c.Header("Referrer-Policy", "no-referrer")
+		c.Header("Cross-Origin-Opener-Policy", "same-origin")
+		if cfg.ContentSecurityPolicy != "" {
+			c.Header("Content-Security-Policy", cfg.ContentSecurityPolicy)
+		}
+		if c.Request.TLS != nil && cfg.HSTSMaxAgeSeconds > 0 {
+			c.Header("Strict-Transport-Security", hsts)
+		}
+		c.Next()
+	}
+}
+
+// Timeout attaches a context deadline to each request. Routes listed in
+// cfg.Routes (keyed by gin route pattern, e.g. "/surveys") use their own
+// timeout; everything else uses cfg.DefaultSeconds. Controllers check the
+// context in their scan loops and give up once it expires.
+func Timeout(cfg config.TimeoutConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		seconds := cfg.DefaultSeconds
+		if routeSeconds, exists := cfg.Routes[c.FullPath()]; exists {
+			seconds = routeSeconds
+		}
+		if seconds <= 0 {
+			c.Next()
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), time.Duration(seconds)*time.Second)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// BodyLimit rejects request bodies larger than maxBytes, or the route's own
+// limit from routes (keyed by gin route pattern). Handlers reading past the
+// limit get an error from the body reader.
+func BodyLimit(defaultMaxBytes int64, routes map[string]int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		maxBytes := defaultMaxBytes
+		if routeMaxBytes, exists := routes[c.FullPath()]; exists {
+			maxBytes = routeMaxBytes
+		}
+		if maxBytes <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+		if c.Request.ContentLength > maxBytes {
+			respondError(c, http.StatusRequestEntityTooLarge, "Request body too large")
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
@@ -0,0 +1,90 @@
+package view
+
+import (
+	"errors"
+	"fishreports/controller"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupSpeciesAdminRoutes registers species catalog management and the
+// unknown species code report under the admin group.
+func SetupSpeciesAdminRoutes(admin *gin.RouterGroup, catalog *controller.SpeciesCatalog) {
+	// Species codes in the survey data that are missing from the catalog.
+	admin.GET("/species/unknown", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"data": catalog.UnknownSpecies()})
+	})
+
+	// Every catalog entry, including species without survey data.
+	admin.GET("/species", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"data": catalog.ListSpecies()})
+	})
+
+	admin.GET("/species/:code", func(c *gin.Context) {
+		species := catalog.GetSpecies(c.Param("code"))
+		if species == nil {
+			respondError(c, http.StatusNotFound, "Species not found")
+			return
+		}
+		c.JSON(http.StatusOK, species)
+	})
+
+	admin.POST("/species", func(c *gin.Context) {
+		var input controller.SpeciesInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid species JSON: "+err.Error())
+			return
+		}
+		species, err := catalog.CreateSpecies(input)
+		if err != nil {
+			respondSpeciesError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, species)
+	})
+
+	// Edit a species; fields left out keep their values.
+	admin.PUT("/species/:code", func(c *gin.Context) {
+		var input controller.SpeciesInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid species JSON: "+err.Error())
+			return
+		}
+		species, err := catalog.UpdateSpecies(c.Param("code"), input)
+		if err != nil {
+			respondSpeciesError(c, err)
+			return
+		}
+		if species == nil {
+			respondError(c, http.StatusNotFound, "Species not found")
+			return
+		}
+		c.JSON(http.StatusOK, species)
+	})
+
+	admin.DELETE("/species/:code", func(c *gin.Context) {
+		deleted, err := catalog.DeleteSpecies(c.Param("code"))
+		if err != nil {
+			respondSpeciesError(c, err)
+			return
+		}
+		if !deleted {
+			respondError(c, http.StatusNotFound, "Species not found")
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// respondSpeciesError maps species catalog errors to responses.
+func respondSpeciesError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, controller.ErrInvalidSpecies):
+		respondError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, controller.ErrSpeciesExists):
+		respondError(c, http.StatusConflict, err.Error())
+	default:
+		respondControllerError(c, err)
+	}
+}
@@ -0,0 +1,90 @@
+package view
+
+import (
+	"errors"
+	"fishreports/controller"
+	"fishreports/model"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupWebhookRoutes registers webhook subscription management under the
+// admin group.
+func SetupWebhookRoutes(admin *gin.RouterGroup, webhookController *controller.WebhookController) {
+	// List subscriptions with their secrets masked.
+	admin.GET("/webhooks", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"data": webhookController.ListSubscriptions()})
+	})
+
+	// Register a subscription. The response is the only time the secret is shown.
+	admin.POST("/webhooks", func(c *gin.Context) {
+		var sub model.WebhookSubscription
+		if err := c.ShouldBindJSON(&sub); err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid webhook JSON: "+err.Error())
+			return
+		}
+		created, err := webhookController.CreateSubscription(sub)
+		if errors.Is(err, controller.ErrInvalidWebhook) {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err != nil {
+			respondControllerError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, created)
+	})
+
+	admin.GET("/webhooks/:id", func(c *gin.Context) {
+		sub := webhookController.GetSubscription(c.Param("id"))
+		if sub == nil {
+			respondError(c, http.StatusNotFound, "Webhook not found")
+			return
+		}
+		c.JSON(http.StatusOK, sub)
+	})
+
+	admin.DELETE("/webhooks/:id", func(c *gin.Context) {
+		deleted, err := webhookController.DeleteSubscription(c.Param("id"))
+		if err != nil {
+			respondControllerError(c, err)
+			return
+		}
+		if !deleted {
+			respondError(c, http.StatusNotFound, "Webhook not found")
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	// Delivery log for one subscription, newest first.
+	admin.GET("/webhooks/:id/deliveries", func(c *gin.Context) {
+		id := c.Param("id")
+		if webhookController.GetSubscription(id) == nil {
+			respondError(c, http.StatusNotFound, "Webhook not found")
+			return
+		}
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+		if err != nil || limit <= 0 {
+			respondError(c, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": webhookController.Deliveries(id, limit)})
+	})
+
+	// Send a signed webhook.ping event once and return the attempt.
+	admin.POST("/webhooks/:id/ping", func(c *gin.Context) {
+		delivery, err := webhookController.Ping(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			respondControllerError(c, err)
+			return
+		}
+		if delivery == nil {
+			respondError(c, http.StatusNotFound, "Webhook not found")
+			return
+		}
+		c.JSON(http.StatusOK, delivery)
+	})
+}